@@ -0,0 +1,358 @@
+// Package authorizer implements API Gateway Lambda authorizers: TOKEN and REQUEST authorizers of REST APIs, and the
+// payload format 1.0 and 2.0 authorizers of HTTP APIs. A single Handler decides who the caller is, and the package
+// builds the IAM policy or simple response API Gateway expects, propagating the claims of the caller to the
+// integration.
+//
+//	auth := authorizer.New(logger, tokenHandler, authorizer.WithCache(5*time.Minute))
+//
+//	lambda.StartWithResponse[events.APIGatewayCustomAuthorizerRequest, events.APIGatewayCustomAuthorizerResponse](
+//		auth.Token(), middleware.CommonTokenAuthorizer(logger))
+package authorizer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ellogroup/ello-golang-aws/v2/lambda"
+)
+
+var (
+	// ErrUnauthorized should be returned by a Handler for a caller without valid credentials. API Gateway responds 401
+	// Unauthorized.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden should be returned by a Handler for a caller that is not allowed to invoke the API. API Gateway
+	// responds 403 Forbidden.
+	ErrForbidden = errors.New("forbidden")
+
+	// errUnauthorizedResponse is the error API Gateway expects from an authorizer to respond 401 Unauthorized.
+	errUnauthorizedResponse = errors.New("Unauthorized") //nolint:revive,staticcheck // the exact message API Gateway expects
+)
+
+// Request is an authorizer event of any type.
+type Request struct {
+	// Type is the type of the authorizer, "TOKEN" or "REQUEST".
+	Type string
+	// Token is the authorization token of a TOKEN authorizer, or the Authorization header of a REQUEST authorizer of a
+	// REST API, whose events do not include the identity sources. For HTTP APIs it is the identity sources of the
+	// request joined with commas or, when there are none, the authorization token of payload format 1.0 events and the
+	// Authorization header of payload format 2.0 events.
+	Token string
+	// MethodARN is the ARN of the method being invoked, or of the route for payload format 2.0 events.
+	MethodARN string
+	// HTTPMethod is the HTTP method of the request. It is empty for TOKEN authorizers.
+	HTTPMethod string
+	// Path is the path of the request. It is empty for TOKEN authorizers.
+	Path string
+	// Headers are the headers of the request. They are empty for TOKEN authorizers.
+	Headers map[string]string
+	// QueryStringParameters are the query string parameters of the request. They are empty for TOKEN authorizers.
+	QueryStringParameters map[string]string
+	// PathParameters are the path parameters of the request. They are empty for TOKEN authorizers.
+	PathParameters map[string]string
+	// StageVariables are the stage variables of the API. They are empty for TOKEN authorizers.
+	StageVariables map[string]string
+	// Event is the original event.
+	Event any
+}
+
+// Result is the decision of a Handler allowing a caller.
+type Result struct {
+	// PrincipalID identifies the caller.
+	PrincipalID string
+	// Claims of the caller are propagated to the integration in the authorizer context, which for simple responses also
+	// includes the PrincipalID as "principalId". API Gateway only accepts strings, numbers and booleans, so other
+	// values are JSON encoded.
+	Claims map[string]any
+	// Policy is the policy of the caller. When it is nil, the caller is allowed to invoke the method of the request
+	// only. It is ignored by simple responses.
+	Policy *Policy
+	// UsageIdentifierKey is the API key of the caller for usage plans of REST APIs.
+	UsageIdentifierKey string
+}
+
+// Handler interface should be implemented for authorizers.
+type Handler interface {
+	// Authorize decides whether the caller of request is allowed, returning ErrUnauthorized or ErrForbidden if not.
+	Authorize(ctx context.Context, request Request) (Result, error)
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as a Handler.
+type HandlerFunc func(ctx context.Context, request Request) (Result, error)
+
+// Authorize calls f(ctx, request).
+func (f HandlerFunc) Authorize(ctx context.Context, request Request) (Result, error) {
+	return f(ctx, request)
+}
+
+type options struct {
+	cacheTTL time.Duration
+}
+
+// Option configures New.
+type Option func(*options)
+
+// WithCache caches the decisions of the Handler in memory for ttl, keyed by a hash of the token and the method of each
+// request. An allowed Result with a Policy is reused for every method of the token instead, so its Policy should depend
+// on the token only. Errors other than ErrUnauthorized and ErrForbidden are not cached, nor are requests without a
+// token.
+func WithCache(ttl time.Duration) Option {
+	return func(o *options) {
+		o.cacheTTL = ttl
+	}
+}
+
+// Authorizer adapts a Handler to each type of authorizer event.
+type Authorizer struct {
+	logger  *slog.Logger
+	handler Handler
+	cache   *cache
+}
+
+// New returns an Authorizer deciding with handler, logging denied requests to logger.
+func New(logger *slog.Logger, handler Handler, opts ...Option) *Authorizer {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	a := &Authorizer{
+		logger:  logger,
+		handler: handler,
+	}
+	if o.cacheTTL > 0 {
+		a.cache = newCache(o.cacheTTL)
+	}
+	return a
+}
+
+// Token returns a handler of TOKEN authorizer events of REST APIs.
+func (a *Authorizer) Token() lambda.HandlerWithResponse[events.APIGatewayCustomAuthorizerRequest, events.APIGatewayCustomAuthorizerResponse] {
+	return handlerFunc[events.APIGatewayCustomAuthorizerRequest, events.APIGatewayCustomAuthorizerResponse](
+		func(ctx context.Context, e events.APIGatewayCustomAuthorizerRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+			return a.policyResponse(ctx, Request{
+				Type:      e.Type,
+				Token:     e.AuthorizationToken,
+				MethodARN: e.MethodArn,
+				Event:     e,
+			})
+		},
+	)
+}
+
+// Request returns a handler of REQUEST authorizer events of REST APIs.
+func (a *Authorizer) Request() lambda.HandlerWithResponse[events.APIGatewayCustomAuthorizerRequestTypeRequest, events.APIGatewayCustomAuthorizerResponse] {
+	return handlerFunc[events.APIGatewayCustomAuthorizerRequestTypeRequest, events.APIGatewayCustomAuthorizerResponse](
+		func(ctx context.Context, e events.APIGatewayCustomAuthorizerRequestTypeRequest) (events.APIGatewayCustomAuthorizerResponse, error) {
+			return a.policyResponse(ctx, Request{
+				Type:                  e.Type,
+				Token:                 header(e.Headers, "Authorization"),
+				MethodARN:             e.MethodArn,
+				HTTPMethod:            e.HTTPMethod,
+				Path:                  e.Path,
+				Headers:               e.Headers,
+				QueryStringParameters: e.QueryStringParameters,
+				PathParameters:        e.PathParameters,
+				StageVariables:        e.StageVariables,
+				Event:                 e,
+			})
+		},
+	)
+}
+
+// HTTPV1 returns a handler of payload format 1.0 authorizer events of HTTP APIs.
+func (a *Authorizer) HTTPV1() lambda.HandlerWithResponse[events.APIGatewayV2CustomAuthorizerV1Request, events.APIGatewayCustomAuthorizerResponse] {
+	return handlerFunc[events.APIGatewayV2CustomAuthorizerV1Request, events.APIGatewayCustomAuthorizerResponse](
+		func(ctx context.Context, e events.APIGatewayV2CustomAuthorizerV1Request) (events.APIGatewayCustomAuthorizerResponse, error) {
+			token := e.IdentitySource
+			if token == "" {
+				token = e.AuthorizationToken
+			}
+			return a.policyResponse(ctx, Request{
+				Type:                  e.Type,
+				Token:                 token,
+				MethodARN:             e.MethodArn,
+				HTTPMethod:            e.HTTPMethod,
+				Path:                  e.Path,
+				Headers:               e.Headers,
+				QueryStringParameters: e.QueryStringParameters,
+				PathParameters:        e.PathParameters,
+				StageVariables:        e.StageVariables,
+				Event:                 e,
+			})
+		},
+	)
+}
+
+// HTTP returns a handler of payload format 2.0 authorizer events of HTTP APIs with simple responses. A caller denied
+// with ErrUnauthorized or ErrForbidden is not authorized, so API Gateway responds 403 Forbidden.
+func (a *Authorizer) HTTP() lambda.HandlerWithResponse[events.APIGatewayV2CustomAuthorizerV2Request, events.APIGatewayV2CustomAuthorizerSimpleResponse] {
+	return handlerFunc[events.APIGatewayV2CustomAuthorizerV2Request, events.APIGatewayV2CustomAuthorizerSimpleResponse](
+		func(ctx context.Context, e events.APIGatewayV2CustomAuthorizerV2Request) (events.APIGatewayV2CustomAuthorizerSimpleResponse, error) {
+			result, err := a.authorize(ctx, httpRequest(e), false)
+			switch {
+			case errors.Is(err, ErrUnauthorized), errors.Is(err, ErrForbidden):
+				return events.APIGatewayV2CustomAuthorizerSimpleResponse{IsAuthorized: false}, nil
+			case err != nil:
+				return events.APIGatewayV2CustomAuthorizerSimpleResponse{}, err
+			}
+			authCtx := authorizerContext(result)
+			if result.PrincipalID != "" {
+				if authCtx == nil {
+					authCtx = map[string]any{}
+				}
+				authCtx["principalId"] = result.PrincipalID
+			}
+			return events.APIGatewayV2CustomAuthorizerSimpleResponse{
+				IsAuthorized: true,
+				Context:      authCtx,
+			}, nil
+		},
+	)
+}
+
+// HTTPPolicy returns a handler of payload format 2.0 authorizer events of HTTP APIs with IAM policy responses.
+func (a *Authorizer) HTTPPolicy() lambda.HandlerWithResponse[events.APIGatewayV2CustomAuthorizerV2Request, events.APIGatewayV2CustomAuthorizerIAMPolicyResponse] {
+	return handlerFunc[events.APIGatewayV2CustomAuthorizerV2Request, events.APIGatewayV2CustomAuthorizerIAMPolicyResponse](
+		func(ctx context.Context, e events.APIGatewayV2CustomAuthorizerV2Request) (events.APIGatewayV2CustomAuthorizerIAMPolicyResponse, error) {
+			response, err := a.policyResponse(ctx, httpRequest(e))
+			return events.APIGatewayV2CustomAuthorizerIAMPolicyResponse{
+				PrincipalID:    response.PrincipalID,
+				PolicyDocument: response.PolicyDocument,
+				Context:        response.Context,
+			}, err
+		},
+	)
+}
+
+// policyResponse returns an IAM policy response for request. A caller denied with ErrForbidden is denied the method of
+// the request, and one denied with ErrUnauthorized gets the error API Gateway responds 401 Unauthorized to.
+func (a *Authorizer) policyResponse(ctx context.Context, request Request) (events.APIGatewayCustomAuthorizerResponse, error) {
+	result, err := a.authorize(ctx, request, true)
+	switch {
+	case errors.Is(err, ErrUnauthorized):
+		return events.APIGatewayCustomAuthorizerResponse{}, errUnauthorizedResponse
+	case errors.Is(err, ErrForbidden):
+		return events.APIGatewayCustomAuthorizerResponse{
+			PrincipalID:    result.PrincipalID,
+			PolicyDocument: NewPolicy(request.MethodARN).DenyARN(request.MethodARN).Document(),
+		}, nil
+	case err != nil:
+		return events.APIGatewayCustomAuthorizerResponse{}, err
+	}
+
+	policy := result.Policy
+	if policy == nil {
+		policy = NewPolicy(request.MethodARN).AllowARN(request.MethodARN)
+	}
+	return events.APIGatewayCustomAuthorizerResponse{
+		PrincipalID:        result.PrincipalID,
+		PolicyDocument:     policy.Document(),
+		Context:            authorizerContext(result),
+		UsageIdentifierKey: result.UsageIdentifierKey,
+	}, nil
+}
+
+// authorize returns the decision of the Handler for request, from the cache if enabled. When usesPolicy, an allowed
+// Result with a Policy is cached for the token, as its Policy covers every method; any other decision may depend on the
+// method of the request, so is cached for the token and method.
+func (a *Authorizer) authorize(ctx context.Context, request Request, usesPolicy bool) (Result, error) {
+	cacheable := a.cache != nil && request.Token != ""
+	methodKey := request.Token + "\x00" + request.MethodARN
+	if cacheable {
+		if entry, ok := a.cache.get(request.Token); ok && usesPolicy {
+			return entry.result, entry.err
+		}
+		if entry, ok := a.cache.get(methodKey); ok {
+			return entry.result, entry.err
+		}
+	}
+
+	result, err := a.handler.Authorize(ctx, request)
+	switch {
+	case err == nil, errors.Is(err, ErrUnauthorized), errors.Is(err, ErrForbidden):
+		if cacheable {
+			key := methodKey
+			if err == nil && result.Policy != nil && usesPolicy {
+				key = request.Token
+			}
+			a.cache.set(key, result, err)
+		}
+	default:
+		a.logger.ErrorContext(ctx, "Authorizer failed",
+			slog.String("method_arn", request.MethodARN),
+			slog.String("error", err.Error()),
+		)
+		return result, err
+	}
+	if err != nil {
+		a.logger.InfoContext(ctx, "Request denied",
+			slog.String("method_arn", request.MethodARN),
+			slog.String("principal_id", result.PrincipalID),
+			slog.String("reason", err.Error()),
+		)
+	}
+	return result, err
+}
+
+func httpRequest(e events.APIGatewayV2CustomAuthorizerV2Request) Request {
+	token := strings.Join(e.IdentitySource, ",")
+	if token == "" {
+		token = header(e.Headers, "Authorization")
+	}
+	return Request{
+		Type:                  e.Type,
+		Token:                 token,
+		MethodARN:             e.RouteArn,
+		HTTPMethod:            e.RequestContext.HTTP.Method,
+		Path:                  e.RawPath,
+		Headers:               e.Headers,
+		QueryStringParameters: e.QueryStringParameters,
+		PathParameters:        e.PathParameters,
+		StageVariables:        e.StageVariables,
+		Event:                 e,
+	}
+}
+
+// authorizerContext returns the authorizer context of result, JSON encoding values API Gateway does not accept.
+func authorizerContext(result Result) map[string]any {
+	if len(result.Claims) == 0 {
+		return nil
+	}
+	ctx := make(map[string]any, len(result.Claims)+1)
+	for k, v := range result.Claims {
+		switch v.(type) {
+		case string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			ctx[k] = v
+		default:
+			if b, err := json.Marshal(v); err == nil {
+				ctx[k] = string(b)
+			}
+		}
+	}
+	return ctx
+}
+
+// header returns the value of the header name, matched case-insensitively.
+func header(headers map[string]string, name string) string {
+	if v, ok := headers[name]; ok {
+		return v
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+// handlerFunc [E, R any] adapts a func to a lambda.HandlerWithResponse[E, R].
+type handlerFunc[E, R any] func(ctx context.Context, event E) (R, error)
+
+func (f handlerFunc[E, R]) Handle(ctx context.Context, event E) (R, error) {
+	return f(ctx, event)
+}
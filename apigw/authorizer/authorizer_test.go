@@ -0,0 +1,298 @@
+package authorizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenHandler allows "Bearer valid", forbids "Bearer forbidden" and fails for "Bearer error".
+type tokenHandler struct {
+	calls    int
+	requests []Request
+}
+
+func (h *tokenHandler) Authorize(_ context.Context, request Request) (Result, error) {
+	h.calls++
+	h.requests = append(h.requests, request)
+	switch request.Token {
+	case "Bearer valid":
+		return Result{
+			PrincipalID: "user-123",
+			Claims:      map[string]any{"email": "jane@example.com", "admin": true, "groups": []string{"a", "b"}},
+		}, nil
+	case "Bearer forbidden":
+		return Result{PrincipalID: "user-456"}, fmt.Errorf("%w: suspended", ErrForbidden)
+	case "Bearer error":
+		return Result{}, errors.New("jwks unavailable")
+	}
+	return Result{}, ErrUnauthorized
+}
+
+func allowStatement(resource string) events.APIGatewayCustomAuthorizerPolicy {
+	return events.APIGatewayCustomAuthorizerPolicy{
+		Version:   "2012-10-17",
+		Statement: []events.IAMPolicyStatement{{Action: []string{"execute-api:Invoke"}, Effect: "Allow", Resource: []string{resource}}},
+	}
+}
+
+func denyStatement(resource string) events.APIGatewayCustomAuthorizerPolicy {
+	return events.APIGatewayCustomAuthorizerPolicy{
+		Version:   "2012-10-17",
+		Statement: []events.IAMPolicyStatement{{Action: []string{"execute-api:Invoke"}, Effect: "Deny", Resource: []string{resource}}},
+	}
+}
+
+func TestAuthorizer_Token(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		want    events.APIGatewayCustomAuthorizerResponse
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:  "valid token, method allowed with claims in context",
+			token: "Bearer valid",
+			want: events.APIGatewayCustomAuthorizerResponse{
+				PrincipalID:    "user-123",
+				PolicyDocument: allowStatement(testMethodARN),
+				Context:        map[string]any{"email": "jane@example.com", "admin": true, "groups": `["a","b"]`},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "forbidden token, method denied",
+			token: "Bearer forbidden",
+			want: events.APIGatewayCustomAuthorizerResponse{
+				PrincipalID:    "user-456",
+				PolicyDocument: denyStatement(testMethodARN),
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "invalid token, Unauthorized error",
+			token: "Bearer invalid",
+			want:  events.APIGatewayCustomAuthorizerResponse{},
+			wantErr: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.EqualError(t, err, "Unauthorized")
+			},
+		},
+		{
+			name:  "handler error, error returned",
+			token: "Bearer error",
+			want:  events.APIGatewayCustomAuthorizerResponse{},
+			wantErr: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.EqualError(t, err, "jwks unavailable")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := &tokenHandler{}
+			auth := New(slog.New(slog.DiscardHandler), handler)
+
+			got, err := auth.Token().Handle(context.Background(), events.APIGatewayCustomAuthorizerRequest{
+				Type:               "TOKEN",
+				AuthorizationToken: tt.token,
+				MethodArn:          testMethodARN,
+			})
+
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, "TOKEN", handler.requests[0].Type)
+		})
+	}
+}
+
+func TestAuthorizer_Token_policy(t *testing.T) {
+	handler := HandlerFunc(func(_ context.Context, r Request) (Result, error) {
+		return Result{PrincipalID: "user-123", Policy: NewPolicy(r.MethodARN).Allow(AnyMethod, "/customers/*")}, nil
+	})
+
+	got, err := New(slog.New(slog.DiscardHandler), handler).Token().Handle(context.Background(),
+		events.APIGatewayCustomAuthorizerRequest{AuthorizationToken: "token", MethodArn: testMethodARN})
+
+	require.NoError(t, err)
+	assert.Equal(t, allowStatement("arn:aws:execute-api:eu-west-2:123456789012:abc123/prod/*/customers/*"), got.PolicyDocument)
+}
+
+func TestAuthorizer_Request(t *testing.T) {
+	handler := &tokenHandler{}
+	auth := New(slog.New(slog.DiscardHandler), handler)
+
+	got, err := auth.Request().Handle(context.Background(), events.APIGatewayCustomAuthorizerRequestTypeRequest{
+		Type:       "REQUEST",
+		MethodArn:  testMethodARN,
+		HTTPMethod: "GET",
+		Path:       "/customers/123",
+		Headers:    map[string]string{"authorization": "Bearer valid"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", got.PrincipalID)
+	assert.Equal(t, allowStatement(testMethodARN), got.PolicyDocument)
+	assert.Equal(t, Request{
+		Type:       "REQUEST",
+		Token:      "Bearer valid",
+		MethodARN:  testMethodARN,
+		HTTPMethod: "GET",
+		Path:       "/customers/123",
+		Headers:    map[string]string{"authorization": "Bearer valid"},
+		Event:      handler.requests[0].Event,
+	}, handler.requests[0])
+}
+
+func TestAuthorizer_HTTPV1(t *testing.T) {
+	auth := New(slog.New(slog.DiscardHandler), &tokenHandler{})
+
+	got, err := auth.HTTPV1().Handle(context.Background(), events.APIGatewayV2CustomAuthorizerV1Request{
+		Type:           "REQUEST",
+		MethodArn:      testMethodARN,
+		IdentitySource: "Bearer forbidden",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, denyStatement(testMethodARN), got.PolicyDocument)
+}
+
+func TestAuthorizer_HTTP(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   events.APIGatewayV2CustomAuthorizerV2Request
+		want    events.APIGatewayV2CustomAuthorizerSimpleResponse
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:  "valid identity source, authorized with principal and claims in context",
+			event: events.APIGatewayV2CustomAuthorizerV2Request{IdentitySource: []string{"Bearer valid"}},
+			want: events.APIGatewayV2CustomAuthorizerSimpleResponse{
+				IsAuthorized: true,
+				Context:      map[string]any{"principalId": "user-123", "email": "jane@example.com", "admin": true, "groups": `["a","b"]`},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "no identity source, authorization header used",
+			event:   events.APIGatewayV2CustomAuthorizerV2Request{Headers: map[string]string{"authorization": "Bearer valid"}},
+			want:    events.APIGatewayV2CustomAuthorizerSimpleResponse{IsAuthorized: true, Context: map[string]any{"principalId": "user-123", "email": "jane@example.com", "admin": true, "groups": `["a","b"]`}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "forbidden, not authorized",
+			event:   events.APIGatewayV2CustomAuthorizerV2Request{IdentitySource: []string{"Bearer forbidden"}},
+			want:    events.APIGatewayV2CustomAuthorizerSimpleResponse{IsAuthorized: false},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "unauthorized, not authorized",
+			event:   events.APIGatewayV2CustomAuthorizerV2Request{IdentitySource: []string{"Bearer invalid"}},
+			want:    events.APIGatewayV2CustomAuthorizerSimpleResponse{IsAuthorized: false},
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "handler error, error returned",
+			event: events.APIGatewayV2CustomAuthorizerV2Request{IdentitySource: []string{"Bearer error"}},
+			want:  events.APIGatewayV2CustomAuthorizerSimpleResponse{},
+			wantErr: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.EqualError(t, err, "jwks unavailable")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(slog.New(slog.DiscardHandler), &tokenHandler{}).HTTP().Handle(context.Background(), tt.event)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAuthorizer_HTTPPolicy(t *testing.T) {
+	const routeARN = "arn:aws:execute-api:eu-west-2:123456789012:abc123/$default/GET/customers"
+	auth := New(slog.New(slog.DiscardHandler), &tokenHandler{})
+
+	got, err := auth.HTTPPolicy().Handle(context.Background(), events.APIGatewayV2CustomAuthorizerV2Request{
+		RouteArn:       routeARN,
+		IdentitySource: []string{"Bearer valid"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", got.PrincipalID)
+	assert.Equal(t, allowStatement(routeARN), got.PolicyDocument)
+}
+
+func TestAuthorizer_WithCache(t *testing.T) {
+	handler := &tokenHandler{}
+	auth := New(slog.New(slog.DiscardHandler), handler, WithCache(time.Minute)).Token()
+	const otherMethodARN = "arn:aws:execute-api:eu-west-2:123456789012:abc123/prod/POST/orders"
+
+	for _, arn := range []string{testMethodARN, otherMethodARN} {
+		got, err := auth.Handle(context.Background(), events.APIGatewayCustomAuthorizerRequest{AuthorizationToken: "Bearer valid", MethodArn: arn})
+		require.NoError(t, err)
+		assert.Equal(t, allowStatement(arn), got.PolicyDocument, "default policy for the method of each request")
+	}
+	for range 2 {
+		_, err := auth.Handle(context.Background(), events.APIGatewayCustomAuthorizerRequest{AuthorizationToken: "Bearer invalid", MethodArn: testMethodARN})
+		assert.EqualError(t, err, "Unauthorized")
+	}
+	for range 2 {
+		_, err := auth.Handle(context.Background(), events.APIGatewayCustomAuthorizerRequest{AuthorizationToken: "Bearer error", MethodArn: testMethodARN})
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, 5, handler.calls, "valid and invalid decisions cached per method, errors not cached")
+}
+
+func TestAuthorizer_WithCache_method(t *testing.T) {
+	const otherMethodARN = "arn:aws:execute-api:eu-west-2:123456789012:abc123/prod/POST/orders"
+	handler := HandlerFunc(func(_ context.Context, request Request) (Result, error) {
+		if request.MethodARN != testMethodARN {
+			return Result{PrincipalID: "user-123"}, ErrForbidden
+		}
+		return Result{PrincipalID: "user-123"}, nil
+	})
+	auth := New(slog.New(slog.DiscardHandler), handler, WithCache(time.Minute))
+
+	t.Run("policy response, allowed for one method only", func(t *testing.T) {
+		token := auth.Token()
+		got, err := token.Handle(context.Background(), events.APIGatewayCustomAuthorizerRequest{AuthorizationToken: "Bearer a", MethodArn: testMethodARN})
+		require.NoError(t, err)
+		assert.Equal(t, allowStatement(testMethodARN), got.PolicyDocument)
+
+		got, err = token.Handle(context.Background(), events.APIGatewayCustomAuthorizerRequest{AuthorizationToken: "Bearer a", MethodArn: otherMethodARN})
+		require.NoError(t, err)
+		assert.Equal(t, denyStatement(otherMethodARN), got.PolicyDocument)
+	})
+
+	t.Run("simple response, allowed for one route only", func(t *testing.T) {
+		simple := auth.HTTP()
+		got, err := simple.Handle(context.Background(), events.APIGatewayV2CustomAuthorizerV2Request{IdentitySource: []string{"Bearer b"}, RouteArn: testMethodARN})
+		require.NoError(t, err)
+		assert.True(t, got.IsAuthorized)
+
+		got, err = simple.Handle(context.Background(), events.APIGatewayV2CustomAuthorizerV2Request{IdentitySource: []string{"Bearer b"}, RouteArn: otherMethodARN})
+		require.NoError(t, err)
+		assert.False(t, got.IsAuthorized)
+	})
+
+	t.Run("policy, reused for every method", func(t *testing.T) {
+		calls := 0
+		policy := New(slog.New(slog.DiscardHandler), HandlerFunc(func(context.Context, Request) (Result, error) {
+			calls++
+			return Result{PrincipalID: "user-123", Policy: NewPolicy(testMethodARN).AllowARN(testMethodARN)}, nil
+		}), WithCache(time.Minute)).Token()
+		for _, arn := range []string{testMethodARN, otherMethodARN} {
+			got, err := policy.Handle(context.Background(), events.APIGatewayCustomAuthorizerRequest{AuthorizationToken: "Bearer c", MethodArn: arn})
+			require.NoError(t, err)
+			assert.Equal(t, allowStatement(testMethodARN), got.PolicyDocument)
+		}
+		assert.Equal(t, 1, calls)
+	})
+}
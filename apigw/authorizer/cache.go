@@ -0,0 +1,81 @@
+package authorizer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+)
+
+const defaultCacheSize = 10000
+
+type cacheEntry struct {
+	result  Result
+	err     error
+	expires time.Time
+}
+
+// cache is an in-memory cache of the decisions of a Handler, keyed by a hash of the token, with or without the method,
+// so tokens are not kept in memory. It lives as long as the Lambda container, so each container has its own.
+type cache struct {
+	clock   clock.Clock
+	ttl     time.Duration
+	size    int
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func newCache(ttl time.Duration) *cache {
+	return &cache{
+		clock:   clock.NewSystem(),
+		ttl:     ttl,
+		size:    defaultCacheSize,
+		entries: map[string]cacheEntry{},
+	}
+}
+
+// get returns the decision cached for key, if it has not expired.
+func (c *cache) get(key string) (cacheEntry, bool) {
+	key = hashKey(key)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return cacheEntry{}, false
+	}
+	if !c.clock.Now().Before(entry.expires) {
+		delete(c.entries, key)
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// set caches the decision for key. When the cache is full, expired entries are removed first, and then an arbitrary
+// entry.
+func (c *cache) set(key string, result Result, err error) {
+	key = hashKey(key)
+	now := c.clock.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < c.size {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cacheEntry{result: result, err: err, expires: now.Add(c.ttl)}
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
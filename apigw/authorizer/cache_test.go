@@ -0,0 +1,58 @@
+package authorizer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := newCache(time.Minute)
+	c.clock = clock.NewFixed(now)
+
+	c.set("token-1", Result{PrincipalID: "user-1"}, nil)
+	c.set("token-2", Result{}, ErrForbidden)
+
+	entry, ok := c.get("token-1")
+	assert.True(t, ok)
+	assert.Equal(t, cacheEntry{result: Result{PrincipalID: "user-1"}, expires: now.Add(time.Minute)}, entry)
+	entry, ok = c.get("token-2")
+	assert.True(t, ok)
+	assert.ErrorIs(t, entry.err, ErrForbidden)
+	_, ok = c.get("token-3")
+	assert.False(t, ok)
+	assert.NotContains(t, c.entries, "token-1", "entries keyed by hash")
+
+	c.clock = clock.NewFixed(now.Add(time.Minute))
+	_, ok = c.get("token-1")
+	assert.False(t, ok, "expired")
+	assert.Len(t, c.entries, 1, "expired entry removed")
+}
+
+func TestCache_full(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := newCache(time.Minute)
+	c.clock = clock.NewFixed(now)
+	c.size = 2
+
+	c.set("token-1", Result{}, nil)
+	c.clock = clock.NewFixed(now.Add(30 * time.Second))
+	c.set("token-2", Result{}, nil)
+	c.clock = clock.NewFixed(now.Add(time.Minute))
+	c.set("token-3", Result{}, nil)
+
+	assert.Len(t, c.entries, 2)
+	_, ok := c.get("token-1")
+	assert.False(t, ok, "expired entry evicted")
+	_, ok = c.get("token-3")
+	assert.True(t, ok)
+
+	c.set("token-4", Result{}, nil)
+	assert.Len(t, c.entries, 2, "arbitrary entry evicted when none expired")
+	_, ok = c.get("token-4")
+	assert.True(t, ok)
+}
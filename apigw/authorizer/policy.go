@@ -0,0 +1,154 @@
+package authorizer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	policyVersion = "2012-10-17"
+	invokeAction  = "execute-api:Invoke"
+
+	// AnyMethod matches every HTTP method of a method ARN.
+	AnyMethod = "*"
+)
+
+// ErrInvalidMethodARN is returned by ParseMethodARN for an ARN that is not a method ARN of API Gateway.
+var ErrInvalidMethodARN = errors.New("invalid method ARN")
+
+// MethodARN is the ARN of a method of an API Gateway stage, e.g.
+// "arn:aws:execute-api:eu-west-2:123456789012:abc123/prod/GET/customers/123". For HTTP APIs it is the route ARN.
+type MethodARN struct {
+	Partition string
+	Region    string
+	AccountID string
+	APIID     string
+	Stage     string
+	// Method is the HTTP method, or AnyMethod.
+	Method string
+	// Resource is the resource path without a leading slash, e.g. "customers/123". It may contain "*" wildcards.
+	Resource string
+}
+
+// ParseMethodARN parses a method ARN, such as the MethodArn of a TOKEN or REQUEST authorizer event.
+func ParseMethodARN(arn string) (MethodARN, error) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "execute-api" {
+		return MethodARN{}, fmt.Errorf("%w: %q", ErrInvalidMethodARN, arn)
+	}
+	path := strings.SplitN(parts[5], "/", 4)
+	if len(path) < 3 {
+		return MethodARN{}, fmt.Errorf("%w: %q", ErrInvalidMethodARN, arn)
+	}
+	m := MethodARN{
+		Partition: parts[1],
+		Region:    parts[3],
+		AccountID: parts[4],
+		APIID:     path[0],
+		Stage:     path[1],
+		Method:    path[2],
+	}
+	if len(path) == 4 {
+		m.Resource = path[3]
+	}
+	return m, nil
+}
+
+// String returns the ARN.
+func (m MethodARN) String() string {
+	return fmt.Sprintf("arn:%s:execute-api:%s:%s:%s/%s/%s/%s",
+		m.Partition, m.Region, m.AccountID, m.APIID, m.Stage, m.Method, m.Resource)
+}
+
+// Policy builds the IAM policy of an authorizer response, allowing or denying methods of the API stage of a request.
+type Policy struct {
+	base       MethodARN
+	statements []events.IAMPolicyStatement
+}
+
+// NewPolicy returns a Policy without any statements, for methods of the API stage of arn. An arn that cannot be parsed
+// is used with wildcards for the account, API and stage, so the policy allows nothing it does not name.
+func NewPolicy(arn string) *Policy {
+	base, err := ParseMethodARN(arn)
+	if err != nil {
+		base = MethodARN{Partition: "aws", Region: "*", AccountID: "*", APIID: "*", Stage: "*"}
+	}
+	return &Policy{base: base}
+}
+
+// Allow allows method (or AnyMethod) on the resource path, which may contain "*" wildcards, e.g. "/customers/*".
+func (p *Policy) Allow(method, path string) *Policy {
+	return p.add("Allow", method, path)
+}
+
+// Deny denies method (or AnyMethod) on the resource path, which may contain "*" wildcards. A deny takes precedence over
+// any allow.
+func (p *Policy) Deny(method, path string) *Policy {
+	return p.add("Deny", method, path)
+}
+
+// AllowAll allows every method of the API stage.
+func (p *Policy) AllowAll() *Policy {
+	return p.Allow(AnyMethod, "*")
+}
+
+// DenyAll denies every method of the API stage.
+func (p *Policy) DenyAll() *Policy {
+	return p.Deny(AnyMethod, "*")
+}
+
+// AllowARN allows the method of arn, e.g. the MethodArn of the request.
+func (p *Policy) AllowARN(arn string) *Policy {
+	return p.addARN("Allow", arn)
+}
+
+// DenyARN denies the method of arn, e.g. the MethodArn of the request.
+func (p *Policy) DenyARN(arn string) *Policy {
+	return p.addARN("Deny", arn)
+}
+
+// Document returns the policy document. A policy without statements denies every method of the API stage.
+func (p *Policy) Document() events.APIGatewayCustomAuthorizerPolicy {
+	if len(p.statements) == 0 {
+		all := p.base
+		all.Method, all.Resource = AnyMethod, "*"
+		return events.APIGatewayCustomAuthorizerPolicy{
+			Version:   policyVersion,
+			Statement: []events.IAMPolicyStatement{{Action: []string{invokeAction}, Effect: "Deny", Resource: []string{all.String()}}},
+		}
+	}
+	statements := make([]events.IAMPolicyStatement, len(p.statements))
+	for i, s := range p.statements {
+		s.Resource = append([]string(nil), s.Resource...)
+		statements[i] = s
+	}
+	return events.APIGatewayCustomAuthorizerPolicy{
+		Version:   policyVersion,
+		Statement: statements,
+	}
+}
+
+func (p *Policy) add(effect, method, path string) *Policy {
+	arn := p.base
+	arn.Method = strings.ToUpper(method)
+	arn.Resource = strings.TrimPrefix(path, "/")
+	return p.addARN(effect, arn.String())
+}
+
+func (p *Policy) addARN(effect, arn string) *Policy {
+	for i, s := range p.statements {
+		if s.Effect == effect {
+			p.statements[i].Resource = append(s.Resource, arn)
+			return p
+		}
+	}
+	p.statements = append(p.statements, events.IAMPolicyStatement{
+		Action:   []string{invokeAction},
+		Effect:   effect,
+		Resource: []string{arn},
+	})
+	return p
+}
@@ -0,0 +1,137 @@
+package authorizer
+
+import (
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+const testMethodARN = "arn:aws:execute-api:eu-west-2:123456789012:abc123/prod/GET/customers/123"
+
+func TestParseMethodARN(t *testing.T) {
+	tests := []struct {
+		name    string
+		arn     string
+		want    MethodARN
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "method arn, parsed",
+			arn:  testMethodARN,
+			want: MethodARN{
+				Partition: "aws",
+				Region:    "eu-west-2",
+				AccountID: "123456789012",
+				APIID:     "abc123",
+				Stage:     "prod",
+				Method:    "GET",
+				Resource:  "customers/123",
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "root resource, parsed with empty resource",
+			arn:  "arn:aws:execute-api:eu-west-2:123456789012:abc123/prod/POST/",
+			want: MethodARN{
+				Partition: "aws",
+				Region:    "eu-west-2",
+				AccountID: "123456789012",
+				APIID:     "abc123",
+				Stage:     "prod",
+				Method:    "POST",
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "not execute-api, ErrInvalidMethodARN",
+			arn:  "arn:aws:s3:::bucket/key",
+			wantErr: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.ErrorIs(t, err, ErrInvalidMethodARN)
+			},
+		},
+		{
+			name: "no method, ErrInvalidMethodARN",
+			arn:  "arn:aws:execute-api:eu-west-2:123456789012:abc123/prod",
+			wantErr: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.ErrorIs(t, err, ErrInvalidMethodARN)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMethodARN(tt.arn)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMethodARN_String(t *testing.T) {
+	m, err := ParseMethodARN(testMethodARN)
+
+	assert.NoError(t, err)
+	assert.Equal(t, testMethodARN, m.String())
+}
+
+func TestPolicy_Document(t *testing.T) {
+	const stage = "arn:aws:execute-api:eu-west-2:123456789012:abc123/prod/"
+	tests := []struct {
+		name   string
+		policy *Policy
+		want   []events.IAMPolicyStatement
+	}{
+		{
+			name:   "allow and deny with wildcards, statement per effect",
+			policy: NewPolicy(testMethodARN).Allow("get", "/customers/*").Allow(AnyMethod, "/orders").Deny("DELETE", "/customers/*"),
+			want: []events.IAMPolicyStatement{
+				{
+					Action:   []string{"execute-api:Invoke"},
+					Effect:   "Allow",
+					Resource: []string{stage + "GET/customers/*", stage + "*/orders"},
+				},
+				{
+					Action:   []string{"execute-api:Invoke"},
+					Effect:   "Deny",
+					Resource: []string{stage + "DELETE/customers/*"},
+				},
+			},
+		},
+		{
+			name:   "allow all, every method of the stage allowed",
+			policy: NewPolicy(testMethodARN).AllowAll(),
+			want: []events.IAMPolicyStatement{
+				{Action: []string{"execute-api:Invoke"}, Effect: "Allow", Resource: []string{stage + "*/*"}},
+			},
+		},
+		{
+			name:   "allow arn, method of the request allowed",
+			policy: NewPolicy(testMethodARN).AllowARN(testMethodARN),
+			want: []events.IAMPolicyStatement{
+				{Action: []string{"execute-api:Invoke"}, Effect: "Allow", Resource: []string{testMethodARN}},
+			},
+		},
+		{
+			name:   "no statements, every method of the stage denied",
+			policy: NewPolicy(testMethodARN),
+			want: []events.IAMPolicyStatement{
+				{Action: []string{"execute-api:Invoke"}, Effect: "Deny", Resource: []string{stage + "*/*"}},
+			},
+		},
+		{
+			name:   "invalid arn, wildcards for the stage",
+			policy: NewPolicy("invalid").Allow("GET", "/customers"),
+			want: []events.IAMPolicyStatement{
+				{Action: []string{"execute-api:Invoke"}, Effect: "Allow", Resource: []string{"arn:aws:execute-api:*:*:*/*/GET/customers"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, events.APIGatewayCustomAuthorizerPolicy{
+				Version:   "2012-10-17",
+				Statement: tt.want,
+			}, tt.policy.Document())
+		})
+	}
+}
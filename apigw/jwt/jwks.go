@@ -0,0 +1,285 @@
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+)
+
+const (
+	defaultJWKSTTL                = time.Hour
+	defaultJWKSMinRefreshInterval = time.Minute
+)
+
+var (
+	// ErrUnknownKey is returned when a token is signed with a key id that is not in the JWKS, even after refreshing it.
+	ErrUnknownKey = errors.New("jwt: unknown key id")
+	// ErrJWKSUnavailable is returned when the JWKS could not be fetched and the key of a token is not cached.
+	ErrJWKSUnavailable = errors.New("jwt: jwks unavailable")
+)
+
+// Fetcher interface should be implemented for sources of a JSON Web Key Set, e.g. the jwks_uri of an identity provider
+// (see NewHTTPFetcher) or a file bundled with the function. Fake it in tests.
+type Fetcher interface {
+	// Fetch returns the JWKS document, a JSON object with a "keys" array.
+	Fetch(ctx context.Context) ([]byte, error)
+}
+
+// FetcherFunc is an adapter to allow the use of ordinary functions as a Fetcher.
+type FetcherFunc func(ctx context.Context) ([]byte, error)
+
+// Fetch calls f(ctx).
+func (f FetcherFunc) Fetch(ctx context.Context) ([]byte, error) {
+	return f(ctx)
+}
+
+// HTTPFetcher fetches a JWKS from a url with an HTTP GET.
+type HTTPFetcher struct {
+	client *http.Client
+	url    string
+}
+
+// NewHTTPFetcher returns an HTTPFetcher fetching the JWKS at url with client, or http.DefaultClient if client is nil.
+func NewHTTPFetcher(client *http.Client, url string) *HTTPFetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPFetcher{client: client, url: url}
+}
+
+// Fetch GETs the JWKS, returning an error unless the response status is 200 OK.
+func (f *HTTPFetcher) Fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return nil, fmt.Errorf("unexpected status %d: %s", res.StatusCode, bytes.TrimSpace(msg))
+	}
+	return io.ReadAll(res.Body)
+}
+
+type jwksOptions struct {
+	ttl                time.Duration
+	minRefreshInterval time.Duration
+}
+
+// JWKSOption configures NewJWKS.
+type JWKSOption func(*jwksOptions)
+
+// WithJWKSTTL sets how long fetched keys are used before the JWKS is fetched again. Defaults to 1 hour.
+func WithJWKSTTL(d time.Duration) JWKSOption {
+	return func(o *jwksOptions) {
+		o.ttl = d
+	}
+}
+
+// WithJWKSMinRefreshInterval sets the minimum time between fetches of the JWKS when a token is signed with an unknown
+// key id, so tokens with made up key ids can not be used to flood the identity provider, and between retries of a
+// failed fetch once the TTL has passed, so an unavailable identity provider is not fetched from on every token.
+// Defaults to 1 minute.
+func WithJWKSMinRefreshInterval(d time.Duration) JWKSOption {
+	return func(o *jwksOptions) {
+		o.minRefreshInterval = d
+	}
+}
+
+// key is a verification key of a JWKS.
+type key struct {
+	alg    string
+	public crypto.PublicKey
+	secret []byte
+}
+
+// JWKS is a cache of the keys of a JSON Web Key Set. Keys are fetched on first use, again once the TTL has passed, and
+// again when a token is signed with a key id not in the cache, for keys rotated in by the identity provider. It lives
+// as long as the Lambda container, so create it once, outside the handler.
+type JWKS struct {
+	clock   clock.Clock
+	fetcher Fetcher
+	opts    jwksOptions
+	mu      sync.Mutex
+	keys    map[string]key
+	fetched time.Time
+	// attempted is the time of the last fetch, successful or not, and fetchErr its error.
+	attempted time.Time
+	fetchErr  error
+}
+
+// NewJWKS returns a JWKS with the keys returned by fetcher.
+func NewJWKS(fetcher Fetcher, options ...JWKSOption) *JWKS {
+	opts := jwksOptions{ttl: defaultJWKSTTL, minRefreshInterval: defaultJWKSMinRefreshInterval}
+	for _, option := range options {
+		option(&opts)
+	}
+	return &JWKS{
+		clock:   clock.NewSystem(),
+		fetcher: fetcher,
+		opts:    opts,
+	}
+}
+
+// key returns the key with id kid, fetching the JWKS if the cache has expired or does not contain kid, at most once per
+// minimum refresh interval. If a fetch fails the cached keys keep being used.
+func (j *JWKS) key(ctx context.Context, kid string) (key, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.clock.Now()
+	if (j.fetched.IsZero() || now.Sub(j.fetched) >= j.opts.ttl) && j.canRefresh(now) {
+		j.refresh(ctx, now)
+	}
+	if k, ok := j.keys[kid]; ok {
+		return k, nil
+	}
+	if j.canRefresh(now) {
+		j.refresh(ctx, now)
+		if k, ok := j.keys[kid]; ok {
+			return k, nil
+		}
+	}
+	if j.fetchErr != nil {
+		return key{}, fmt.Errorf("%w: %w", ErrJWKSUnavailable, j.fetchErr)
+	}
+	return key{}, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+}
+
+// canRefresh reports whether the minimum refresh interval has passed since the last fetch.
+func (j *JWKS) canRefresh(now time.Time) bool {
+	return j.attempted.IsZero() || now.Sub(j.attempted) >= j.opts.minRefreshInterval
+}
+
+// refresh fetches the JWKS, replacing the cached keys, and records the attempt and its error.
+func (j *JWKS) refresh(ctx context.Context, now time.Time) {
+	j.attempted = now
+	data, err := j.fetcher.Fetch(ctx)
+	if err != nil {
+		j.fetchErr = err
+		return
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		j.fetchErr = err
+		return
+	}
+	j.keys, j.fetched, j.fetchErr = keys, now, nil
+}
+
+// jwk is a JSON Web Key, as defined by RFC 7517.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// parseJWKS parses the signature keys of a JWKS document by key id. Keys of unsupported types are skipped.
+func parseJWKS(data []byte) (map[string]key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("decoding jwks: %w", err)
+	}
+
+	keys := make(map[string]key, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		parsed, err := parseJWK(k)
+		if err != nil {
+			return nil, fmt.Errorf("parsing key %q: %w", k.Kid, err)
+		}
+		if parsed.alg != "" {
+			keys[k.Kid] = parsed
+		}
+	}
+	return keys, nil
+}
+
+// parseJWK parses a key, returning a key without an alg if its type is not supported.
+func parseJWK(k jwk) (key, error) {
+	switch {
+	case k.Kty == "RSA" && (k.Alg == "" || k.Alg == AlgRS256):
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return key{}, fmt.Errorf("decoding n: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return key{}, fmt.Errorf("decoding e: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return key{}, errors.New("exponent too large")
+		}
+		return key{alg: AlgRS256, public: &rsa.PublicKey{N: n, E: int(e.Int64())}}, nil
+	case k.Kty == "EC" && k.Crv == "P-256" && (k.Alg == "" || k.Alg == AlgES256):
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return key{}, fmt.Errorf("decoding x: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return key{}, fmt.Errorf("decoding y: %w", err)
+		}
+		if len(x) != 32 || len(y) != 32 {
+			return key{}, errors.New("invalid P-256 coordinates")
+		}
+		public, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), append(append([]byte{4}, x...), y...))
+		if err != nil {
+			return key{}, err
+		}
+		return key{alg: AlgES256, public: public}, nil
+	case k.Kty == "oct" && (k.Alg == "" || k.Alg == AlgHS256):
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return key{}, fmt.Errorf("decoding k: %w", err)
+		}
+		if len(secret) == 0 {
+			return key{}, errors.New("empty secret")
+		}
+		return key{alg: AlgHS256, secret: secret}, nil
+	}
+	return key{}, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
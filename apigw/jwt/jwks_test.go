@@ -0,0 +1,153 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+)
+
+// countingFetcher returns the test JWKS, or err, counting the fetches.
+type countingFetcher struct {
+	fetches int
+	err     error
+}
+
+func (f *countingFetcher) Fetch(context.Context) ([]byte, error) {
+	f.fetches++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return testJWKSDocument(), nil
+}
+
+func TestJWKS_key(t *testing.T) {
+	fetcher := &countingFetcher{}
+	keys := NewJWKS(fetcher, WithJWKSTTL(time.Hour), WithJWKSMinRefreshInterval(time.Minute))
+	keys.clock = clock.NewFixed(testNow)
+	ctx := context.Background()
+
+	for _, kid := range []string{"rsa", "ec", "hmac", "rsa"} {
+		_, err := keys.key(ctx, kid)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, fetcher.fetches, "keys cached")
+
+	_, err := keys.key(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Equal(t, 1, fetcher.fetches, "unknown kid within min refresh interval, not fetched")
+
+	keys.clock = clock.NewFixed(testNow.Add(time.Minute))
+	_, err = keys.key(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Equal(t, 2, fetcher.fetches, "unknown kid after min refresh interval, fetched")
+
+	keys.clock = clock.NewFixed(testNow.Add(time.Minute + time.Hour))
+	_, err = keys.key(ctx, "rsa")
+	require.NoError(t, err)
+	assert.Equal(t, 3, fetcher.fetches, "keys expired, fetched")
+}
+
+func TestJWKS_key_fetchFailure(t *testing.T) {
+	fetcher := &countingFetcher{err: errors.New("connection refused")}
+	keys := NewJWKS(fetcher)
+	keys.clock = clock.NewFixed(testNow)
+	ctx := context.Background()
+
+	_, err := keys.key(ctx, "rsa")
+	assert.ErrorIs(t, err, ErrJWKSUnavailable)
+	assert.ErrorContains(t, err, "connection refused")
+
+	fetcher.err = nil
+	_, err = keys.key(ctx, "rsa")
+	assert.ErrorIs(t, err, ErrJWKSUnavailable, "failed fetch within min refresh interval, not retried")
+	assert.Equal(t, 1, fetcher.fetches)
+
+	keys.clock = clock.NewFixed(testNow.Add(time.Minute))
+	_, err = keys.key(ctx, "rsa")
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetcher.fetches, "failed fetch after min refresh interval, retried")
+
+	fetcher.err = errors.New("connection refused")
+	keys.clock = clock.NewFixed(testNow.Add(2 * time.Hour))
+	_, err = keys.key(ctx, "rsa")
+	assert.NoError(t, err, "cached keys used when fetch fails")
+	_, err = keys.key(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrJWKSUnavailable)
+	assert.Equal(t, 3, fetcher.fetches)
+
+	keys.clock = clock.NewFixed(testNow.Add(2*time.Hour + time.Second))
+	_, err = keys.key(ctx, "rsa")
+	assert.NoError(t, err, "cached keys used when fetch fails")
+	assert.Equal(t, 3, fetcher.fetches, "expired keys within min refresh interval of failed fetch, not fetched")
+}
+
+func TestParseJWKS(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantKids []string
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		{
+			name:     "supported keys, parsed by kid",
+			data:     string(testJWKSDocument()),
+			wantKids: []string{"ec", "hmac", "rsa"},
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "unsupported key types, skipped",
+			data:     `{"keys":[{"kty":"OKP","kid":"ed","crv":"Ed25519","x":"AQAB"},{"kty":"RSA","kid":"ps","alg":"PS256","n":"AQAB","e":"AQAB"}]}`,
+			wantKids: []string{},
+			wantErr:  assert.NoError,
+		},
+		{
+			name:    "not json, error",
+			data:    `<html>`,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid key, error",
+			data:    `{"keys":[{"kty":"EC","kid":"ec","crv":"P-256","x":"AQAB","y":"AQAB"}]}`,
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJWKS([]byte(tt.data))
+			tt.wantErr(t, err)
+			if tt.wantKids != nil {
+				kids := make([]string, 0, len(got))
+				for kid := range got {
+					kids = append(kids, kid)
+				}
+				assert.ElementsMatch(t, tt.wantKids, kids)
+			}
+		})
+	}
+}
+
+func TestHTTPFetcher_Fetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/jwks.json" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(testJWKSDocument())
+	}))
+	defer server.Close()
+
+	got, err := NewHTTPFetcher(server.Client(), server.URL+"/.well-known/jwks.json").Fetch(context.Background())
+	require.NoError(t, err)
+	assert.JSONEq(t, string(testJWKSDocument()), string(got))
+
+	_, err = NewHTTPFetcher(nil, server.URL+"/missing").Fetch(context.Background())
+	assert.EqualError(t, err, "unexpected status 404: not found")
+}
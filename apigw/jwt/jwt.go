@@ -0,0 +1,326 @@
+// Package jwt verifies JSON Web Tokens, signed with RS256, ES256 or HS256, against the keys of a JWKS.
+package jwt
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+)
+
+// Supported signature algorithms.
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgHS256 = "HS256"
+)
+
+var (
+	// ErrMalformed is returned for a token that is not a JWS compact serialization with a JSON header and claims.
+	ErrMalformed = errors.New("jwt: malformed token")
+	// ErrUnsupportedAlgorithm is returned for a token not signed with RS256, ES256 or HS256, or signed with an algorithm
+	// other than the one of its key.
+	ErrUnsupportedAlgorithm = errors.New("jwt: unsupported algorithm")
+	// ErrInvalidSignature is returned for a token whose signature does not match its key.
+	ErrInvalidSignature = errors.New("jwt: invalid signature")
+	// ErrExpired is returned for a token without an exp claim, or whose exp claim has passed.
+	ErrExpired = errors.New("jwt: token expired")
+	// ErrNotYetValid is returned for a token whose nbf claim has not been reached.
+	ErrNotYetValid = errors.New("jwt: token not yet valid")
+	// ErrInvalidIssuer is returned for a token whose iss claim is not one of the issuers of the Verifier.
+	ErrInvalidIssuer = errors.New("jwt: invalid issuer")
+	// ErrInvalidAudience is returned for a token whose aud claim contains none of the audiences of the Verifier.
+	ErrInvalidAudience = errors.New("jwt: invalid audience")
+	// ErrInsufficientScope is returned by Claims.RequireScopes when a scope is missing.
+	ErrInsufficientScope = errors.New("jwt: insufficient scope")
+)
+
+// Claims are the claims of a verified token.
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	// Scopes are the scopes of the space-delimited scope claim, or of the scp claim.
+	Scopes []string
+	// Raw contains every claim of the token, including the ones above, with numbers decoded as json.Number.
+	Raw map[string]any
+}
+
+// RequireScopes returns ErrInsufficientScope, naming the missing scopes, unless the claims contain all scopes.
+func (c Claims) RequireScopes(scopes ...string) error {
+	var missing []string
+	for _, scope := range scopes {
+		if !slices.Contains(c.Scopes, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrInsufficientScope, strings.Join(missing, " "))
+	}
+	return nil
+}
+
+type verifierOptions struct {
+	issuers   []string
+	audiences []string
+	leeway    time.Duration
+}
+
+// VerifierOption configures NewVerifier.
+type VerifierOption func(*verifierOptions)
+
+// WithIssuer requires the iss claim of tokens to be one of issuers.
+func WithIssuer(issuers ...string) VerifierOption {
+	return func(o *verifierOptions) {
+		o.issuers = issuers
+	}
+}
+
+// WithAudience requires the aud claim of tokens to contain one of audiences.
+func WithAudience(audiences ...string) VerifierOption {
+	return func(o *verifierOptions) {
+		o.audiences = audiences
+	}
+}
+
+// WithLeeway allows for clock skew with the issuer when checking the exp and nbf claims. Defaults to none.
+func WithLeeway(d time.Duration) VerifierOption {
+	return func(o *verifierOptions) {
+		o.leeway = d
+	}
+}
+
+// Verifier verifies tokens against the keys of a JWKS.
+type Verifier struct {
+	clock clock.Clock
+	keys  *JWKS
+	opts  verifierOptions
+}
+
+// NewVerifier returns a Verifier of tokens signed with keys.
+func NewVerifier(keys *JWKS, options ...VerifierOption) *Verifier {
+	var opts verifierOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	return &Verifier{
+		clock: clock.NewSystem(),
+		keys:  keys,
+		opts:  opts,
+	}
+}
+
+// Verify verifies the signature of token and its exp, nbf, iss and aud claims, returning its claims.
+func (v *Verifier) Verify(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrMalformed
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Claims{}, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, ErrMalformed
+	}
+	if header.Alg != AlgRS256 && header.Alg != AlgES256 && header.Alg != AlgHS256 {
+		return Claims{}, fmt.Errorf("%w %q", ErrUnsupportedAlgorithm, header.Alg)
+	}
+
+	k, err := v.keys.key(ctx, header.Kid)
+	if err != nil {
+		return Claims{}, err
+	}
+	if k.alg != header.Alg {
+		return Claims{}, fmt.Errorf("%w %q for key %q", ErrUnsupportedAlgorithm, header.Alg, header.Kid)
+	}
+	if !verifySignature(k, parts[0]+"."+parts[1], signature) {
+		return Claims{}, ErrInvalidSignature
+	}
+
+	var raw map[string]any
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return Claims{}, err
+	}
+	claims, err := parseClaims(raw)
+	if err != nil {
+		return Claims{}, err
+	}
+	return claims, v.validate(claims)
+}
+
+// validate checks the registered claims of a token with a valid signature.
+func (v *Verifier) validate(claims Claims) error {
+	now := v.clock.Now()
+	if claims.ExpiresAt.IsZero() || !now.Before(claims.ExpiresAt.Add(v.opts.leeway)) {
+		return ErrExpired
+	}
+	if !claims.NotBefore.IsZero() && now.Add(v.opts.leeway).Before(claims.NotBefore) {
+		return ErrNotYetValid
+	}
+	if len(v.opts.issuers) > 0 && !slices.Contains(v.opts.issuers, claims.Issuer) {
+		return fmt.Errorf("%w %q", ErrInvalidIssuer, claims.Issuer)
+	}
+	if len(v.opts.audiences) > 0 && !slices.ContainsFunc(claims.Audience, func(aud string) bool {
+		return slices.Contains(v.opts.audiences, aud)
+	}) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+func verifySignature(k key, signed string, signature []byte) bool {
+	switch k.alg {
+	case AlgRS256:
+		public, ok := k.public.(*rsa.PublicKey)
+		digest := sha256.Sum256([]byte(signed))
+		return ok && rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], signature) == nil
+	case AlgES256:
+		public, ok := k.public.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return false
+		}
+		digest := sha256.Sum256([]byte(signed))
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(public, digest[:], r, s)
+	case AlgHS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write([]byte(signed))
+		return hmac.Equal(mac.Sum(nil), signature)
+	}
+	return false
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrMalformed
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	return nil
+}
+
+// parseClaims parses the registered claims and scopes of raw, returning ErrMalformed for claims of the wrong type.
+func parseClaims(raw map[string]any) (Claims, error) {
+	claims := Claims{Raw: raw}
+	var err error
+	if claims.Issuer, err = stringClaim(raw, "iss"); err != nil {
+		return Claims{}, err
+	}
+	if claims.Subject, err = stringClaim(raw, "sub"); err != nil {
+		return Claims{}, err
+	}
+	if claims.Audience, err = stringsClaim(raw, "aud"); err != nil {
+		return Claims{}, err
+	}
+	if claims.ExpiresAt, err = timeClaim(raw, "exp"); err != nil {
+		return Claims{}, err
+	}
+	if claims.NotBefore, err = timeClaim(raw, "nbf"); err != nil {
+		return Claims{}, err
+	}
+	if claims.IssuedAt, err = timeClaim(raw, "iat"); err != nil {
+		return Claims{}, err
+	}
+
+	scope, err := stringClaim(raw, "scope")
+	if err != nil {
+		return Claims{}, err
+	}
+	if scope != "" {
+		claims.Scopes = strings.Fields(scope)
+	} else if claims.Scopes, err = stringsClaim(raw, "scp"); err != nil {
+		return Claims{}, err
+	}
+	return claims, nil
+}
+
+func stringClaim(raw map[string]any, name string) (string, error) {
+	v, ok := raw[name]
+	if !ok {
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %s claim is not a string", ErrMalformed, name)
+	}
+	return s, nil
+}
+
+// stringsClaim parses a claim that is either a string or an array of strings. A space-delimited string is split.
+func stringsClaim(raw map[string]any, name string) ([]string, error) {
+	switch v := raw[name].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return strings.Fields(v), nil
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s claim is not an array of strings", ErrMalformed, name)
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("%w: %s claim is not a string or an array of strings", ErrMalformed, name)
+}
+
+// timeClaim parses a NumericDate claim, the number of seconds since the Unix epoch.
+func timeClaim(raw map[string]any, name string) (time.Time, error) {
+	v, ok := raw[name]
+	if !ok {
+		return time.Time{}, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: %s claim is not a number", ErrMalformed, name)
+	}
+	f, err := n.Float64()
+	if err != nil || math.IsInf(f, 0) {
+		return time.Time{}, fmt.Errorf("%w: %s claim is not a number", ErrMalformed, name)
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))).UTC(), nil
+}
+
+type claimsContextKey struct{}
+
+// NewContext returns a copy of ctx carrying claims, for handlers to retrieve with FromContext.
+func NewContext(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// FromContext returns the claims carried by ctx, added by the auth middleware or NewContext.
+func FromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(Claims)
+	return claims, ok
+}
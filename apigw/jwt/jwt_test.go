@@ -0,0 +1,302 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+)
+
+var (
+	testNow    = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	testSecret = []byte("0123456789abcdef0123456789abcdef")
+	testRSAKey = mustRSAKey()
+	testECKey  = mustECKey()
+)
+
+func mustRSAKey() *rsa.PrivateKey {
+	k, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+func mustECKey() *ecdsa.PrivateKey {
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return k
+}
+
+func encodeSegment(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// sign returns a token with claims signed with alg by the test key of alg.
+func sign(alg, kid string, claims map[string]any) string {
+	signed := encodeSegment(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + encodeSegment(claims)
+	digest := sha256.Sum256([]byte(signed))
+	var signature []byte
+	switch alg {
+	case AlgRS256:
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, testRSAKey, crypto.SHA256, digest[:])
+	case AlgES256:
+		r, s, _ := ecdsa.Sign(rand.Reader, testECKey, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case AlgHS256:
+		mac := hmac.New(sha256.New, testSecret)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// tamper replaces the claims of token, keeping its header and signature.
+func tamper(token string, claims map[string]any) string {
+	parts := strings.Split(token, ".")
+	return parts[0] + "." + encodeSegment(claims) + "." + parts[2]
+}
+
+func testJWKSDocument() []byte {
+	public, err := testECKey.PublicKey.Bytes()
+	if err != nil {
+		panic(err)
+	}
+	data, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{
+			"kty": "RSA", "kid": "rsa", "use": "sig", "alg": "RS256",
+			"n": base64.RawURLEncoding.EncodeToString(testRSAKey.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(testRSAKey.E)).Bytes()),
+		},
+		{
+			"kty": "EC", "kid": "ec", "crv": "P-256",
+			"x": base64.RawURLEncoding.EncodeToString(public[1:33]),
+			"y": base64.RawURLEncoding.EncodeToString(public[33:]),
+		},
+		{"kty": "oct", "kid": "hmac", "alg": "HS256", "k": base64.RawURLEncoding.EncodeToString(testSecret)},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+	}})
+	return data
+}
+
+func testClaims() map[string]any {
+	return map[string]any{
+		"iss":   "https://issuer.example.com",
+		"sub":   "user-123",
+		"aud":   "orders-api",
+		"exp":   testNow.Add(time.Hour).Unix(),
+		"iat":   testNow.Add(-time.Minute).Unix(),
+		"scope": "orders:read orders:write",
+	}
+}
+
+func withClaims(changes map[string]any) map[string]any {
+	claims := testClaims()
+	for k, v := range changes {
+		if v == nil {
+			delete(claims, k)
+			continue
+		}
+		claims[k] = v
+	}
+	return claims
+}
+
+func newTestVerifier(options ...VerifierOption) *Verifier {
+	keys := NewJWKS(FetcherFunc(func(context.Context) ([]byte, error) { return testJWKSDocument(), nil }))
+	keys.clock = clock.NewFixed(testNow)
+	v := NewVerifier(keys, options...)
+	v.clock = clock.NewFixed(testNow)
+	return v
+}
+
+func errorIs(target error) assert.ErrorAssertionFunc {
+	return func(t assert.TestingT, err error, _ ...any) bool {
+		return assert.ErrorIs(t, err, target)
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []VerifierOption
+		token   string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "RS256, verified",
+			options: []VerifierOption{WithIssuer("https://issuer.example.com"), WithAudience("orders-api")},
+			token:   sign(AlgRS256, "rsa", testClaims()),
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "ES256, verified",
+			token:   sign(AlgES256, "ec", testClaims()),
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "HS256, verified",
+			token:   sign(AlgHS256, "hmac", testClaims()),
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "not three segments, ErrMalformed",
+			token:   "abc.def",
+			wantErr: errorIs(ErrMalformed),
+		},
+		{
+			name:    "header not json, ErrMalformed",
+			token:   "YWJj.e30.c2ln",
+			wantErr: errorIs(ErrMalformed),
+		},
+		{
+			name:    "alg none, ErrUnsupportedAlgorithm",
+			token:   encodeSegment(map[string]string{"alg": "none", "kid": "rsa"}) + "." + encodeSegment(testClaims()) + ".",
+			wantErr: errorIs(ErrUnsupportedAlgorithm),
+		},
+		{
+			name:    "alg not of key, ErrUnsupportedAlgorithm",
+			token:   sign(AlgHS256, "rsa", testClaims()),
+			wantErr: errorIs(ErrUnsupportedAlgorithm),
+		},
+		{
+			name:    "unknown kid, ErrUnknownKey",
+			token:   sign(AlgRS256, "unknown", testClaims()),
+			wantErr: errorIs(ErrUnknownKey),
+		},
+		{
+			name:    "encryption key, ErrUnknownKey",
+			token:   sign(AlgRS256, "enc", testClaims()),
+			wantErr: errorIs(ErrUnknownKey),
+		},
+		{
+			name:    "claims changed after signing, ErrInvalidSignature",
+			token:   tamper(sign(AlgRS256, "rsa", testClaims()), withClaims(map[string]any{"sub": "admin"})),
+			wantErr: errorIs(ErrInvalidSignature),
+		},
+		{
+			name:    "expired, ErrExpired",
+			token:   sign(AlgHS256, "hmac", withClaims(map[string]any{"exp": testNow.Unix()})),
+			wantErr: errorIs(ErrExpired),
+		},
+		{
+			name:    "expired within leeway, verified",
+			options: []VerifierOption{WithLeeway(time.Minute)},
+			token:   sign(AlgHS256, "hmac", withClaims(map[string]any{"exp": testNow.Add(-30 * time.Second).Unix()})),
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "no exp, ErrExpired",
+			token:   sign(AlgHS256, "hmac", withClaims(map[string]any{"exp": nil})),
+			wantErr: errorIs(ErrExpired),
+		},
+		{
+			name:    "nbf in the future, ErrNotYetValid",
+			token:   sign(AlgHS256, "hmac", withClaims(map[string]any{"nbf": testNow.Add(time.Minute).Unix()})),
+			wantErr: errorIs(ErrNotYetValid),
+		},
+		{
+			name:    "other issuer, ErrInvalidIssuer",
+			options: []VerifierOption{WithIssuer("https://other.example.com")},
+			token:   sign(AlgHS256, "hmac", testClaims()),
+			wantErr: errorIs(ErrInvalidIssuer),
+		},
+		{
+			name:    "other audience, ErrInvalidAudience",
+			options: []VerifierOption{WithAudience("customers-api")},
+			token:   sign(AlgHS256, "hmac", testClaims()),
+			wantErr: errorIs(ErrInvalidAudience),
+		},
+		{
+			name:    "one of audiences, verified",
+			options: []VerifierOption{WithAudience("customers-api")},
+			token:   sign(AlgHS256, "hmac", withClaims(map[string]any{"aud": []string{"orders-api", "customers-api"}})),
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "exp not a number, ErrMalformed",
+			token:   sign(AlgHS256, "hmac", withClaims(map[string]any{"exp": "tomorrow"})),
+			wantErr: errorIs(ErrMalformed),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestVerifier(tt.options...).Verify(context.Background(), tt.token)
+			tt.wantErr(t, err)
+		})
+	}
+}
+
+func TestVerifier_Verify_claims(t *testing.T) {
+	tests := []struct {
+		name       string
+		claims     map[string]any
+		wantScopes []string
+	}{
+		{
+			name:       "scope claim, split on spaces",
+			claims:     testClaims(),
+			wantScopes: []string{"orders:read", "orders:write"},
+		},
+		{
+			name:       "scp claim, used as is",
+			claims:     withClaims(map[string]any{"scope": nil, "scp": []string{"orders:read"}}),
+			wantScopes: []string{"orders:read"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestVerifier().Verify(context.Background(), sign(AlgRS256, "rsa", tt.claims))
+
+			require.NoError(t, err)
+			assert.Equal(t, "https://issuer.example.com", got.Issuer)
+			assert.Equal(t, "user-123", got.Subject)
+			assert.Equal(t, []string{"orders-api"}, got.Audience)
+			assert.Equal(t, testNow.Add(time.Hour), got.ExpiresAt)
+			assert.Equal(t, testNow.Add(-time.Minute), got.IssuedAt)
+			assert.True(t, got.NotBefore.IsZero())
+			assert.Equal(t, tt.wantScopes, got.Scopes)
+			assert.Equal(t, json.Number("1704114000"), got.Raw["exp"])
+		})
+	}
+}
+
+func TestClaims_RequireScopes(t *testing.T) {
+	claims := Claims{Scopes: []string{"orders:read", "orders:write"}}
+
+	assert.NoError(t, claims.RequireScopes())
+	assert.NoError(t, claims.RequireScopes("orders:read"))
+	err := claims.RequireScopes("orders:read", "orders:delete", "admin")
+	assert.ErrorIs(t, err, ErrInsufficientScope)
+	assert.EqualError(t, err, "jwt: insufficient scope: orders:delete admin")
+}
+
+func TestFromContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	got, ok := FromContext(NewContext(context.Background(), Claims{Subject: "user-123"}))
+	assert.True(t, ok)
+	assert.Equal(t, Claims{Subject: "user-123"}, got)
+}
@@ -0,0 +1,241 @@
+// Package local serves API Gateway handlers over net/http, so an endpoint can be tried with curl on a local port with
+// no AWS dependency. Requests are translated into API Gateway v1 (REST) or v2 (HTTP API) events and run through the
+// same middleware that lambda.StartWithResponse would apply.
+package local
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+const (
+	defaultTimeout     = 29 * time.Second
+	defaultStage       = "local"
+	defaultAccountID   = "000000000000"
+	defaultAPIID       = "local"
+	defaultReadTimeout = 10 * time.Second
+)
+
+type options struct {
+	routes           []route
+	binaryMediaTypes []string
+	timeout          time.Duration
+	stage            string
+}
+
+// Option configures NewHandler/NewHandlerV2.
+type Option func(*options)
+
+// WithRoute adds a route the handler serves. method is an HTTP method or "ANY", and template is an API Gateway
+// resource path such as "/customers/{id}" or "/files/{proxy+}", from which the event's path parameters are taken.
+// Routes are matched in the order they are added. With no routes, every request is served as "ANY /{proxy+}".
+func WithRoute(method, template string) Option {
+	return func(o *options) {
+		o.routes = append(o.routes, newRoute(method, template))
+	}
+}
+
+// WithBinaryMediaTypes sets the content types (e.g. "image/png", "image/*") whose request bodies are base64 encoded in
+// the event, like API Gateway's binary media types. Bodies that are not valid UTF-8 are always base64 encoded.
+func WithBinaryMediaTypes(mediaTypes ...string) Option {
+	return func(o *options) {
+		o.binaryMediaTypes = append(o.binaryMediaTypes, mediaTypes...)
+	}
+}
+
+// WithTimeout sets the deadline of each invocation's context. Defaults to 29s, API Gateway's integration timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithStage sets the stage of the event's request context. Defaults to "local".
+func WithStage(stage string) Option {
+	return func(o *options) {
+		o.stage = stage
+	}
+}
+
+// NewServer returns an http.Server for handler listening on addr (e.g. ":8080"), with timeouts set.
+func NewServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: defaultReadTimeout,
+		ReadTimeout:       defaultReadTimeout,
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		timeout: defaultTimeout,
+		stage:   defaultStage,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(o.routes) == 0 {
+		o.routes = []route{newRoute("ANY", "/{proxy+}")}
+	}
+	return o
+}
+
+// route is an API Gateway resource path template and the method it is served for.
+type route struct {
+	method   string
+	template string
+	segments []string
+}
+
+func newRoute(method, template string) route {
+	return route{
+		method:   strings.ToUpper(method),
+		template: template,
+		segments: splitPath(template),
+	}
+}
+
+// match returns the path parameters of path if it matches the route.
+func (r route) match(method, path string) (map[string]string, bool) {
+	if r.method != "ANY" && r.method != method {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	segments := splitPath(path)
+	for i, tmpl := range r.segments {
+		name, isParam := strings.CutPrefix(tmpl, "{")
+		name, _ = strings.CutSuffix(name, "}")
+		if greedy, isGreedy := strings.CutSuffix(name, "+"); isParam && isGreedy {
+			// A greedy parameter in the root position, like the $default route, also matches "/"
+			if i >= len(segments) && i > 0 {
+				return nil, false
+			}
+			params[greedy] = strings.Join(segments[i:], "/")
+			return params, true
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+		switch {
+		case isParam:
+			params[name] = segments[i]
+		case tmpl != segments[i]:
+			return nil, false
+		}
+	}
+	if len(segments) != len(r.segments) {
+		return nil, false
+	}
+	return params, true
+}
+
+func (o options) matchRoute(method, path string) (route, map[string]string, bool) {
+	for _, r := range o.routes {
+		if params, ok := r.match(method, path); ok {
+			return r, params, true
+		}
+	}
+	return route{}, nil, false
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// readBody reads the request body, base64 encoding it if it is binary.
+func (o options) readBody(r *http.Request) (string, bool, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil || len(body) == 0 {
+		return "", false, err
+	}
+	if !utf8.Valid(body) || o.isBinary(r.Header.Get("Content-Type")) {
+		return base64.StdEncoding.EncodeToString(body), true, nil
+	}
+	return string(body), false, nil
+}
+
+func (o options) isBinary(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, binary := range o.binaryMediaTypes {
+		if binary == mediaType || binary == "*/*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(binary, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// invocationContext returns the context of an invocation, carrying a Lambda context and deadline like the Lambda
+// runtime's.
+func (o options) invocationContext(ctx context.Context, requestID string) (context.Context, context.CancelFunc) {
+	ctx = lambdacontext.NewContext(ctx, &lambdacontext.LambdaContext{
+		AwsRequestID:       requestID,
+		InvokedFunctionArn: "arn:aws:lambda:local:" + defaultAccountID + ":function:" + lambdacontext.FunctionName,
+	})
+	return context.WithTimeout(ctx, o.timeout)
+}
+
+// writeResponse writes an API Gateway response to w. Headers and multi-value headers are merged, as API Gateway does.
+func writeResponse(w http.ResponseWriter, status int, headers map[string]string, multiValueHeaders map[string][]string, cookies []string, body string, isBase64Encoded bool) {
+	for k, values := range multiValueHeaders {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+	for k, v := range headers {
+		w.Header().Add(k, v)
+	}
+	for _, c := range cookies {
+		w.Header().Add("Set-Cookie", c)
+	}
+
+	data := []byte(body)
+	if isBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			http.Error(w, "invalid base64 response body", http.StatusBadGateway)
+			return
+		}
+		data = decoded
+	}
+
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = io.WriteString(w, `{"message":"`+message+`"}`)
+}
+
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,236 @@
+package local
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/middleware"
+)
+
+type handlerFunc[E, R any] func(ctx context.Context, event E) (R, error)
+
+func (f handlerFunc[E, R]) Handle(ctx context.Context, event E) (R, error) {
+	return f(ctx, event)
+}
+
+func Test_route_match(t *testing.T) {
+	type testCase struct {
+		name       string
+		method     string
+		template   string
+		reqMethod  string
+		path       string
+		wantParams map[string]string
+		wantOk     bool
+	}
+	tests := []testCase{
+		{name: "static match", method: "GET", template: "/health", reqMethod: "GET", path: "/health", wantParams: map[string]string{}, wantOk: true},
+		{name: "method mismatch", method: "GET", template: "/health", reqMethod: "POST", path: "/health", wantOk: false},
+		{name: "any method", method: "ANY", template: "/health", reqMethod: "DELETE", path: "/health", wantParams: map[string]string{}, wantOk: true},
+		{name: "path parameter", method: "GET", template: "/customers/{id}", reqMethod: "GET", path: "/customers/123", wantParams: map[string]string{"id": "123"}, wantOk: true},
+		{name: "too many segments", method: "GET", template: "/customers/{id}", reqMethod: "GET", path: "/customers/123/orders", wantOk: false},
+		{name: "too few segments", method: "GET", template: "/customers/{id}", reqMethod: "GET", path: "/customers", wantOk: false},
+		{name: "greedy parameter", method: "GET", template: "/files/{proxy+}", reqMethod: "GET", path: "/files/a/b/c.txt", wantParams: map[string]string{"proxy": "a/b/c.txt"}, wantOk: true},
+		{name: "greedy parameter requires a segment", method: "GET", template: "/files/{proxy+}", reqMethod: "GET", path: "/files", wantOk: false},
+		{name: "root greedy parameter matches root", method: "ANY", template: "/{proxy+}", reqMethod: "GET", path: "/", wantParams: map[string]string{"proxy": ""}, wantOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotParams, gotOk := newRoute(tt.method, tt.template).match(tt.reqMethod, tt.path)
+			assert.Equal(t, tt.wantOk, gotOk)
+			if tt.wantOk {
+				assert.Equal(t, tt.wantParams, gotParams)
+			}
+		})
+	}
+}
+
+func TestNewHandler(t *testing.T) {
+	var gotEvent events.APIGatewayProxyRequest
+	var gotLambdaCtx *lambdacontext.LambdaContext
+	handler := handlerFunc[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](
+		func(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			gotEvent = event
+			gotLambdaCtx, _ = lambdacontext.FromContext(ctx)
+			if event.PathParameters["id"] == "error" {
+				return events.APIGatewayProxyResponse{}, errors.New("error")
+			}
+			return events.APIGatewayProxyResponse{
+				StatusCode:        http.StatusCreated,
+				Headers:           map[string]string{"Content-Type": "application/octet-stream"},
+				MultiValueHeaders: map[string][]string{"X-Multi": {"a", "b"}},
+				Body:              base64.StdEncoding.EncodeToString([]byte{0xff, 0x00}),
+				IsBase64Encoded:   true,
+			}, nil
+		})
+
+	srv := httptest.NewServer(NewHandler(handler,
+		[]middleware.WithResponse[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse]{
+			middleware.NewContextWithResponse[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](),
+		},
+		WithRoute(http.MethodPost, "/customers/{id}"),
+		WithBinaryMediaTypes("image/*"),
+	))
+	defer srv.Close()
+
+	t.Run("request translated and response written", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL+"/customers/123?tag=a&tag=b", strings.NewReader(`{"name":"jane"}`))
+		require.NoError(t, err)
+		req.Header.Add("X-Custom", "1")
+		req.Header.Add("X-Custom", "2")
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, _ := io.ReadAll(res.Body)
+
+		assert.Equal(t, "/customers/{id}", gotEvent.Resource)
+		assert.Equal(t, "/customers/123", gotEvent.Path)
+		assert.Equal(t, http.MethodPost, gotEvent.HTTPMethod)
+		assert.Equal(t, map[string]string{"id": "123"}, gotEvent.PathParameters)
+		assert.Equal(t, map[string]string{"tag": "b"}, gotEvent.QueryStringParameters)
+		assert.Equal(t, map[string][]string{"tag": {"a", "b"}}, gotEvent.MultiValueQueryStringParameters)
+		assert.Equal(t, "2", gotEvent.Headers["X-Custom"])
+		assert.Equal(t, []string{"1", "2"}, gotEvent.MultiValueHeaders["X-Custom"])
+		assert.Equal(t, `{"name":"jane"}`, gotEvent.Body)
+		assert.False(t, gotEvent.IsBase64Encoded)
+		assert.Equal(t, "local", gotEvent.RequestContext.Stage)
+		assert.Equal(t, "/customers/{id}", gotEvent.RequestContext.ResourcePath)
+		assert.NotEmpty(t, gotEvent.RequestContext.RequestID)
+		assert.Equal(t, "127.0.0.1", gotEvent.RequestContext.Identity.SourceIP)
+		require.NotNil(t, gotLambdaCtx)
+
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		assert.Equal(t, []byte{0xff, 0x00}, body)
+		assert.Equal(t, []string{"a", "b"}, res.Header.Values("X-Multi"))
+		assert.Equal(t, gotEvent.RequestContext.RequestID, res.Header.Get("X-Request-Id"), "context middleware applied")
+	})
+
+	t.Run("binary request body base64 encoded", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL+"/customers/123", strings.NewReader("png"))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "image/png")
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+
+		assert.True(t, gotEvent.IsBase64Encoded)
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("png")), gotEvent.Body)
+	})
+
+	t.Run("unmatched route, 404", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+"/customers/123", nil)
+		require.NoError(t, err)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("handler error, 502", func(t *testing.T) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL+"/customers/error", nil)
+		require.NoError(t, err)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+
+		assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+	})
+}
+
+func TestNewHandlerV2(t *testing.T) {
+	var gotEvent events.APIGatewayV2HTTPRequest
+	handler := handlerFunc[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse](
+		func(_ context.Context, event events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+			gotEvent = event
+			return events.APIGatewayV2HTTPResponse{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"Content-Type": "text/plain"},
+				Cookies:    []string{"session=abc; Path=/"},
+				Body:       "ok",
+			}, nil
+		})
+
+	srv := httptest.NewServer(NewHandlerV2(handler, nil))
+	defer srv.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+"/files/a/b?tag=a&tag=b", nil)
+	require.NoError(t, err)
+	req.Header.Add("X-Custom", "1")
+	req.Header.Add("X-Custom", "2")
+	req.AddCookie(&http.Cookie{Name: "theme", Value: "dark"})
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+
+	assert.Equal(t, "2.0", gotEvent.Version)
+	assert.Equal(t, "$default", gotEvent.RouteKey)
+	assert.Equal(t, "/files/a/b", gotEvent.RawPath)
+	assert.Equal(t, "tag=a&tag=b", gotEvent.RawQueryString)
+	assert.Equal(t, map[string]string{"tag": "a,b"}, gotEvent.QueryStringParameters)
+	assert.Equal(t, map[string]string{"proxy": "files/a/b"}, gotEvent.PathParameters)
+	assert.Equal(t, "1,2", gotEvent.Headers["x-custom"])
+	assert.NotContains(t, gotEvent.Headers, "cookie")
+	assert.Equal(t, []string{"theme=dark"}, gotEvent.Cookies)
+	assert.Equal(t, http.MethodGet, gotEvent.RequestContext.HTTP.Method)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, "session=abc; Path=/", res.Header.Get("Set-Cookie"))
+}
+
+func TestNewHandlerV2_rootAndEscapedPath(t *testing.T) {
+	var gotEvents []events.APIGatewayV2HTTPRequest
+	handler := handlerFunc[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse](
+		func(_ context.Context, event events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+			gotEvents = append(gotEvents, event)
+			return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusOK}, nil
+		})
+
+	srv := httptest.NewServer(NewHandlerV2(handler, nil))
+	defer srv.Close()
+
+	for _, path := range []string{"/", "/files/a%2Fb%20c"} {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode, path)
+	}
+
+	require.Len(t, gotEvents, 2)
+	assert.Equal(t, "/", gotEvents[0].RawPath)
+	assert.Equal(t, "$default", gotEvents[0].RouteKey)
+	assert.Equal(t, "/files/a%2Fb%20c", gotEvents[1].RawPath)
+}
+
+func Test_writeResponse_mergesHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	writeResponse(w, http.StatusCreated,
+		map[string]string{"X-Multi": "c", "Content-Type": "text/plain"},
+		map[string][]string{"X-Multi": {"a", "b"}},
+		nil, "ok", false)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, []string{"a", "b", "c"}, w.Header().Values("X-Multi"))
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+}
@@ -0,0 +1,119 @@
+package local
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ellogroup/ello-golang-aws/v2/internal/requestid"
+	"github.com/ellogroup/ello-golang-aws/v2/lambda"
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/middleware"
+)
+
+type handlerV1 struct {
+	handlerFn func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error)
+	opts      options
+}
+
+// NewHandler returns an http.Handler serving an API Gateway v1 (REST API) handler, wrapped in middlewares exactly as
+// lambda.StartWithResponse would wrap it.
+//
+// Each request is translated into an events.APIGatewayProxyRequest with headers, multi-value headers, query string
+// parameters, path parameters (from the route matched, see WithRoute), the body (base64 encoded if binary) and a
+// synthetic request context. The response is written back with its status code, headers and (base64 decoded) body.
+// A handler error is written as a 502, like API Gateway's "Internal server error".
+func NewHandler(handler lambda.HandlerWithResponse[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse], middlewares []middleware.WithResponse[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse], opts ...Option) http.Handler {
+	return &handlerV1{
+		handlerFn: lambda.HandlerWithResponseFn(handler, middlewares),
+		opts:      newOptions(opts),
+	}
+}
+
+func (h *handlerV1) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt, params, ok := h.opts.matchRoute(r.Method, r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	event, err := h.opts.newRequestV1(r, rt, params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	ctx, cancel := h.opts.invocationContext(r.Context(), event.RequestContext.RequestID)
+	defer cancel()
+
+	res, err := h.handlerFn(ctx, event)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "Internal server error")
+		return
+	}
+	writeResponse(w, res.StatusCode, res.Headers, res.MultiValueHeaders, nil, res.Body, res.IsBase64Encoded)
+}
+
+func (o options) newRequestV1(r *http.Request, rt route, params map[string]string) (events.APIGatewayProxyRequest, error) {
+	body, isBase64Encoded, err := o.readBody(r)
+	if err != nil {
+		return events.APIGatewayProxyRequest{}, err
+	}
+
+	headers := make(map[string]string, len(r.Header))
+	multiValueHeaders := make(map[string][]string, len(r.Header))
+	for k, v := range r.Header {
+		headers[k] = v[len(v)-1]
+		multiValueHeaders[k] = v
+	}
+	if r.Host != "" {
+		headers["Host"] = r.Host
+		multiValueHeaders["Host"] = []string{r.Host}
+	}
+
+	var query map[string]string
+	var multiValueQuery map[string][]string
+	if q := r.URL.Query(); len(q) > 0 {
+		query = make(map[string]string, len(q))
+		multiValueQuery = make(map[string][]string, len(q))
+		for k, v := range q {
+			query[k] = v[len(v)-1]
+			multiValueQuery[k] = v
+		}
+	}
+
+	var pathParams map[string]string
+	if len(params) > 0 {
+		pathParams = params
+	}
+
+	now := time.Now()
+	return events.APIGatewayProxyRequest{
+		Resource:                        rt.template,
+		Path:                            r.URL.Path,
+		HTTPMethod:                      r.Method,
+		Headers:                         headers,
+		MultiValueHeaders:               multiValueHeaders,
+		QueryStringParameters:           query,
+		MultiValueQueryStringParameters: multiValueQuery,
+		PathParameters:                  pathParams,
+		RequestContext: events.APIGatewayProxyRequestContext{
+			AccountID:        defaultAccountID,
+			ResourceID:       rt.template,
+			Stage:            o.stage,
+			DomainName:       r.Host,
+			RequestID:        requestid.New(),
+			Protocol:         r.Proto,
+			Identity:         events.APIGatewayRequestIdentity{SourceIP: sourceIP(r), UserAgent: r.UserAgent()},
+			ResourcePath:     rt.template,
+			Path:             "/" + o.stage + r.URL.Path,
+			HTTPMethod:       r.Method,
+			RequestTime:      now.UTC().Format("02/Jan/2006:15:04:05 -0700"),
+			RequestTimeEpoch: now.UnixMilli(),
+			APIID:            defaultAPIID,
+		},
+		Body:            body,
+		IsBase64Encoded: isBase64Encoded,
+	}, nil
+}
@@ -0,0 +1,130 @@
+package local
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ellogroup/ello-golang-aws/v2/internal/requestid"
+	"github.com/ellogroup/ello-golang-aws/v2/lambda"
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/middleware"
+)
+
+type handlerV2 struct {
+	handlerFn func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error)
+	opts      options
+}
+
+// NewHandlerV2 returns an http.Handler serving an API Gateway v2 (HTTP API, payload format 2.0) handler, wrapped in
+// middlewares exactly as lambda.StartWithResponse would wrap it.
+//
+// Each request is translated into an events.APIGatewayV2HTTPRequest with the route key, raw path and query string,
+// lower-cased headers and query string parameters (multiple values comma separated, as API Gateway does), cookies,
+// path parameters (from the route matched, see WithRoute), the body (base64 encoded if binary) and a synthetic request
+// context. The response is written back with its status code, headers, cookies and (base64 decoded) body. A handler
+// error is written as a 500, like API Gateway's "Internal Server Error".
+func NewHandlerV2(handler lambda.HandlerWithResponse[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse], middlewares []middleware.WithResponse[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse], opts ...Option) http.Handler {
+	return &handlerV2{
+		handlerFn: lambda.HandlerWithResponseFn(handler, middlewares),
+		opts:      newOptions(opts),
+	}
+}
+
+func (h *handlerV2) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt, params, ok := h.opts.matchRoute(r.Method, r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	event, err := h.opts.newRequestV2(r, rt, params)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	ctx, cancel := h.opts.invocationContext(r.Context(), event.RequestContext.RequestID)
+	defer cancel()
+
+	res, err := h.handlerFn(ctx, event)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	writeResponse(w, res.StatusCode, res.Headers, res.MultiValueHeaders, res.Cookies, res.Body, res.IsBase64Encoded)
+}
+
+func (o options) newRequestV2(r *http.Request, rt route, params map[string]string) (events.APIGatewayV2HTTPRequest, error) {
+	body, isBase64Encoded, err := o.readBody(r)
+	if err != nil {
+		return events.APIGatewayV2HTTPRequest{}, err
+	}
+
+	headers := make(map[string]string, len(r.Header))
+	for k, v := range r.Header {
+		if strings.EqualFold(k, "Cookie") {
+			continue
+		}
+		headers[strings.ToLower(k)] = strings.Join(v, ",")
+	}
+	if r.Host != "" {
+		headers["host"] = r.Host
+	}
+
+	var cookies []string
+	for _, c := range r.Cookies() {
+		cookies = append(cookies, c.String())
+	}
+
+	var query map[string]string
+	if q := r.URL.Query(); len(q) > 0 {
+		query = make(map[string]string, len(q))
+		for k, v := range q {
+			query[k] = strings.Join(v, ",")
+		}
+	}
+
+	var pathParams map[string]string
+	if len(params) > 0 {
+		pathParams = params
+	}
+
+	routeKey := rt.method + " " + rt.template
+	if rt.method == "ANY" && rt.template == "/{proxy+}" {
+		routeKey = "$default"
+	}
+
+	now := time.Now()
+	return events.APIGatewayV2HTTPRequest{
+		Version:               "2.0",
+		RouteKey:              routeKey,
+		RawPath:               r.URL.EscapedPath(),
+		RawQueryString:        r.URL.RawQuery,
+		Cookies:               cookies,
+		Headers:               headers,
+		QueryStringParameters: query,
+		PathParameters:        pathParams,
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			RouteKey:   routeKey,
+			AccountID:  defaultAccountID,
+			Stage:      o.stage,
+			RequestID:  requestid.New(),
+			APIID:      defaultAPIID,
+			DomainName: r.Host,
+			Time:       now.UTC().Format("02/Jan/2006:15:04:05 -0700"),
+			TimeEpoch:  now.UnixMilli(),
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				Protocol:  r.Proto,
+				SourceIP:  sourceIP(r),
+				UserAgent: r.UserAgent(),
+			},
+		},
+		Body:            body,
+		IsBase64Encoded: isBase64Encoded,
+	}, nil
+}
@@ -0,0 +1,154 @@
+// Package ratelimit limits the rate of requests per key with token buckets, kept in a Store.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+)
+
+const defaultMemoryStoreSize = 10000
+
+// Limit allows Requests requests per Period, in bursts of up to Burst requests. Tokens are added to the bucket of a key
+// at a steady rate of Requests per Period, up to Burst, or Requests if Burst is 0.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// PerSecond returns a Limit of requests per second.
+func PerSecond(requests int) Limit {
+	return Limit{Requests: requests, Period: time.Second}
+}
+
+// PerMinute returns a Limit of requests per minute.
+func PerMinute(requests int) Limit {
+	return Limit{Requests: requests, Period: time.Minute}
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// interval returns the time it takes to add a token to a bucket.
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(max(l.Requests, 1))
+}
+
+// Result is the outcome of taking a token from the bucket of a key.
+type Result struct {
+	// Allowed is whether a token was taken, i.e. the request is within the limit.
+	Allowed bool
+	// Limit is the capacity of the bucket.
+	Limit int
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until a token can be taken, when the request was not allowed.
+	RetryAfter time.Duration
+}
+
+// Store interface should be implemented for stores of the token buckets of keys. MemoryStore keeps them in the memory
+// of each Lambda container. Implement Store over a shared store, such as DynamoDB or Redis, for a limit across
+// containers.
+type Store interface {
+	// Take takes a token from the bucket of key, created full if it does not exist, according to limit.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+// MemoryStore is a Store keeping token buckets in memory. It lives as long as the Lambda container, so each container
+// has its own buckets and limits. When the store is full, buckets that have refilled are removed first, and then
+// arbitrary buckets.
+type MemoryStore struct {
+	clock   clock.Clock
+	size    int
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// MemoryStoreOption configures NewMemoryStore.
+type MemoryStoreOption func(*MemoryStore)
+
+// WithMemoryStoreSize sets the maximum number of buckets kept by the store. Defaults to 10000.
+func WithMemoryStoreSize(size int) MemoryStoreOption {
+	return func(s *MemoryStore) {
+		s.size = size
+	}
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore(opts ...MemoryStoreOption) *MemoryStore {
+	s := &MemoryStore{
+		clock:   clock.NewSystem(),
+		size:    defaultMemoryStoreSize,
+		buckets: map[string]*bucket{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Take takes a token from the bucket of key. It never returns an error.
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	now := s.clock.Now()
+	capacity, interval := limit.capacity(), limit.interval()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		s.evict(now)
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+	if interval > 0 {
+		b.tokens = math.Min(capacity, b.tokens+float64(now.Sub(b.updated))/float64(interval))
+	}
+	b.updated = now
+
+	result := Result{Limit: int(capacity)}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) * float64(interval))
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = time.Duration((capacity - b.tokens) * float64(interval))
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
+
+// evict makes room for a bucket when the store is full.
+func (s *MemoryStore) evict(now time.Time) {
+	if len(s.buckets) < s.size {
+		return
+	}
+	for k, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, k)
+		}
+	}
+	for k := range s.buckets {
+		if len(s.buckets) < s.size {
+			break
+		}
+		delete(s.buckets, k)
+	}
+}
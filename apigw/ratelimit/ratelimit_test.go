@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.clock = clock.NewFixed(now)
+	limit := PerMinute(3)
+	ctx := context.Background()
+
+	take := func(key string) Result {
+		t.Helper()
+		result, err := store.Take(ctx, key, limit)
+		require.NoError(t, err)
+		return result
+	}
+
+	assert.Equal(t, Result{Allowed: true, Limit: 3, Remaining: 2, Reset: 20 * time.Second}, take("client-1"))
+	assert.Equal(t, Result{Allowed: true, Limit: 3, Remaining: 1, Reset: 40 * time.Second}, take("client-1"))
+	assert.Equal(t, Result{Allowed: true, Limit: 3, Remaining: 0, Reset: time.Minute}, take("client-1"))
+	assert.Equal(t, Result{Allowed: false, Limit: 3, Remaining: 0, Reset: time.Minute, RetryAfter: 20 * time.Second}, take("client-1"))
+	assert.True(t, take("client-2").Allowed, "buckets per key")
+
+	store.clock = clock.NewFixed(now.Add(10 * time.Second))
+	assert.Equal(t, Result{Allowed: false, Limit: 3, Remaining: 0, Reset: 50 * time.Second, RetryAfter: 10 * time.Second}, take("client-1"))
+
+	store.clock = clock.NewFixed(now.Add(20 * time.Second))
+	assert.Equal(t, Result{Allowed: true, Limit: 3, Remaining: 0, Reset: time.Minute}, take("client-1"), "token added")
+
+	store.clock = clock.NewFixed(now.Add(time.Hour))
+	assert.Equal(t, Result{Allowed: true, Limit: 3, Remaining: 2, Reset: 20 * time.Second}, take("client-1"), "bucket refilled up to its capacity")
+}
+
+func TestMemoryStore_Take_burst(t *testing.T) {
+	store := NewMemoryStore()
+	store.clock = clock.NewFixed(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+	limit := Limit{Requests: 1, Period: time.Second, Burst: 5}
+
+	for i := range 5 {
+		result, err := store.Take(context.Background(), "client", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed, "request %d within burst", i)
+		assert.Equal(t, 5, result.Limit)
+	}
+	result, err := store.Take(context.Background(), "client", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+}
+
+func TestMemoryStore_evict(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(WithMemoryStoreSize(2))
+	store.clock = clock.NewFixed(now)
+	ctx := context.Background()
+
+	_, _ = store.Take(ctx, "client-1", PerSecond(1))
+	_, _ = store.Take(ctx, "client-2", PerMinute(1))
+	store.clock = clock.NewFixed(now.Add(time.Second))
+	_, _ = store.Take(ctx, "client-3", PerMinute(1))
+
+	assert.Len(t, store.buckets, 2)
+	assert.NotContains(t, store.buckets, "client-1", "refilled bucket evicted")
+
+	for i := range 5 {
+		_, _ = store.Take(ctx, fmt.Sprintf("client-%d", i+4), PerMinute(1))
+	}
+	assert.Len(t, store.buckets, 2, "arbitrary buckets evicted when none refilled")
+}
@@ -2,9 +2,13 @@ package response
 
 import (
 	"fmt"
+	"maps"
+	"math"
 	"net/http"
 	"reflect"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 )
@@ -15,20 +19,29 @@ import (
 type ErrorCode string
 
 // Generic error codes built into this package - see NewErrorCode. Applications can register their
-// own additional codes with RegisterErrorCode/MustRegisterErrorCode.
+// own additional codes with RegisterErrorCode/MustRegisterErrorCode, but not these: registering one
+// with a different definition fails. ErrorCodeForbidden, ErrorCodePayloadTooLarge,
+// ErrorCodeUnsupportedMedia and ErrorCodeTimeout were added after the others, so applications that
+// registered codes of the same names must now use the built-in ones or rename theirs.
 const (
 	ErrorCodeValidationFailed ErrorCode = "validation_failed"
 	ErrorCodeUnauthorized     ErrorCode = "unauthorized"
+	ErrorCodeForbidden        ErrorCode = "forbidden"
 	ErrorCodeRateLimited      ErrorCode = "rate_limited"
+	ErrorCodePayloadTooLarge  ErrorCode = "payload_too_large"
+	ErrorCodeUnsupportedMedia ErrorCode = "unsupported_media_type"
 	ErrorCodeInternalError    ErrorCode = "internal_error"
+	ErrorCodeTimeout          ErrorCode = "timeout"
 )
 
-// ErrorCodeDefinition bundles the HTTP status, message, and default field-level details NewErrorCode
-// uses to build a response for a given ErrorCode - see RegisterErrorCode.
+// ErrorCodeDefinition bundles the HTTP status, message, default field-level details, and any
+// additional response headers NewErrorCode uses to build a response for a given ErrorCode - see
+// RegisterErrorCode.
 type ErrorCodeDefinition struct {
 	Status  int
 	Message string
 	Fields  []ErrorField
+	Headers map[string]string
 }
 
 var (
@@ -42,14 +55,30 @@ var (
 			Status:  http.StatusUnauthorized,
 			Message: "Missing or invalid bearer token.",
 		},
+		ErrorCodeForbidden: {
+			Status:  http.StatusForbidden,
+			Message: "The bearer token does not grant access to this resource.",
+		},
 		ErrorCodeRateLimited: {
 			Status:  http.StatusTooManyRequests,
 			Message: "Too many requests. Retry after the period in the Retry-After header.",
 		},
+		ErrorCodePayloadTooLarge: {
+			Status:  http.StatusRequestEntityTooLarge,
+			Message: "The request body is too large.",
+		},
+		ErrorCodeUnsupportedMedia: {
+			Status:  http.StatusUnsupportedMediaType,
+			Message: "The Content-Type of the request body is not supported.",
+		},
 		ErrorCodeInternalError: {
 			Status:  http.StatusInternalServerError,
 			Message: "An unexpected error occurred. Please retry.",
 		},
+		ErrorCodeTimeout: {
+			Status:  http.StatusGatewayTimeout,
+			Message: "The request took too long to process. Please retry.",
+		},
 	}
 )
 
@@ -72,7 +101,8 @@ func RegisterErrorCode(code ErrorCode, def ErrorCodeDefinition) error {
 	defer errorCodeRegistryMu.Unlock()
 
 	if existing, ok := errorCodeRegistry[code]; ok {
-		if fieldsEqual(existing.Fields, def.Fields) && existing.Status == def.Status && existing.Message == def.Message {
+		if fieldsEqual(existing.Fields, def.Fields) && maps.Equal(existing.Headers, def.Headers) &&
+			existing.Status == def.Status && existing.Message == def.Message {
 			return nil
 		}
 		return fmt.Errorf("response: ErrorCode %q is already registered with a different definition", code)
@@ -122,6 +152,19 @@ func WithErrorFields(fields ...ErrorField) ErrorCodeOption {
 	return func(d *ErrorCodeDefinition) { d.Fields = fields }
 }
 
+// WithRetryAfter sets the Retry-After header of the response NewErrorCode builds to d, rounded up to
+// whole seconds - the period ErrorCodeRateLimited's message tells clients to wait for.
+func WithRetryAfter(after time.Duration) ErrorCodeOption {
+	return func(d *ErrorCodeDefinition) {
+		headers := maps.Clone(d.Headers)
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		headers["Retry-After"] = strconv.FormatInt(int64(math.Ceil(max(after, 0).Seconds())), 10)
+		d.Headers = headers
+	}
+}
+
 // NewErrorCode creates a new error response for API Gateway using code's registered HTTP status
 // and message (see RegisterErrorCode), so every caller reporting the same error produces the same
 // response. Use the With* options to override any of them.
@@ -138,7 +181,11 @@ func NewErrorCode(code ErrorCode, opts ...ErrorCodeOption) events.APIGatewayProx
 	for _, opt := range opts {
 		opt(&def)
 	}
-	return NewError(def.Status, code, def.Message, def.Fields...)
+	res := NewError(def.Status, code, def.Message, def.Fields...)
+	for k, v := range def.Headers {
+		res.Headers[k] = v
+	}
+	return res
 }
 
 // Field-level codes used across our APIs. Use these instead of inline string literals so every
@@ -147,6 +194,7 @@ const (
 	FieldErrorCodeRequired      = "required"
 	FieldErrorCodeNotUnique     = "not_unique"
 	FieldErrorCodeInvalidFormat = "invalid_format"
+	FieldErrorCodeTooDeep       = "too_deep"
 )
 
 // errorCodeConstraint is satisfied by any string or integer type, named or not, so a caller can
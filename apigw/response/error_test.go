@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/stretchr/testify/assert"
@@ -145,6 +146,28 @@ func TestNewErrorCode(t *testing.T) {
 		assert.Equal(t, want, got)
 	})
 
+	t.Run("WithRetryAfter sets the Retry-After header in whole seconds", func(t *testing.T) {
+		got := NewErrorCode(ErrorCodeRateLimited, WithRetryAfter(1500*time.Millisecond))
+		want := events.APIGatewayProxyResponse{
+			StatusCode: 429,
+			Body:       `{"code":"rate_limited","message":"Too many requests. Retry after the period in the Retry-After header."}`,
+			Headers:    map[string]string{"Content-Type": "application/json", "Retry-After": "2"},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("registered headers are added to the response", func(t *testing.T) {
+		code := ErrorCode("test_maintenance")
+		MustRegisterErrorCode(code, ErrorCodeDefinition{
+			Status:  503,
+			Message: "Down for maintenance.",
+			Headers: map[string]string{"Retry-After": "3600"},
+		})
+		assert.Equal(t, map[string]string{"Content-Type": "application/json", "Retry-After": "3600"}, NewErrorCode(code).Headers)
+		assert.Equal(t, "60", NewErrorCode(code, WithRetryAfter(time.Minute)).Headers["Retry-After"])
+		assert.Equal(t, "3600", NewErrorCode(code).Headers["Retry-After"], "registered headers must not be mutated by options")
+	})
+
 	t.Run("options apply independently of each other", func(t *testing.T) {
 		got1 := NewErrorCode(ErrorCodeValidationFailed)
 		got2 := NewErrorCode(ErrorCodeValidationFailed, WithErrorMessage("custom message"))
@@ -162,8 +185,12 @@ func TestBuiltinErrorCodeDefinitions_registerEveryErrorCode(t *testing.T) {
 	builtins := []ErrorCode{
 		ErrorCodeValidationFailed,
 		ErrorCodeUnauthorized,
+		ErrorCodeForbidden,
 		ErrorCodeRateLimited,
+		ErrorCodePayloadTooLarge,
+		ErrorCodeUnsupportedMedia,
 		ErrorCodeInternalError,
+		ErrorCodeTimeout,
 	}
 
 	errorCodeRegistryMu.RLock()
@@ -195,6 +222,14 @@ func TestNewErrorCode_builtins(t *testing.T) {
 				Headers:    map[string]string{"Content-Type": "application/json"},
 			},
 		},
+		{
+			code: ErrorCodeForbidden,
+			want: events.APIGatewayProxyResponse{
+				StatusCode: 403,
+				Body:       `{"code":"forbidden","message":"The bearer token does not grant access to this resource."}`,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+			},
+		},
 		{
 			code: ErrorCodeRateLimited,
 			want: events.APIGatewayProxyResponse{
@@ -203,6 +238,22 @@ func TestNewErrorCode_builtins(t *testing.T) {
 				Headers:    map[string]string{"Content-Type": "application/json"},
 			},
 		},
+		{
+			code: ErrorCodePayloadTooLarge,
+			want: events.APIGatewayProxyResponse{
+				StatusCode: 413,
+				Body:       `{"code":"payload_too_large","message":"The request body is too large."}`,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+			},
+		},
+		{
+			code: ErrorCodeUnsupportedMedia,
+			want: events.APIGatewayProxyResponse{
+				StatusCode: 415,
+				Body:       `{"code":"unsupported_media_type","message":"The Content-Type of the request body is not supported."}`,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+			},
+		},
 		{
 			code: ErrorCodeInternalError,
 			want: events.APIGatewayProxyResponse{
@@ -211,6 +262,14 @@ func TestNewErrorCode_builtins(t *testing.T) {
 				Headers:    map[string]string{"Content-Type": "application/json"},
 			},
 		},
+		{
+			code: ErrorCodeTimeout,
+			want: events.APIGatewayProxyResponse{
+				StatusCode: 504,
+				Body:       `{"code":"timeout","message":"The request took too long to process. Please retry."}`,
+				Headers:    map[string]string{"Content-Type": "application/json"},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(string(tt.code), func(t *testing.T) {
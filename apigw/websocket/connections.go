@@ -0,0 +1,48 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrGone should be returned by a ConnectionManager for a connection that is no longer connected, i.e. when the
+// management API responds with a GoneException.
+var ErrGone = errors.New("connection gone")
+
+// ConnectionManager interface should be implemented for clients of the API Gateway management API of a WebSocket API,
+// usually by adapting an apigatewaymanagementapi.Client configured with the Endpoint of a Request. Fake it in tests of
+// handlers that send messages.
+type ConnectionManager interface {
+	// PostToConnection sends data to the client of connectionID.
+	PostToConnection(ctx context.Context, connectionID string, data []byte) error
+	// DeleteConnection disconnects the client of connectionID.
+	DeleteConnection(ctx context.Context, connectionID string) error
+}
+
+// PostJSON sends v as JSON to the client of connectionID with manager.
+func PostJSON(ctx context.Context, manager ConnectionManager, connectionID string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding message: %w", err)
+	}
+	return manager.PostToConnection(ctx, connectionID, data)
+}
+
+// Broadcast sends data to the clients of each of connectionIDs with manager, returning the ids of connections that
+// are gone (see ErrGone), to be forgotten by the caller, and the errors of any other failed sends.
+func Broadcast(ctx context.Context, manager ConnectionManager, connectionIDs []string, data []byte) ([]string, error) {
+	var gone []string
+	var errs []error
+	for _, id := range connectionIDs {
+		err := manager.PostToConnection(ctx, id, data)
+		switch {
+		case errors.Is(err, ErrGone):
+			gone = append(gone, id)
+		case err != nil:
+			errs = append(errs, fmt.Errorf("connection %s: %w", id, err))
+		}
+	}
+	return gone, errors.Join(errs...)
+}
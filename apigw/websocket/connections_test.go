@@ -0,0 +1,60 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConnections struct {
+	posted  map[string][]string
+	deleted []string
+	errs    map[string]error
+}
+
+func (f *fakeConnections) PostToConnection(_ context.Context, connectionID string, data []byte) error {
+	if err := f.errs[connectionID]; err != nil {
+		return err
+	}
+	if f.posted == nil {
+		f.posted = map[string][]string{}
+	}
+	f.posted[connectionID] = append(f.posted[connectionID], string(data))
+	return nil
+}
+
+func (f *fakeConnections) DeleteConnection(_ context.Context, connectionID string) error {
+	f.deleted = append(f.deleted, connectionID)
+	return nil
+}
+
+func TestPostJSON(t *testing.T) {
+	connections := &fakeConnections{}
+
+	err := PostJSON(context.Background(), connections, "conn-1", map[string]string{"message": "hello"})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"conn-1": {`{"message":"hello"}`}}, connections.posted)
+}
+
+func TestPostJSON_encodingError(t *testing.T) {
+	err := PostJSON(context.Background(), &fakeConnections{}, "conn-1", make(chan int))
+
+	assert.ErrorContains(t, err, "encoding message")
+}
+
+func TestBroadcast(t *testing.T) {
+	connections := &fakeConnections{errs: map[string]error{
+		"conn-2": ErrGone,
+		"conn-3": errors.New("throttled"),
+	}}
+
+	gone, err := Broadcast(context.Background(), connections, []string{"conn-1", "conn-2", "conn-3", "conn-4"}, []byte("hi"))
+
+	assert.Equal(t, []string{"conn-2"}, gone)
+	assert.EqualError(t, err, "connection conn-3: throttled")
+	assert.Equal(t, map[string][]string{"conn-1": {"hi"}, "conn-4": {"hi"}}, connections.posted)
+}
@@ -0,0 +1,132 @@
+// Package websocket routes API Gateway WebSocket events to handlers by route key, and sends messages to connected
+// clients through a ConnectionManager.
+//
+//	router := websocket.NewRouter()
+//	router.Connect(connectHandler)
+//	router.Disconnect(disconnectHandler)
+//	router.Route("sendMessage", sendMessageHandler)
+//	router.Default(defaultHandler)
+//
+//	lambda.StartWithResponse[events.APIGatewayWebsocketProxyRequest, events.APIGatewayProxyResponse](router,
+//		middleware.CommonWebSocket(logger))
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Route keys of the predefined routes of a WebSocket API.
+const (
+	RouteConnect    = "$connect"
+	RouteDisconnect = "$disconnect"
+	RouteDefault    = "$default"
+)
+
+// ErrNoRoute is returned by a Router for an event without a registered handler when there is no default route.
+var ErrNoRoute = errors.New("no route for event")
+
+// Request is an API Gateway WebSocket event.
+type Request struct {
+	events.APIGatewayWebsocketProxyRequest
+
+	// ConnectionID is the id of the connection of the client that sent the event.
+	ConnectionID string
+	// RouteKey is the route key of the event, e.g. "$connect" or "sendMessage".
+	RouteKey string
+	// DomainName is the domain name of the API.
+	DomainName string
+	// Stage is the stage of the API.
+	Stage string
+}
+
+// NewRequest returns a Request for event.
+func NewRequest(event events.APIGatewayWebsocketProxyRequest) Request {
+	return Request{
+		APIGatewayWebsocketProxyRequest: event,
+		ConnectionID:                    event.RequestContext.ConnectionID,
+		RouteKey:                        event.RequestContext.RouteKey,
+		DomainName:                      event.RequestContext.DomainName,
+		Stage:                           event.RequestContext.Stage,
+	}
+}
+
+// Endpoint returns the endpoint of the API Gateway management API for the API of the request, to configure the client
+// behind a ConnectionManager.
+func (r Request) Endpoint() string {
+	return "https://" + r.DomainName + "/" + r.Stage
+}
+
+// Handler interface should be implemented for handlers of API Gateway WebSocket events.
+type Handler interface {
+	// Handle handles a request and returns a response.
+	Handle(ctx context.Context, request Request) (events.APIGatewayProxyResponse, error)
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as a Handler.
+type HandlerFunc func(ctx context.Context, request Request) (events.APIGatewayProxyResponse, error)
+
+// Handle calls f(ctx, request).
+func (f HandlerFunc) Handle(ctx context.Context, request Request) (events.APIGatewayProxyResponse, error) {
+	return f(ctx, request)
+}
+
+// Router routes API Gateway WebSocket events to handlers by their route key. It implements
+// lambda.HandlerWithResponse[events.APIGatewayWebsocketProxyRequest, events.APIGatewayProxyResponse].
+//
+// A response with a zero status code is returned as 200 OK, so handlers with nothing to respond can return an empty
+// response. For $connect, any other status code than 2xx rejects the connection. Handlers must be registered before
+// the Router handles events.
+type Router struct {
+	routes map[string]Handler
+}
+
+// NewRouter returns a Router without any registered handlers.
+func NewRouter() *Router {
+	return &Router{routes: map[string]Handler{}}
+}
+
+// Connect registers handler for the $connect route, called when a client connects.
+func (r *Router) Connect(handler Handler) {
+	r.Route(RouteConnect, handler)
+}
+
+// Disconnect registers handler for the $disconnect route, called after a connection is closed. The connection can no
+// longer be sent messages.
+func (r *Router) Disconnect(handler Handler) {
+	r.Route(RouteDisconnect, handler)
+}
+
+// Default registers handler for the $default route, and for events of routes without a registered handler.
+func (r *Router) Default(handler Handler) {
+	r.Route(RouteDefault, handler)
+}
+
+// Route registers handler for events with routeKey. Panics if a handler is already registered for routeKey.
+func (r *Router) Route(routeKey string, handler Handler) {
+	if _, ok := r.routes[routeKey]; ok {
+		panic(fmt.Sprintf("websocket: handler already registered for route %q", routeKey))
+	}
+	r.routes[routeKey] = handler
+}
+
+// Handle routes event to the handler registered for its route key, or otherwise the $default route.
+func (r *Router) Handle(ctx context.Context, event events.APIGatewayWebsocketProxyRequest) (events.APIGatewayProxyResponse, error) {
+	request := NewRequest(event)
+	handler, ok := r.routes[request.RouteKey]
+	if !ok {
+		if handler, ok = r.routes[RouteDefault]; !ok {
+			return events.APIGatewayProxyResponse{}, fmt.Errorf("%w: %q", ErrNoRoute, request.RouteKey)
+		}
+	}
+
+	response, err := handler.Handle(ctx, request)
+	if err == nil && response.StatusCode == 0 {
+		response.StatusCode = http.StatusOK
+	}
+	return response, err
+}
@@ -0,0 +1,154 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func newEvent(routeKey string) events.APIGatewayWebsocketProxyRequest {
+	return events.APIGatewayWebsocketProxyRequest{
+		Body: `{"action":"sendMessage"}`,
+		RequestContext: events.APIGatewayWebsocketProxyRequestContext{
+			ConnectionID: "L0SM9cOFvHcCIhw=",
+			RouteKey:     routeKey,
+			DomainName:   "abc123.execute-api.eu-west-2.amazonaws.com",
+			Stage:        "prod",
+		},
+	}
+}
+
+func respond(status int, body string) Handler {
+	return HandlerFunc(func(context.Context, Request) (events.APIGatewayProxyResponse, error) {
+		return events.APIGatewayProxyResponse{StatusCode: status, Body: body}, nil
+	})
+}
+
+func TestNewRequest(t *testing.T) {
+	event := newEvent("sendMessage")
+
+	got := NewRequest(event)
+
+	assert.Equal(t, Request{
+		APIGatewayWebsocketProxyRequest: event,
+		ConnectionID:                    "L0SM9cOFvHcCIhw=",
+		RouteKey:                        "sendMessage",
+		DomainName:                      "abc123.execute-api.eu-west-2.amazonaws.com",
+		Stage:                           "prod",
+	}, got)
+	assert.Equal(t, "https://abc123.execute-api.eu-west-2.amazonaws.com/prod", got.Endpoint())
+}
+
+func TestRouter_Handle(t *testing.T) {
+	tests := []struct {
+		name    string
+		router  func() *Router
+		event   events.APIGatewayWebsocketProxyRequest
+		want    events.APIGatewayProxyResponse
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "$connect, routed to connect handler",
+			router: func() *Router {
+				r := NewRouter()
+				r.Connect(respond(http.StatusOK, "connect"))
+				r.Default(respond(http.StatusOK, "default"))
+				return r
+			},
+			event:   newEvent(RouteConnect),
+			want:    events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: "connect"},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "$disconnect, routed to disconnect handler",
+			router: func() *Router {
+				r := NewRouter()
+				r.Disconnect(respond(http.StatusOK, "disconnect"))
+				return r
+			},
+			event:   newEvent(RouteDisconnect),
+			want:    events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: "disconnect"},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "custom route, routed to route handler with request details",
+			router: func() *Router {
+				r := NewRouter()
+				r.Route("sendMessage", HandlerFunc(func(_ context.Context, req Request) (events.APIGatewayProxyResponse, error) {
+					return events.APIGatewayProxyResponse{StatusCode: http.StatusAccepted, Body: req.ConnectionID + " " + req.Stage}, nil
+				}))
+				return r
+			},
+			event:   newEvent("sendMessage"),
+			want:    events.APIGatewayProxyResponse{StatusCode: http.StatusAccepted, Body: "L0SM9cOFvHcCIhw= prod"},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "route without handler, routed to default handler",
+			router: func() *Router {
+				r := NewRouter()
+				r.Route("sendMessage", respond(http.StatusOK, "sendMessage"))
+				r.Default(respond(http.StatusOK, "default"))
+				return r
+			},
+			event:   newEvent("leave"),
+			want:    events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: "default"},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "zero status code, 200 returned",
+			router: func() *Router {
+				r := NewRouter()
+				r.Connect(respond(0, ""))
+				return r
+			},
+			event:   newEvent(RouteConnect),
+			want:    events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "handler returns error, error returned",
+			router: func() *Router {
+				r := NewRouter()
+				r.Connect(HandlerFunc(func(context.Context, Request) (events.APIGatewayProxyResponse, error) {
+					return events.APIGatewayProxyResponse{}, errors.New("error")
+				}))
+				return r
+			},
+			event: newEvent(RouteConnect),
+			want:  events.APIGatewayProxyResponse{},
+			wantErr: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.EqualError(t, err, "error")
+			},
+		},
+		{
+			name:   "route without handler or default, ErrNoRoute",
+			router: NewRouter,
+			event:  newEvent("sendMessage"),
+			want:   events.APIGatewayProxyResponse{},
+			wantErr: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.ErrorIs(t, err, ErrNoRoute) && assert.ErrorContains(t, err, `"sendMessage"`)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.router().Handle(context.Background(), tt.event)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRouter_Route_duplicate(t *testing.T) {
+	r := NewRouter()
+	r.Connect(respond(http.StatusOK, ""))
+
+	assert.PanicsWithValue(t, `websocket: handler already registered for route "$connect"`, func() {
+		r.Route(RouteConnect, respond(http.StatusOK, ""))
+	})
+}
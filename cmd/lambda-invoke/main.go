@@ -0,0 +1,25 @@
+// Command lambda-invoke prints sample events, and invokes an echo handler with an event through the common
+// middleware, for trying out event fixtures. Services build their own command registering their handlers with
+// package lambdacli.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/lambdacli"
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/middleware"
+)
+
+type echoHandler struct{}
+
+func (echoHandler) Handle(_ context.Context, event json.RawMessage) (json.RawMessage, error) {
+	return event, nil
+}
+
+func main() {
+	cli := lambdacli.New()
+	lambdacli.RegisterWithResponse(cli, "echo", echoHandler{}, middleware.CommonWithResponse[json.RawMessage, json.RawMessage])
+	os.Exit(cli.Main())
+}
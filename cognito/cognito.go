@@ -0,0 +1,214 @@
+// Package cognito handles Cognito user pool triggers with a handler per trigger, dispatching each event on its trigger
+// source so one function can serve several triggers.
+//
+//	dispatcher := cognito.NewDispatcher()
+//	dispatcher.PreSignUp(preSignUpHandler)
+//	dispatcher.CustomMessage(customMessageHandler)
+//
+//	lambda.StartWithResponse[cognito.Event, cognito.Event](dispatcher, cognito.CommonCognito(logger))
+package cognito
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ellogroup/ello-golang-aws/v2/internal/cognitoredact"
+)
+
+// Triggers, the prefix of the trigger source of each event (e.g. "PreSignUp" of "PreSignUp_AdminCreateUser").
+const (
+	TriggerPreSignUp           = "PreSignUp"
+	TriggerPostConfirmation    = "PostConfirmation"
+	TriggerPreAuthentication   = "PreAuthentication"
+	TriggerPostAuthentication  = "PostAuthentication"
+	TriggerPreTokenGeneration  = "TokenGeneration"
+	TriggerCustomMessage       = "CustomMessage"
+	TriggerDefineAuthChallenge = "DefineAuthChallenge"
+	TriggerCreateAuthChallenge = "CreateAuthChallenge"
+	TriggerVerifyAuthChallenge = "VerifyAuthChallengeResponse"
+	TriggerUserMigration       = "UserMigration"
+)
+
+var (
+	// ErrNoRoute is returned by a Dispatcher for an event of a trigger without a registered handler.
+	ErrNoRoute = errors.New("no handler for trigger")
+	// ErrInvalidEvent is returned by a Dispatcher when an event cannot be decoded into the type of its handler.
+	ErrInvalidEvent = errors.New("invalid event")
+)
+
+// Event is a Cognito user pool trigger event of any trigger, with its common header decoded. It encodes to JSON as the
+// raw event, so a Dispatcher can return the event of the handler as its response.
+type Event struct {
+	events.CognitoEventUserPoolsHeader
+
+	// Raw is the raw JSON of the event.
+	Raw json.RawMessage
+}
+
+// UnmarshalJSON decodes the header of the event and keeps a copy of the raw event.
+func (e *Event) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &e.CognitoEventUserPoolsHeader); err != nil {
+		return err
+	}
+	e.Raw = append(json.RawMessage(nil), b...)
+	return nil
+}
+
+// MarshalJSON returns the raw event.
+func (e Event) MarshalJSON() ([]byte, error) {
+	if e.Raw == nil {
+		return []byte("null"), nil
+	}
+	return e.Raw, nil
+}
+
+// Trigger returns the trigger of the event, the prefix of its trigger source.
+func (e Event) Trigger() string {
+	trigger, _, _ := strings.Cut(e.TriggerSource, "_")
+	return trigger
+}
+
+// Handler [E any] interface should be implemented for handlers of Cognito user pool trigger events of type E, e.g.
+// events.CognitoEventUserPoolsPreSignup. It is a lambda.HandlerWithResponse[E, E], so a handler of a single trigger
+// can also be started on its own.
+type Handler[E any] interface {
+	// Handle handles an event and returns it with its response set.
+	Handle(ctx context.Context, event E) (E, error)
+}
+
+// HandlerFunc [E any] is an adapter to allow the use of ordinary functions as a Handler.
+type HandlerFunc[E any] func(ctx context.Context, event E) (E, error)
+
+// Handle calls f(ctx, event).
+func (f HandlerFunc[E]) Handle(ctx context.Context, event E) (E, error) {
+	return f(ctx, event)
+}
+
+// Dispatcher dispatches Cognito user pool trigger events to the handler registered for their trigger. It implements
+// lambda.HandlerWithResponse[Event, Event]. Handlers must be registered before the Dispatcher handles events.
+type Dispatcher struct {
+	routes     map[string]func(context.Context, Event) (Event, error)
+	tokenGenV2 func(context.Context, Event) (Event, error)
+}
+
+// NewDispatcher returns a Dispatcher without any registered handlers.
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{
+		routes: map[string]func(context.Context, Event) (Event, error){},
+	}
+}
+
+// PreSignUp registers handler for pre sign-up events.
+func (d *Dispatcher) PreSignUp(handler Handler[events.CognitoEventUserPoolsPreSignup]) {
+	d.register(TriggerPreSignUp, route(handler))
+}
+
+// PostConfirmation registers handler for post confirmation events.
+func (d *Dispatcher) PostConfirmation(handler Handler[events.CognitoEventUserPoolsPostConfirmation]) {
+	d.register(TriggerPostConfirmation, route(handler))
+}
+
+// PreAuthentication registers handler for pre authentication events.
+func (d *Dispatcher) PreAuthentication(handler Handler[events.CognitoEventUserPoolsPreAuthentication]) {
+	d.register(TriggerPreAuthentication, route(handler))
+}
+
+// PostAuthentication registers handler for post authentication events.
+func (d *Dispatcher) PostAuthentication(handler Handler[events.CognitoEventUserPoolsPostAuthentication]) {
+	d.register(TriggerPostAuthentication, route(handler))
+}
+
+// PreTokenGeneration registers handler for pre token generation events of version 1, and of later versions when no
+// handler is registered with PreTokenGenerationV2.
+func (d *Dispatcher) PreTokenGeneration(handler Handler[events.CognitoEventUserPoolsPreTokenGen]) {
+	d.register(TriggerPreTokenGeneration, route(handler))
+}
+
+// PreTokenGenerationV2 registers handler for pre token generation events of version 2 and later, which can customize
+// access tokens as well as id tokens.
+func (d *Dispatcher) PreTokenGenerationV2(handler Handler[events.CognitoEventUserPoolsPreTokenGenV2_0]) {
+	if d.tokenGenV2 != nil {
+		panic("cognito: handler already registered for trigger \"TokenGeneration\" version 2")
+	}
+	d.tokenGenV2 = route(handler)
+}
+
+// CustomMessage registers handler for custom message events.
+func (d *Dispatcher) CustomMessage(handler Handler[events.CognitoEventUserPoolsCustomMessage]) {
+	d.register(TriggerCustomMessage, route(handler))
+}
+
+// DefineAuthChallenge registers handler for define auth challenge events.
+func (d *Dispatcher) DefineAuthChallenge(handler Handler[events.CognitoEventUserPoolsDefineAuthChallenge]) {
+	d.register(TriggerDefineAuthChallenge, route(handler))
+}
+
+// CreateAuthChallenge registers handler for create auth challenge events.
+func (d *Dispatcher) CreateAuthChallenge(handler Handler[events.CognitoEventUserPoolsCreateAuthChallenge]) {
+	d.register(TriggerCreateAuthChallenge, route(handler))
+}
+
+// VerifyAuthChallenge registers handler for verify auth challenge response events.
+func (d *Dispatcher) VerifyAuthChallenge(handler Handler[events.CognitoEventUserPoolsVerifyAuthChallenge]) {
+	d.register(TriggerVerifyAuthChallenge, route(handler))
+}
+
+// UserMigration registers handler for user migration events.
+func (d *Dispatcher) UserMigration(handler Handler[events.CognitoEventUserPoolsMigrateUser]) {
+	d.register(TriggerUserMigration, route(handler))
+}
+
+// Handle dispatches event to the handler registered for its trigger, returning the event of the handler with its
+// response set.
+func (d *Dispatcher) Handle(ctx context.Context, event Event) (Event, error) {
+	trigger := event.Trigger()
+	if trigger == TriggerPreTokenGeneration && d.tokenGenV2 != nil && event.Version != "1" {
+		return d.tokenGenV2(ctx, event)
+	}
+	r, ok := d.routes[trigger]
+	if !ok {
+		return event, fmt.Errorf("%w: %q", ErrNoRoute, event.TriggerSource)
+	}
+	return r(ctx, event)
+}
+
+// register panics if a handler is already registered for trigger.
+func (d *Dispatcher) register(trigger string, r func(context.Context, Event) (Event, error)) {
+	if _, ok := d.routes[trigger]; ok {
+		panic(fmt.Sprintf("cognito: handler already registered for trigger %q", trigger))
+	}
+	d.routes[trigger] = r
+}
+
+// route adapts handler to handle an Event, decoding it into type E and encoding the event returned by handler.
+func route[E any](handler Handler[E]) func(context.Context, Event) (Event, error) {
+	return func(ctx context.Context, event Event) (Event, error) {
+		var typed E
+		if err := json.Unmarshal(event.Raw, &typed); err != nil {
+			return event, fmt.Errorf("%w: %w", ErrInvalidEvent, err)
+		}
+		typed, err := handler.Handle(ctx, typed)
+		if err != nil {
+			return event, err
+		}
+		raw, err := json.Marshal(typed)
+		if err != nil {
+			return event, fmt.Errorf("encoding event: %w", err)
+		}
+		return Event{CognitoEventUserPoolsHeader: event.CognitoEventUserPoolsHeader, Raw: raw}, nil
+	}
+}
+
+// Header returns the header of event if it is an Event or one of the Cognito user pool trigger event types of
+// github.com/aws/aws-lambda-go/events.
+func Header(event any) (events.CognitoEventUserPoolsHeader, bool) {
+	if e, ok := event.(Event); ok {
+		return e.CognitoEventUserPoolsHeader, true
+	}
+	return cognitoredact.Header(event)
+}
@@ -0,0 +1,204 @@
+package cognito
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEvent(t *testing.T, raw string) Event {
+	t.Helper()
+	var event Event
+	require.NoError(t, json.Unmarshal([]byte(raw), &event))
+	return event
+}
+
+func TestEvent_UnmarshalJSON(t *testing.T) {
+	raw := `{"version":"1","triggerSource":"PreSignUp_SignUp","region":"eu-west-2","userPoolId":"eu-west-2_abc",` +
+		`"userName":"jane","callerContext":{"clientId":"client"},"request":{"userAttributes":{"email":"jane@example.com"}}}`
+
+	event := newEvent(t, raw)
+
+	assert.Equal(t, events.CognitoEventUserPoolsHeader{
+		Version:       "1",
+		TriggerSource: "PreSignUp_SignUp",
+		Region:        "eu-west-2",
+		UserPoolID:    "eu-west-2_abc",
+		CallerContext: events.CognitoEventUserPoolsCallerContext{ClientID: "client"},
+		UserName:      "jane",
+	}, event.CognitoEventUserPoolsHeader)
+	assert.Equal(t, TriggerPreSignUp, event.Trigger())
+	b, err := json.Marshal(event)
+	require.NoError(t, err)
+	assert.JSONEq(t, raw, string(b))
+}
+
+func TestDispatcher_Handle(t *testing.T) {
+	preSignUp := HandlerFunc[events.CognitoEventUserPoolsPreSignup](
+		func(_ context.Context, e events.CognitoEventUserPoolsPreSignup) (events.CognitoEventUserPoolsPreSignup, error) {
+			e.Response.AutoConfirmUser = e.Request.UserAttributes["email"] == "jane@example.com"
+			return e, nil
+		},
+	)
+	customMessage := HandlerFunc[events.CognitoEventUserPoolsCustomMessage](
+		func(_ context.Context, e events.CognitoEventUserPoolsCustomMessage) (events.CognitoEventUserPoolsCustomMessage, error) {
+			e.Response.EmailSubject = "Welcome"
+			e.Response.EmailMessage = "Your code is " + e.Request.CodeParameter
+			return e, nil
+		},
+	)
+	tokenGen := HandlerFunc[events.CognitoEventUserPoolsPreTokenGen](
+		func(_ context.Context, e events.CognitoEventUserPoolsPreTokenGen) (events.CognitoEventUserPoolsPreTokenGen, error) {
+			e.Response.ClaimsOverrideDetails.ClaimsToSuppress = []string{"v1"}
+			return e, nil
+		},
+	)
+	tokenGenV2 := HandlerFunc[events.CognitoEventUserPoolsPreTokenGenV2_0](
+		func(_ context.Context, e events.CognitoEventUserPoolsPreTokenGenV2_0) (events.CognitoEventUserPoolsPreTokenGenV2_0, error) {
+			e.Response.ClaimsAndScopeOverrideDetails.AccessTokenGeneration.ScopesToAdd = []string{"v2"}
+			return e, nil
+		},
+	)
+	postConfirmation := HandlerFunc[events.CognitoEventUserPoolsPostConfirmation](
+		func(context.Context, events.CognitoEventUserPoolsPostConfirmation) (events.CognitoEventUserPoolsPostConfirmation, error) {
+			return events.CognitoEventUserPoolsPostConfirmation{}, errors.New("welcome email failed")
+		},
+	)
+	dispatcher := NewDispatcher()
+	dispatcher.PreSignUp(preSignUp)
+	dispatcher.CustomMessage(customMessage)
+	dispatcher.PreTokenGeneration(tokenGen)
+	dispatcher.PreTokenGenerationV2(tokenGenV2)
+	dispatcher.PostConfirmation(postConfirmation)
+
+	tests := []struct {
+		name     string
+		event    string
+		wantPath []string
+		want     any
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		{
+			name:     "pre sign-up, dispatched, response set",
+			event:    `{"triggerSource":"PreSignUp_AdminCreateUser","request":{"userAttributes":{"email":"jane@example.com"}}}`,
+			wantPath: []string{"autoConfirmUser"},
+			want:     true,
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "custom message, dispatched, response set",
+			event:    `{"triggerSource":"CustomMessage_ForgotPassword","request":{"codeParameter":"{####}"}}`,
+			wantPath: []string{"emailMessage"},
+			want:     "Your code is {####}",
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "token generation version 1, dispatched to version 1 handler",
+			event:    `{"version":"1","triggerSource":"TokenGeneration_Authentication"}`,
+			wantPath: []string{"claimsOverrideDetails", "claimsToSuppress"},
+			want:     []any{"v1"},
+			wantErr:  assert.NoError,
+		},
+		{
+			name:     "token generation version 2, dispatched to version 2 handler",
+			event:    `{"version":"2","triggerSource":"TokenGeneration_RefreshTokens"}`,
+			wantPath: []string{"claimsAndScopeOverrideDetails", "accessTokenGeneration", "scopesToAdd"},
+			want:     []any{"v2"},
+			wantErr:  assert.NoError,
+		},
+		{
+			name:  "handler returns error, error returned",
+			event: `{"triggerSource":"PostConfirmation_ConfirmSignUp"}`,
+			wantErr: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.EqualError(t, err, "welcome email failed")
+			},
+		},
+		{
+			name:  "no handler for trigger, ErrNoRoute",
+			event: `{"triggerSource":"UserMigration_Authentication"}`,
+			wantErr: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.ErrorIs(t, err, ErrNoRoute) && assert.ErrorContains(t, err, "UserMigration_Authentication")
+			},
+		},
+		{
+			name:  "event does not match handler type, ErrInvalidEvent",
+			event: `{"triggerSource":"PreSignUp_SignUp","request":{"userAttributes":"jane"}}`,
+			wantErr: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.ErrorIs(t, err, ErrInvalidEvent)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dispatcher.Handle(context.Background(), newEvent(t, tt.event))
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+			var value any
+			b, err := json.Marshal(got)
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal(b, &value))
+			for _, key := range append([]string{"response"}, tt.wantPath...) {
+				m, ok := value.(map[string]any)
+				require.Truef(t, ok, "%q of %s", key, b)
+				value = m[key]
+			}
+			assert.Equal(t, tt.want, value)
+			assert.Equal(t, newEvent(t, tt.event).CognitoEventUserPoolsHeader, got.CognitoEventUserPoolsHeader)
+		})
+	}
+}
+
+func TestDispatcher_duplicateRegistration(t *testing.T) {
+	dispatcher := NewDispatcher()
+	handler := HandlerFunc[events.CognitoEventUserPoolsPreSignup](
+		func(_ context.Context, e events.CognitoEventUserPoolsPreSignup) (events.CognitoEventUserPoolsPreSignup, error) {
+			return e, nil
+		},
+	)
+	dispatcher.PreSignUp(handler)
+
+	assert.PanicsWithValue(t, `cognito: handler already registered for trigger "PreSignUp"`, func() {
+		dispatcher.PreSignUp(handler)
+	})
+}
+
+func TestHeader(t *testing.T) {
+	header := events.CognitoEventUserPoolsHeader{TriggerSource: "CustomMessage_SignUp", UserPoolID: "pool"}
+	tests := []struct {
+		name   string
+		event  any
+		want   events.CognitoEventUserPoolsHeader
+		wantOk bool
+	}{
+		{
+			name:   "Event, header returned",
+			event:  Event{CognitoEventUserPoolsHeader: header},
+			want:   header,
+			wantOk: true,
+		},
+		{
+			name:   "typed event, header returned",
+			event:  events.CognitoEventUserPoolsCustomMessage{CognitoEventUserPoolsHeader: header},
+			want:   header,
+			wantOk: true,
+		},
+		{
+			name:   "other event, not ok",
+			event:  events.SQSEvent{},
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Header(tt.event)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, ok)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package cognito
+
+import (
+	"log/slog"
+
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/middleware"
+)
+
+// Middleware is a slice of WithResponse middleware for handlers of Event that return Event.
+type Middleware []middleware.WithResponse[Event, Event]
+
+// CommonCognito returns a slice of common middleware for handlers of Event that return Event, such as a Dispatcher.
+// Each Event is redacted by the event logger and its user pool details are added to the context.
+func CommonCognito(logger *slog.Logger) Middleware {
+	return middleware.CommonWithResponse[Event, Event](logger)
+}
@@ -0,0 +1,60 @@
+package cognito
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ellogroup/ello-golang-aws/v2/internal/cognitoredact"
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/middleware"
+)
+
+func TestCommonCognito(t *testing.T) {
+	var logs bytes.Buffer
+	next := func(_ context.Context, event Event) (Event, error) {
+		return event, nil
+	}
+	for _, m := range CommonCognito(slog.New(slog.NewJSONHandler(&logs, nil))) {
+		next = m.Wrap(next)
+	}
+	event := newEvent(t, `{"triggerSource":"PostConfirmation_ConfirmSignUp","userName":"jane@example.com"}`)
+
+	_, err := next(context.Background(), event)
+
+	require.NoError(t, err)
+	assert.Contains(t, logs.String(), cognitoredact.RedactedValue)
+	assert.NotContains(t, logs.String(), "jane@example.com")
+}
+
+func TestCommonWithResponse_typedTrigger(t *testing.T) {
+	var logs bytes.Buffer
+	next := func(_ context.Context, event events.CognitoEventUserPoolsMigrateUser) (events.CognitoEventUserPoolsMigrateUser, error) {
+		event.CognitoEventUserPoolsMigrateUserResponse.UserAttributes = map[string]string{"email": "jane@example.com", "phone_number": "+447700900000"}
+		return event, nil
+	}
+	chain := middleware.CommonWithResponse[events.CognitoEventUserPoolsMigrateUser, events.CognitoEventUserPoolsMigrateUser](
+		slog.New(slog.NewJSONHandler(&logs, nil)))
+	for _, m := range chain {
+		next = m.Wrap(next)
+	}
+	event := events.CognitoEventUserPoolsMigrateUser{
+		CognitoEventUserPoolsHeader: events.CognitoEventUserPoolsHeader{
+			TriggerSource: "UserMigration_Authentication",
+			UserName:      "jane@example.com",
+		},
+		CognitoEventUserPoolsMigrateUserRequest: events.CognitoEventUserPoolsMigrateUserRequest{Password: "hunter2"},
+	}
+
+	_, err := next(context.Background(), event)
+
+	require.NoError(t, err)
+	assert.Contains(t, logs.String(), cognitoredact.RedactedValue)
+	assert.NotContains(t, logs.String(), "jane@example.com")
+	assert.NotContains(t, logs.String(), "+447700900000")
+	assert.NotContains(t, logs.String(), "hunter2")
+}
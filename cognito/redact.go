@@ -0,0 +1,38 @@
+package cognito
+
+import (
+	"log/slog"
+
+	"github.com/ellogroup/ello-golang-aws/v2/internal/cognitoredact"
+)
+
+// HashUsername returns a hash of username identifying a user in logs without logging the username, which is often an
+// email address.
+func HashUsername(username string) string {
+	return cognitoredact.HashUsername(username)
+}
+
+// Redact returns a redacted copy of event, as a map, if it is an Event or one of the Cognito user pool trigger event
+// types of github.com/aws/aws-lambda-go/events. The username, the values of user attributes other than sub,
+// email_verified, phone_number_verified and cognito:user_status, passwords, validation data, client metadata, challenge
+// answers and private challenge parameters are replaced with [REDACTED]. Other events are returned unchanged.
+//
+// The event logger of the middleware package redacts these events the same way by default.
+func Redact(event any) any {
+	if _, ok := Header(event); !ok {
+		return event
+	}
+	return cognitoredact.Redact(event)
+}
+
+// Redact returns a redacted copy of the event (see Redact). It implements middleware.Redactable, so the event logger
+// redacts each event.
+func (e Event) Redact() any {
+	return Redact(e)
+}
+
+// LogFields returns the user pool id, trigger source and a hash of the username of the event (see HashUsername). It
+// implements middleware.Loggable, so the context middleware adds them to the context of each event.
+func (e Event) LogFields() []slog.Attr {
+	return cognitoredact.LogFields(e.CognitoEventUserPoolsHeader)
+}
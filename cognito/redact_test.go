@@ -0,0 +1,144 @@
+package cognito
+
+import (
+	"log/slog"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ellogroup/ello-golang-aws/v2/internal/cognitoredact"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name  string
+		event any
+		want  any
+	}{
+		{
+			name: "pre sign-up, username, personal attributes and validation data redacted",
+			event: events.CognitoEventUserPoolsPreSignup{
+				CognitoEventUserPoolsHeader: events.CognitoEventUserPoolsHeader{
+					TriggerSource: "PreSignUp_SignUp",
+					UserPoolID:    "pool",
+					UserName:      "jane@example.com",
+				},
+				Request: events.CognitoEventUserPoolsPreSignupRequest{
+					UserAttributes: map[string]string{"sub": "123", "email": "jane@example.com", "email_verified": "false"},
+					ValidationData: map[string]string{"captcha": "token"},
+				},
+			},
+			want: map[string]any{
+				"version":       "",
+				"triggerSource": "PreSignUp_SignUp",
+				"region":        "",
+				"userPoolId":    "pool",
+				"callerContext": map[string]any{"awsSdkVersion": "", "clientId": ""},
+				"userName":      cognitoredact.RedactedValue,
+				"request": map[string]any{
+					"userAttributes": map[string]any{"sub": "123", "email": cognitoredact.RedactedValue, "email_verified": "false"},
+					"validationData": map[string]any{"captcha": cognitoredact.RedactedValue},
+					"clientMetadata": nil,
+				},
+				"response": map[string]any{"autoConfirmUser": false, "autoVerifyEmail": false, "autoVerifyPhone": false},
+			},
+		},
+		{
+			name: "user migration Event, password and migrated attributes redacted",
+			event: Event{Raw: []byte(`{"triggerSource":"UserMigration_Authentication","userName":"jane",` +
+				`"request":{"password":"hunter2"},"response":{"userAttributes":{"email":"jane@example.com"}}}`)},
+			want: map[string]any{
+				"triggerSource": "UserMigration_Authentication",
+				"userName":      cognitoredact.RedactedValue,
+				"request":       map[string]any{"password": cognitoredact.RedactedValue},
+				"response":      map[string]any{"userAttributes": map[string]any{"email": cognitoredact.RedactedValue}},
+			},
+		},
+		{
+			name: "verify auth challenge, answer and private challenge parameters redacted",
+			event: events.CognitoEventUserPoolsVerifyAuthChallenge{
+				Request: events.CognitoEventUserPoolsVerifyAuthChallengeRequest{
+					PrivateChallengeParameters: map[string]string{"answer": "42"},
+					ChallengeAnswer:            "42",
+				},
+			},
+			want: map[string]any{
+				"version":       "",
+				"triggerSource": "",
+				"region":        "",
+				"userPoolId":    "",
+				"callerContext": map[string]any{"awsSdkVersion": "", "clientId": ""},
+				"userName":      "",
+				"request": map[string]any{
+					"userAttributes":             nil,
+					"privateChallengeParameters": map[string]any{"answer": cognitoredact.RedactedValue},
+					"challengeAnswer":            cognitoredact.RedactedValue,
+					"clientMetadata":             nil,
+				},
+				"response": map[string]any{"answerCorrect": false},
+			},
+		},
+		{
+			name: "custom message, client metadata redacted",
+			event: events.CognitoEventUserPoolsCustomMessage{
+				Request: events.CognitoEventUserPoolsCustomMessageRequest{
+					ClientMetadata: map[string]string{"invite_code": "abc123"},
+				},
+			},
+			want: map[string]any{
+				"version":       "",
+				"triggerSource": "",
+				"region":        "",
+				"userPoolId":    "",
+				"callerContext": map[string]any{"awsSdkVersion": "", "clientId": ""},
+				"userName":      "",
+				"request": map[string]any{
+					"userAttributes":    nil,
+					"codeParameter":     "",
+					"usernameParameter": "",
+					"clientMetadata":    map[string]any{"invite_code": cognitoredact.RedactedValue},
+				},
+				"response": map[string]any{"smsMessage": "", "emailMessage": "", "emailSubject": ""},
+			},
+		},
+		{
+			name:  "other event, unchanged",
+			event: events.SQSEvent{},
+			want:  events.SQSEvent{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Redact(tt.event))
+		})
+	}
+}
+
+func TestHashUsername(t *testing.T) {
+	assert.Equal(t, "8c6976e5b5410415", HashUsername("admin"))
+	assert.NotEqual(t, HashUsername("jane"), HashUsername("john"))
+}
+
+func TestEvent_Redact(t *testing.T) {
+	event := Event{Raw: []byte(`{"triggerSource":"PostConfirmation_ConfirmSignUp","userName":"jane"}`)}
+
+	assert.Equal(t, map[string]any{
+		"triggerSource": "PostConfirmation_ConfirmSignUp",
+		"userName":      cognitoredact.RedactedValue,
+	}, event.Redact())
+}
+
+func TestEvent_LogFields(t *testing.T) {
+	event := Event{CognitoEventUserPoolsHeader: events.CognitoEventUserPoolsHeader{
+		TriggerSource: "PreSignUp_SignUp",
+		UserPoolID:    "eu-west-2_abc",
+		UserName:      "admin",
+	}}
+
+	assert.Equal(t, []slog.Attr{
+		slog.String("user_pool_id", "eu-west-2_abc"),
+		slog.String("trigger_source", "PreSignUp_SignUp"),
+		slog.String("username_hash", "8c6976e5b5410415"),
+	}, event.LogFields())
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+)
+
+const (
+	defaultCacheTTL      = 5 * time.Minute
+	defaultCacheMaxStale = time.Hour
+	cacheRefreshTimeout  = 10 * time.Second
+)
+
+type cacheOptions struct {
+	ttl      time.Duration
+	maxStale time.Duration
+}
+
+// CacheOption configures NewCachedSource.
+type CacheOption func(*cacheOptions)
+
+// WithCacheTTL sets how long values are used before they are refreshed. Defaults to 5 minutes.
+func WithCacheTTL(d time.Duration) CacheOption {
+	return func(o *cacheOptions) {
+		o.ttl = d
+	}
+}
+
+// WithCacheMaxStale sets how long past the TTL a value is still used while it is refreshed in the background, or if
+// refreshing it fails. Past it, the value is fetched before it is returned. Defaults to 1 hour.
+func WithCacheMaxStale(d time.Duration) CacheOption {
+	return func(o *cacheOptions) {
+		o.maxStale = d
+	}
+}
+
+// CachedSource is a Source caching the values of another Source in memory, so rotated parameters and secrets are picked
+// up without a redeploy while most invocations are served from memory. It lives as long as the Lambda container, so
+// create it once, outside the handler. It is safe for concurrent use.
+//
+// A value is fetched on first use and used for the TTL (see WithCacheTTL). Once the TTL has passed, the cached value is
+// still returned while a single background refresh fetches the new one, so invocations do not wait for the source.
+// If the refresh fails the cached value is kept, until it is older than the TTL plus the max stale period (see
+// WithCacheMaxStale), when it is fetched before being returned and errors are returned. Errors are never cached. A
+// Lambda container is frozen between invocations, so a refresh still running when an invocation ends completes in a
+// later one.
+type CachedSource struct {
+	clock  clock.Clock
+	source Source
+	opts   cacheOptions
+	values *cache[string]
+	paths  *cache[map[string]string]
+	wg     sync.WaitGroup
+}
+
+// NewCachedSource returns a CachedSource caching the values of source.
+func NewCachedSource(source Source, options ...CacheOption) *CachedSource {
+	opts := cacheOptions{ttl: defaultCacheTTL, maxStale: defaultCacheMaxStale}
+	for _, option := range options {
+		option(&opts)
+	}
+	return &CachedSource{
+		clock:  clock.NewSystem(),
+		source: source,
+		opts:   opts,
+		values: newCache[string](),
+		paths:  newCache[map[string]string](),
+	}
+}
+
+// Get returns the value of the parameter or secret name, from the cache if it has not expired.
+func (c *CachedSource) Get(ctx context.Context, name string) (string, error) {
+	return get(ctx, c, c.values, name, c.source.Get)
+}
+
+// GetPath returns the values of every parameter or secret under path, from the cache if they have not expired. Paths
+// are cached separately from the names under them.
+func (c *CachedSource) GetPath(ctx context.Context, path string) (map[string]string, error) {
+	values, err := get(ctx, c, c.paths, path, c.source.GetPath)
+	return maps.Clone(values), err
+}
+
+// cache is the cache of a CachedSource for values of type T, keyed by name or path.
+type cache[T any] struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry[T]
+}
+
+type cacheEntry[T any] struct {
+	value      T
+	fetched    time.Time
+	refreshing bool
+}
+
+func newCache[T any]() *cache[T] {
+	return &cache[T]{entries: map[string]*cacheEntry[T]{}}
+}
+
+// get returns the value of key from cache, fetching it with fetch if it is missing or too stale, or refreshing it in
+// the background if it has only expired.
+func get[T any](ctx context.Context, c *CachedSource, cache *cache[T], key string, fetch func(context.Context, string) (T, error)) (T, error) {
+	now := c.clock.Now()
+	cache.mu.Lock()
+	entry, ok := cache.entries[key]
+	if ok {
+		age := now.Sub(entry.fetched)
+		value := entry.value
+		if age < c.opts.ttl {
+			cache.mu.Unlock()
+			return value, nil
+		}
+		if age < c.opts.ttl+c.opts.maxStale {
+			if !entry.refreshing {
+				entry.refreshing = true
+				refresh(ctx, c, cache, key, entry, fetch)
+			}
+			cache.mu.Unlock()
+			return value, nil
+		}
+	}
+	cache.mu.Unlock()
+
+	value, err := fetch(ctx, key)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	cache.mu.Lock()
+	cache.entries[key] = &cacheEntry[T]{value: value, fetched: c.clock.Now()}
+	cache.mu.Unlock()
+	return value, nil
+}
+
+// refresh fetches the value of key into entry of cache in the background, keeping the cached value if it fails. The
+// fetch is not canceled with ctx, as the invocation that triggered it may end first. If entry has been replaced by a
+// newer value fetched in the meantime, the refreshed value is discarded.
+func refresh[T any](ctx context.Context, c *CachedSource, cache *cache[T], key string, entry *cacheEntry[T], fetch func(context.Context, string) (T, error)) {
+	c.wg.Go(func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheRefreshTimeout)
+		defer cancel()
+		value, err := fetch(ctx, key)
+
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		entry.refreshing = false
+		if err == nil && cache.entries[key] == entry {
+			entry.value, entry.fetched = value, c.clock.Now()
+		}
+	})
+}
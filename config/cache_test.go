@@ -0,0 +1,181 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingSource counts the fetches of a MemorySource, failing them with err if set.
+type countingSource struct {
+	*MemorySource
+	gets  int
+	paths int
+	err   error
+}
+
+func (s *countingSource) Get(ctx context.Context, name string) (string, error) {
+	s.gets++
+	if s.err != nil {
+		return "", s.err
+	}
+	return s.MemorySource.Get(ctx, name)
+}
+
+func (s *countingSource) GetPath(ctx context.Context, path string) (map[string]string, error) {
+	s.paths++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.MemorySource.GetPath(ctx, path)
+}
+
+func TestCachedSource_Get(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	source := &countingSource{MemorySource: NewMemorySource(map[string]string{"/orders/key": "v1"})}
+	cached := NewCachedSource(source, WithCacheTTL(time.Minute), WithCacheMaxStale(time.Hour))
+	cached.clock = clock.NewFixed(now)
+
+	get := func(want string) {
+		t.Helper()
+		value, err := cached.Get(ctx, "/orders/key")
+		require.NoError(t, err)
+		assert.Equal(t, want, value)
+		cached.wg.Wait()
+	}
+
+	// Fetched on first use, then cached for the TTL
+	get("v1")
+	source.Set("/orders/key", "v2")
+	cached.clock = clock.NewFixed(now.Add(59 * time.Second))
+	get("v1")
+	assert.Equal(t, 1, source.gets)
+
+	// Expired, stale value returned while refreshed in the background
+	cached.clock = clock.NewFixed(now.Add(time.Minute))
+	get("v1")
+	assert.Equal(t, 2, source.gets)
+	get("v2")
+	assert.Equal(t, 2, source.gets)
+
+	// Refresh failed, stale value kept and refreshed again
+	source.Set("/orders/key", "v3")
+	source.err = errors.New("throttled")
+	cached.clock = clock.NewFixed(now.Add(3 * time.Minute))
+	get("v2")
+	get("v2")
+	assert.Equal(t, 4, source.gets)
+
+	// Too stale, fetched before being returned and errors returned
+	cached.clock = clock.NewFixed(now.Add(2 * time.Hour))
+	_, err := cached.Get(ctx, "/orders/key")
+	assert.EqualError(t, err, "throttled")
+	source.err = nil
+	get("v3")
+	assert.Equal(t, 6, source.gets)
+}
+
+// funcSource is a Source fetching values with get.
+type funcSource struct {
+	get func(ctx context.Context, name string) (string, error)
+}
+
+func (s funcSource) Get(ctx context.Context, name string) (string, error) {
+	return s.get(ctx, name)
+}
+
+func (funcSource) GetPath(context.Context, string) (map[string]string, error) {
+	return nil, nil
+}
+
+func TestCachedSource_Get_refreshOutdated(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var calls atomic.Int32
+	refreshing, release := make(chan struct{}), make(chan struct{})
+	source := funcSource{get: func(context.Context, string) (string, error) {
+		switch calls.Add(1) {
+		case 1:
+			return "v1", nil
+		case 2:
+			// The background refresh, completing after a newer value is fetched
+			close(refreshing)
+			<-release
+			return "v2", nil
+		}
+		return "v3", nil
+	}}
+	cached := NewCachedSource(source, WithCacheTTL(time.Minute), WithCacheMaxStale(time.Hour))
+	cached.clock = clock.NewFixed(now)
+
+	value, err := cached.Get(ctx, "/orders/key")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	cached.clock = clock.NewFixed(now.Add(time.Minute))
+	value, err = cached.Get(ctx, "/orders/key")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+	<-refreshing
+
+	cached.clock = clock.NewFixed(now.Add(2 * time.Hour))
+	value, err = cached.Get(ctx, "/orders/key")
+	require.NoError(t, err)
+	assert.Equal(t, "v3", value)
+
+	close(release)
+	cached.wg.Wait()
+	value, err = cached.Get(ctx, "/orders/key")
+	require.NoError(t, err)
+	assert.Equal(t, "v3", value, "newer value not overwritten by the refresh")
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestCachedSource_Get_notFound(t *testing.T) {
+	ctx := context.Background()
+	source := &countingSource{MemorySource: NewMemorySource(nil)}
+	cached := NewCachedSource(source)
+
+	_, err := cached.Get(ctx, "/orders/key")
+	assert.ErrorIs(t, err, ErrNotFound)
+	source.Set("/orders/key", "v1")
+	value, err := cached.Get(ctx, "/orders/key")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+	assert.Equal(t, 2, source.gets, "errors are not cached")
+}
+
+func TestCachedSource_GetPath(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	source := &countingSource{MemorySource: NewMemorySource(map[string]string{"/orders/a": "1", "/orders/b": "2"})}
+	cached := NewCachedSource(source)
+	cached.clock = clock.NewFixed(now)
+
+	values, err := cached.GetPath(ctx, "/orders")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"/orders/a": "1", "/orders/b": "2"}, values)
+
+	values["/orders/a"] = "changed by caller"
+	source.Set("/orders/c", "3")
+	values, err = cached.GetPath(ctx, "/orders")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"/orders/a": "1", "/orders/b": "2"}, values)
+	assert.Equal(t, 1, source.paths)
+
+	cached.clock = clock.NewFixed(now.Add(defaultCacheTTL))
+	_, err = cached.GetPath(ctx, "/orders")
+	require.NoError(t, err)
+	cached.wg.Wait()
+	values, err = cached.GetPath(ctx, "/orders")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"/orders/a": "1", "/orders/b": "2", "/orders/c": "3"}, values)
+	assert.Equal(t, 0, source.gets, "paths are cached separately from names")
+}
@@ -0,0 +1,314 @@
+// Package config loads the configuration of a Lambda from environment variables into a typed struct, bound through
+// struct tags, failing fast with every missing or invalid variable at once.
+//
+//	type Config struct {
+//		TableName string        `env:"TABLE_NAME,required"`
+//		Timeout   time.Duration `env:"TIMEOUT" default:"5s"`
+//		Origins   []string      `env:"ALLOWED_ORIGINS"`
+//		APIURL    *url.URL      `env:"API_URL,required"`
+//		APIKey    string        `env:"API_KEY,required,secret"`
+//		DB        DBConfig      `prefix:"DB_"`
+//	}
+//
+//	func (c Config) LogValue() slog.Value { return config.LogValue(c) }
+//
+//	cfg := config.MustLoad[Config]()
+//	logger.Info("Config loaded", slog.Any("config", cfg))
+//	lambda.Start[events.SQSEvent](handler.New(cfg), middleware.CommonSQS(logger))
+//
+// Parameters and secrets that rotate are read at runtime from a Source instead, such as SSM Parameter Store or Secrets
+// Manager, cached in memory by a CachedSource.
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSeparator = ","
+	redactedValue    = "[REDACTED]"
+)
+
+var (
+	// ErrMissing is returned by Load, joined with any other errors, for each required variable that is not set.
+	ErrMissing = errors.New("config: missing required variable")
+	// ErrInvalid is returned by Load, joined with any other errors, for each variable whose value cannot be parsed into
+	// the type of its field.
+	ErrInvalid = errors.New("config: invalid variable")
+)
+
+var (
+	durationType        = reflect.TypeFor[time.Duration]()
+	urlType             = reflect.TypeFor[url.URL]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+type loadOptions struct {
+	lookup func(string) (string, bool)
+	prefix string
+}
+
+// Option configures Load.
+type Option func(*loadOptions)
+
+// WithLookup sets the function variables are looked up with. Defaults to os.LookupEnv.
+func WithLookup(lookup func(name string) (string, bool)) Option {
+	return func(o *loadOptions) {
+		o.lookup = lookup
+	}
+}
+
+// WithPrefix sets a prefix added to the name of every variable, e.g. "ORDERS_" to load ORDERS_TABLE_NAME for a field
+// tagged `env:"TABLE_NAME"`.
+func WithPrefix(prefix string) Option {
+	return func(o *loadOptions) {
+		o.prefix = prefix
+	}
+}
+
+// Load returns a T, which must be a struct, with its fields set from environment variables.
+//
+// A field tagged `env:"NAME"` is set from the variable NAME, parsed into the type of the field. Supported types are
+// strings, bools, integers, floats, time.Duration, url.URL (which must be absolute), types implementing
+// encoding.TextUnmarshaler, pointers to any of them and slices of any of them, split on commas or the separator of a
+// `sep:";"` tag. The options of the env tag are:
+//
+//   - required: the variable must be set, and not empty, unless the field has a default.
+//   - secret: the value is redacted by LogValue, and from the errors of Load.
+//
+// A `default:"value"` tag sets the value used when the variable is not set or empty. Struct fields without an env tag
+// are loaded as nested configuration, with the names of their variables prefixed with the value of a `prefix:"DB_"`
+// tag, if any. Fields without tags and unexported fields are left unchanged.
+//
+// Every missing or invalid variable is reported: the error joins an error wrapping ErrMissing or ErrInvalid for each.
+func Load[T any](options ...Option) (T, error) {
+	opts := loadOptions{lookup: os.LookupEnv}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	var cfg T
+	v := reflect.ValueOf(&cfg).Elem()
+	if v.Kind() != reflect.Struct {
+		return cfg, fmt.Errorf("config: %T is not a struct", cfg)
+	}
+	l := loader{lookup: opts.lookup}
+	l.load(v, opts.prefix)
+	return cfg, errors.Join(l.errs...)
+}
+
+// MustLoad is like Load but panics if the configuration cannot be loaded. Call it at init, so a Lambda with a missing
+// or invalid variable fails before handling any event.
+func MustLoad[T any](options ...Option) T {
+	cfg, err := Load[T](options...)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+// LogValue returns the value of cfg, a struct loaded by Load, for logging with slog: a group of its tagged fields, with
+// the values of fields tagged secret redacted. Implement slog.LogValuer on the configuration struct with it:
+//
+//	func (c Config) LogValue() slog.Value { return config.LogValue(c) }
+func LogValue(cfg any) slog.Value {
+	v := reflect.ValueOf(cfg)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return slog.AnyValue(cfg)
+	}
+	return logValue(v)
+}
+
+func logValue(v reflect.Value) slog.Value {
+	t := v.Type()
+	var attrs []slog.Attr
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, ok := f.Tag.Lookup("env")
+		if !ok {
+			if isNested(f) {
+				attrs = append(attrs, slog.Attr{Key: f.Name, Value: logValue(v.Field(i))})
+			}
+			continue
+		}
+		if _, _, secret := parseTag(tag); secret {
+			attrs = append(attrs, slog.String(f.Name, redactedValue))
+			continue
+		}
+		attrs = append(attrs, slog.Attr{Key: f.Name, Value: fieldLogValue(v.Field(i))})
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func fieldLogValue(v reflect.Value) slog.Value {
+	switch {
+	case v.Type() == urlType:
+		u, _ := v.Interface().(url.URL)
+		return slog.StringValue(u.String())
+	case v.Kind() == reflect.Pointer && v.Type().Elem() == urlType && !v.IsNil():
+		u, _ := v.Interface().(*url.URL)
+		return slog.StringValue(u.String())
+	}
+	return slog.AnyValue(v.Interface())
+}
+
+type loader struct {
+	lookup func(string) (string, bool)
+	errs   []error
+}
+
+func (l *loader) load(v reflect.Value, prefix string) {
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, ok := f.Tag.Lookup("env")
+		if !ok {
+			if isNested(f) {
+				l.load(v.Field(i), prefix+f.Tag.Get("prefix"))
+			}
+			continue
+		}
+
+		name, required, secret := parseTag(tag)
+		name = prefix + name
+		raw, _ := l.lookup(name)
+		if raw == "" {
+			def, ok := f.Tag.Lookup("default")
+			if !ok {
+				if required {
+					l.errs = append(l.errs, fmt.Errorf("%w %s", ErrMissing, name))
+				}
+				continue
+			}
+			raw = def
+		}
+
+		sep := defaultSeparator
+		if s, ok := f.Tag.Lookup("sep"); ok {
+			sep = s
+		}
+		if err := setValue(v.Field(i), raw, sep); err != nil {
+			if secret {
+				l.errs = append(l.errs, fmt.Errorf("%w %s", ErrInvalid, name))
+			} else {
+				l.errs = append(l.errs, fmt.Errorf("%w %s: %w", ErrInvalid, name, err))
+			}
+		}
+	}
+}
+
+// isNested returns whether f is a struct field loaded as nested configuration.
+func isNested(f reflect.StructField) bool {
+	return f.Type.Kind() == reflect.Struct && f.Type != urlType && !reflect.PointerTo(f.Type).Implements(textUnmarshalerType)
+}
+
+// parseTag returns the name of the variable of an env tag and whether it has the required and secret options.
+func parseTag(tag string) (name string, required, secret bool) {
+	name, opts, _ := strings.Cut(tag, ",")
+	for opt := range strings.SplitSeq(opts, ",") {
+		switch strings.TrimSpace(opt) {
+		case "required":
+			required = true
+		case "secret":
+			secret = true
+		}
+	}
+	return name, required, secret
+}
+
+// setValue sets v to raw parsed into the type of v, splitting raw on sep for slices.
+func setValue(v reflect.Value, raw, sep string) error {
+	t := v.Type()
+	if t == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	if t == urlType {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return err
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%q is not an absolute URL", raw)
+		}
+		v.Set(reflect.ValueOf(*u))
+		return nil
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Pointer:
+		p := reflect.New(t.Elem())
+		if err := setValue(p.Elem(), raw, sep); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.Slice:
+		var items []string
+		for item := range strings.SplitSeq(raw, sep) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		s := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			if err := setValue(s.Index(i), item, sep); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		v.Set(s)
+	default:
+		return fmt.Errorf("unsupported type %s", t)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dbConfig struct {
+	Host     string `env:"HOST,required"`
+	Port     int    `env:"PORT" default:"5432"`
+	Password string `env:"PASSWORD,required,secret"`
+}
+
+type testConfig struct {
+	TableName string        `env:"TABLE_NAME,required"`
+	Timeout   time.Duration `env:"TIMEOUT" default:"5s"`
+	Debug     bool          `env:"DEBUG"`
+	Ratio     float64       `env:"RATIO"`
+	Retries   uint8         `env:"RETRIES"`
+	Origins   []string      `env:"ALLOWED_ORIGINS"`
+	Ports     []int         `env:"PORTS" sep:";"`
+	APIURL    url.URL       `env:"API_URL"`
+	Callback  *url.URL      `env:"CALLBACK_URL"`
+	Addr      netip.Addr    `env:"ADDR"`
+	MaxItems  *int          `env:"MAX_ITEMS"`
+	DB        dbConfig      `prefix:"DB_"`
+	Ignored   string
+	internal  string `env:"INTERNAL"`
+}
+
+func lookup(env map[string]string) Option {
+	return WithLookup(func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	})
+}
+
+func TestLoad(t *testing.T) {
+	maxItems := 10
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		options  []Option
+		want     testConfig
+		wantErrs []error
+		wantMsg  string
+	}{
+		{
+			name: "every type, loaded",
+			env: map[string]string{
+				"TABLE_NAME":      "orders",
+				"TIMEOUT":         "1m30s",
+				"DEBUG":           "true",
+				"RATIO":           "0.25",
+				"RETRIES":         "3",
+				"ALLOWED_ORIGINS": "https://a.example.com, https://b.example.com,",
+				"PORTS":           "80;443",
+				"API_URL":         "https://api.example.com/v1",
+				"CALLBACK_URL":    "https://example.com/callback",
+				"ADDR":            "10.0.0.1",
+				"MAX_ITEMS":       "10",
+				"DB_HOST":         "db.internal",
+				"DB_PORT":         "6543",
+				"DB_PASSWORD":     "hunter2",
+				"INTERNAL":        "ignored",
+			},
+			want: testConfig{
+				TableName: "orders",
+				Timeout:   90 * time.Second,
+				Debug:     true,
+				Ratio:     0.25,
+				Retries:   3,
+				Origins:   []string{"https://a.example.com", "https://b.example.com"},
+				Ports:     []int{80, 443},
+				APIURL:    url.URL{Scheme: "https", Host: "api.example.com", Path: "/v1"},
+				Callback:  &url.URL{Scheme: "https", Host: "example.com", Path: "/callback"},
+				Addr:      netip.MustParseAddr("10.0.0.1"),
+				MaxItems:  &maxItems,
+				DB:        dbConfig{Host: "db.internal", Port: 6543, Password: "hunter2"},
+			},
+		},
+		{
+			name: "unset and empty, defaults used",
+			env:  map[string]string{"TABLE_NAME": "orders", "TIMEOUT": "", "DB_HOST": "db", "DB_PASSWORD": "pw"},
+			want: testConfig{
+				TableName: "orders",
+				Timeout:   5 * time.Second,
+				DB:        dbConfig{Host: "db", Port: 5432, Password: "pw"},
+			},
+		},
+		{
+			name:    "prefix, added to every variable",
+			env:     map[string]string{"ORDERS_TABLE_NAME": "orders", "ORDERS_DB_HOST": "db", "ORDERS_DB_PASSWORD": "pw", "TABLE_NAME": "other"},
+			options: []Option{WithPrefix("ORDERS_")},
+			want: testConfig{
+				TableName: "orders",
+				Timeout:   5 * time.Second,
+				DB:        dbConfig{Host: "db", Port: 5432, Password: "pw"},
+			},
+		},
+		{
+			name: "missing and invalid, every variable reported",
+			env: map[string]string{
+				"TABLE_NAME":   "",
+				"TIMEOUT":      "soon",
+				"PORTS":        "80;http",
+				"API_URL":      "/v1",
+				"DB_PORT":      "big",
+				"DB_PASSWORD":  "",
+				"CALLBACK_URL": "https://example.com",
+			},
+			wantErrs: []error{ErrMissing, ErrInvalid},
+			wantMsg: "config: missing required variable TABLE_NAME\n" +
+				"config: invalid variable TIMEOUT: time: invalid duration \"soon\"\n" +
+				"config: invalid variable PORTS: item 1: strconv.ParseInt: parsing \"http\": invalid syntax\n" +
+				"config: invalid variable API_URL: \"/v1\" is not an absolute URL\n" +
+				"config: missing required variable DB_HOST\n" +
+				"config: invalid variable DB_PORT: strconv.ParseInt: parsing \"big\": invalid syntax\n" +
+				"config: missing required variable DB_PASSWORD",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load[testConfig](append([]Option{lookup(tt.env)}, tt.options...)...)
+
+			if tt.wantErrs == nil {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				assert.Empty(t, got.internal, "unexported fields are not loaded")
+				return
+			}
+			for _, wantErr := range tt.wantErrs {
+				assert.ErrorIs(t, err, wantErr)
+			}
+			assert.EqualError(t, err, tt.wantMsg)
+		})
+	}
+}
+
+func TestLoad_secretNotInError(t *testing.T) {
+	type secretConfig struct {
+		Key int `env:"KEY,secret"`
+	}
+
+	_, err := Load[secretConfig](lookup(map[string]string{"KEY": "s3cr3t"}))
+
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.EqualError(t, err, "config: invalid variable KEY")
+}
+
+func TestLoad_unsupportedType(t *testing.T) {
+	type mapConfig struct {
+		Tags map[string]string `env:"TAGS"`
+	}
+
+	_, err := Load[mapConfig](lookup(map[string]string{"TAGS": "a=b"}))
+
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.EqualError(t, err, "config: invalid variable TAGS: unsupported type map[string]string")
+}
+
+func TestLoad_notStruct(t *testing.T) {
+	_, err := Load[string]()
+	assert.EqualError(t, err, "config: string is not a struct")
+}
+
+func TestMustLoad(t *testing.T) {
+	type requiredConfig struct {
+		Name string `env:"NAME,required"`
+	}
+
+	assert.Equal(t, requiredConfig{Name: "orders"}, MustLoad[requiredConfig](lookup(map[string]string{"NAME": "orders"})))
+	assert.PanicsWithError(t, "config: missing required variable NAME", func() {
+		MustLoad[requiredConfig](lookup(map[string]string{}))
+	})
+}
+
+func (c testConfig) LogValue() slog.Value { return LogValue(c) }
+
+func TestLogValue(t *testing.T) {
+	cfg := testConfig{
+		TableName: "orders",
+		Timeout:   time.Second,
+		APIURL:    url.URL{Scheme: "https", Host: "api.example.com"},
+		Callback:  &url.URL{Scheme: "https", Host: "example.com", Path: "/callback"},
+		Origins:   []string{"https://a.example.com"},
+		DB:        dbConfig{Host: "db", Port: 5432, Password: "hunter2"},
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	logger.Info("Config loaded", slog.Any("config", cfg))
+
+	assert.Equal(t, "level=INFO msg=\"Config loaded\" config.TableName=orders config.Timeout=1s config.Debug=false "+
+		"config.Ratio=0 config.Retries=0 config.Origins=[https://a.example.com] config.Ports=[] "+
+		"config.APIURL=https://api.example.com config.Callback=https://example.com/callback config.Addr=\"\" "+
+		"config.MaxItems=<nil> config.DB.Host=db config.DB.Port=5432 config.DB.Password=[REDACTED]\n", buf.String())
+	assert.NotContains(t, buf.String(), "hunter2")
+}
+
+func TestLogValue_notStruct(t *testing.T) {
+	assert.Equal(t, slog.StringValue("value"), LogValue("value"))
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// errorCodeResourceNotFound is the error code of Secrets Manager for a secret that does not exist.
+	errorCodeResourceNotFound = "ResourceNotFoundException"
+	// filterKeyName is the key of the filter of secrets by name, which matches the start of their names.
+	filterKeyName = "name"
+)
+
+// SecretsManagerClient interface should be implemented for clients of Secrets Manager, usually by adapting a
+// secretsmanager.Client. Its methods mirror the operations of the SDK, one request per call, and errors should be
+// returned as the SDK returns them.
+type SecretsManagerClient interface {
+	// GetSecretValue returns the current value of the secret of input.
+	GetSecretValue(ctx context.Context, input *GetSecretValueInput) (*GetSecretValueOutput, error)
+	// BatchGetSecretValue returns a page of the current values of the secrets matching the filters of input.
+	BatchGetSecretValue(ctx context.Context, input *BatchGetSecretValueInput) (*BatchGetSecretValueOutput, error)
+}
+
+// GetSecretValueInput is the input of SecretsManagerClient.GetSecretValue.
+type GetSecretValueInput struct {
+	// SecretID is the name or ARN of the secret.
+	SecretID string
+}
+
+// GetSecretValueOutput is the output of SecretsManagerClient.GetSecretValue.
+type GetSecretValueOutput struct {
+	Name         string
+	SecretString string
+}
+
+// BatchGetSecretValueInput is the input of SecretsManagerClient.BatchGetSecretValue.
+type BatchGetSecretValueInput struct {
+	// Filters select the secrets, e.g. a filter with the key "name" selects the secrets with names starting with one of
+	// its values.
+	Filters []SecretsFilter
+	// NextToken is the NextToken of the previous page, or empty for the first page.
+	NextToken string
+}
+
+// SecretsFilter is a filter of BatchGetSecretValueInput.
+type SecretsFilter struct {
+	Key    string
+	Values []string
+}
+
+// BatchGetSecretValueOutput is the output of SecretsManagerClient.BatchGetSecretValue.
+type BatchGetSecretValueOutput struct {
+	SecretValues []SecretValueEntry
+	// Errors are the errors of the secrets that could not be read.
+	Errors []SecretValueError
+	// NextToken is the token of the next page, or empty for the last page.
+	NextToken string
+}
+
+// SecretValueEntry is the current value of a secret of BatchGetSecretValueOutput.
+type SecretValueEntry struct {
+	Name         string
+	SecretString string
+}
+
+// SecretValueError is an error of BatchGetSecretValueOutput.
+type SecretValueError struct {
+	SecretID  string
+	ErrorCode string
+	Message   string
+}
+
+// SecretsManagerSource is a Source of Secrets Manager secrets. Secrets are read by name, so a path of secrets is the
+// secrets with names starting with it, e.g. "orders/prod" for "orders/prod/db" and "orders/prod/stripe".
+type SecretsManagerSource struct {
+	client SecretsManagerClient
+}
+
+// NewSecretsManagerSource returns a SecretsManagerSource of the secrets read with client.
+func NewSecretsManagerSource(client SecretsManagerClient) *SecretsManagerSource {
+	return &SecretsManagerSource{client: client}
+}
+
+// Get returns the value of the secret name, a name or ARN.
+func (s *SecretsManagerSource) Get(ctx context.Context, name string) (string, error) {
+	output, err := s.client.GetSecretValue(ctx, &GetSecretValueInput{SecretID: name})
+	if err != nil {
+		return "", fmt.Errorf("config: getting secret %s: %w", name, notFound(err, errorCodeResourceNotFound))
+	}
+	return output.SecretString, nil
+}
+
+// GetPath returns the values of every secret under path, across every page of results. Secrets deleted while they are
+// read are left out.
+func (s *SecretsManagerSource) GetPath(ctx context.Context, path string) (map[string]string, error) {
+	prefix := pathPrefix(path)
+	values := map[string]string{}
+	input := &BatchGetSecretValueInput{Filters: []SecretsFilter{{Key: filterKeyName, Values: []string{prefix}}}}
+	for {
+		output, err := s.client.BatchGetSecretValue(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("config: getting secrets by path %s: %w", path, err)
+		}
+		var errs []error
+		for _, e := range output.Errors {
+			if e.ErrorCode != errorCodeResourceNotFound {
+				errs = append(errs, fmt.Errorf("%s: %s: %s", e.SecretID, e.ErrorCode, e.Message))
+			}
+		}
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("config: getting secrets by path %s: %w", path, errors.Join(errs...))
+		}
+		for _, secret := range output.SecretValues {
+			// The name filter is not case-sensitive
+			if strings.HasPrefix(secret.Name, prefix) {
+				values[secret.Name] = secret.SecretString
+			}
+		}
+		if output.NextToken == "" {
+			return values, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSecretsManager returns the secrets of a MemorySource in pages of pageSize, recording the inputs of batch gets.
+// Like Secrets Manager, its name filter is not case-sensitive.
+type fakeSecretsManager struct {
+	*MemorySource
+	pageSize int
+	inputs   []BatchGetSecretValueInput
+	errors   []SecretValueError
+	err      error
+}
+
+func (f *fakeSecretsManager) GetSecretValue(ctx context.Context, input *GetSecretValueInput) (*GetSecretValueOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	value, err := f.Get(ctx, input.SecretID)
+	if err != nil {
+		return nil, apiError{code: "ResourceNotFoundException"}
+	}
+	return &GetSecretValueOutput{Name: input.SecretID, SecretString: value}, nil
+}
+
+func (f *fakeSecretsManager) BatchGetSecretValue(_ context.Context, input *BatchGetSecretValueInput) (*BatchGetSecretValueOutput, error) {
+	f.inputs = append(f.inputs, *input)
+	if f.err != nil {
+		return nil, f.err
+	}
+	prefix := strings.ToLower(input.Filters[0].Values[0])
+	f.mu.RLock()
+	var names []string
+	for name := range f.values {
+		if strings.HasPrefix(strings.ToLower(name), prefix) {
+			names = append(names, name)
+		}
+	}
+	f.mu.RUnlock()
+	slices.Sort(names)
+
+	start, _ := strconv.Atoi(input.NextToken)
+	end := min(start+f.pageSize, len(names))
+	output := &BatchGetSecretValueOutput{Errors: f.errors}
+	for _, name := range names[start:end] {
+		value, _ := f.Get(context.Background(), name)
+		output.SecretValues = append(output.SecretValues, SecretValueEntry{Name: name, SecretString: value})
+	}
+	if end < len(names) {
+		output.NextToken = strconv.Itoa(end)
+	}
+	return output, nil
+}
+
+func TestSecretsManagerSource(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeSecretsManager{pageSize: 20, MemorySource: NewMemorySource(map[string]string{
+		"orders/prod/db":     `{"username":"orders","password":"hunter2"}`,
+		"orders/prod/stripe": "sk_test",
+		"orders/production":  "other",
+		"Orders/Prod/legacy": "other",
+	})}
+	source := NewSecretsManagerSource(client)
+
+	db, err := GetJSON[struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}](ctx, source, "orders/prod/db")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", db.Password)
+
+	values, err := source.GetPath(ctx, "orders/prod")
+	require.NoError(t, err)
+	assert.Equal(t, []BatchGetSecretValueInput{{Filters: []SecretsFilter{{Key: "name", Values: []string{"orders/prod/"}}}}}, client.inputs)
+	assert.Equal(t, []string{"orders/prod/db", "orders/prod/stripe"}, slices.Sorted(maps.Keys(values)))
+
+	_, err = source.Get(ctx, "orders/prod/missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	client.err = errors.New("access denied")
+	_, err = source.Get(ctx, "orders/prod/db")
+	assert.EqualError(t, err, "config: getting secret orders/prod/db: access denied")
+}
+
+func TestSecretsManagerSource_GetPath_pages(t *testing.T) {
+	secrets := map[string]string{}
+	for i := range 45 {
+		secrets["orders/prod/"+strconv.Itoa(i)] = strconv.Itoa(i)
+	}
+	client := &fakeSecretsManager{pageSize: 20, MemorySource: NewMemorySource(secrets)}
+
+	values, err := NewSecretsManagerSource(client).GetPath(context.Background(), "orders/prod/")
+
+	require.NoError(t, err)
+	assert.Equal(t, secrets, values)
+	var tokens []string
+	for _, input := range client.inputs {
+		tokens = append(tokens, input.NextToken)
+	}
+	assert.Equal(t, []string{"", "20", "40"}, tokens)
+}
+
+func TestSecretsManagerSource_GetPath_errors(t *testing.T) {
+	client := &fakeSecretsManager{pageSize: 20, MemorySource: NewMemorySource(map[string]string{"orders/prod/db": "db"})}
+	source := NewSecretsManagerSource(client)
+
+	client.errors = []SecretValueError{{SecretID: "orders/prod/deleted", ErrorCode: "ResourceNotFoundException"}}
+	values, err := source.GetPath(context.Background(), "orders/prod")
+	require.NoError(t, err, "deleted secrets left out")
+	assert.Equal(t, map[string]string{"orders/prod/db": "db"}, values)
+
+	client.errors = []SecretValueError{{SecretID: "orders/prod/kms", ErrorCode: "DecryptionFailure", Message: "key disabled"}}
+	_, err = source.GetPath(context.Background(), "orders/prod")
+	assert.EqualError(t, err, "config: getting secrets by path orders/prod: orders/prod/kms: DecryptionFailure: key disabled")
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned by a Source for a parameter or secret that does not exist, i.e. when SSM responds with a
+// ParameterNotFound or Secrets Manager with a ResourceNotFoundException.
+var ErrNotFound = errors.New("config: not found")
+
+// Source interface should be implemented for stores of configuration values, such as SSM Parameter Store (see
+// NewSSMSource) and Secrets Manager (see NewSecretsManagerSource), read at runtime rather than from environment
+// variables. Wrap it with NewCachedSource to pick up rotated values without a redeploy, and use a MemorySource in
+// tests.
+type Source interface {
+	// Get returns the value of the parameter or secret name, or an error wrapping ErrNotFound.
+	Get(ctx context.Context, name string) (string, error)
+	// GetPath returns the values of every parameter or secret under path, a hierarchy of names separated by "/" (e.g.
+	// "/orders/prod"), keyed by their full name.
+	GetPath(ctx context.Context, path string) (map[string]string, error)
+}
+
+// GetJSON returns the value of the parameter or secret name of source decoded from JSON into a T, e.g. a Secrets
+// Manager secret of database credentials.
+func GetJSON[T any](ctx context.Context, source Source, name string) (T, error) {
+	var v T
+	value, err := source.Get(ctx, name)
+	if err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return v, fmt.Errorf("config: decoding %s: %w", name, err)
+	}
+	return v, nil
+}
+
+// notFound returns err wrapped with ErrNotFound if it is an error of an AWS API, such as the smithy.APIError of the
+// SDK, with the error code code.
+func notFound(err error, code string) error {
+	var apiErr interface{ ErrorCode() string }
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == code {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
+
+// pathPrefix returns the prefix of the names under path.
+func pathPrefix(path string) string {
+	return strings.TrimSuffix(path, "/") + "/"
+}
+
+// MemorySource is a Source of values held in memory, for tests and local stand-ins of SSM Parameter Store and Secrets
+// Manager. It is safe for concurrent use.
+type MemorySource struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// NewMemorySource returns a MemorySource with a copy of values, keyed by name.
+func NewMemorySource(values map[string]string) *MemorySource {
+	copied := maps.Clone(values)
+	if copied == nil {
+		copied = map[string]string{}
+	}
+	return &MemorySource{values: copied}
+}
+
+// Get returns the value of name, or ErrNotFound.
+func (s *MemorySource) Get(_ context.Context, name string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.values[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return value, nil
+}
+
+// GetPath returns the values of every name under path.
+func (s *MemorySource) GetPath(_ context.Context, path string) (map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	prefix := pathPrefix(path)
+	values := map[string]string{}
+	for name, value := range s.values {
+		if strings.HasPrefix(name, prefix) {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// Set sets the value of name, e.g. to rotate it in a test.
+func (s *MemorySource) Set(name, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[name] = value
+}
+
+// Delete deletes name.
+func (s *MemorySource) Delete(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, name)
+}
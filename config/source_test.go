@@ -0,0 +1,57 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemorySource(t *testing.T) {
+	ctx := context.Background()
+	source := NewMemorySource(map[string]string{
+		"/orders/prod/table":       "orders",
+		"/orders/prod/db/password": "hunter2",
+		"/orders/production/table": "other",
+	})
+
+	value, err := source.Get(ctx, "/orders/prod/table")
+	require.NoError(t, err)
+	assert.Equal(t, "orders", value)
+
+	_, err = source.Get(ctx, "/orders/prod/missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	values, err := source.GetPath(ctx, "/orders/prod/")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"/orders/prod/table": "orders", "/orders/prod/db/password": "hunter2"}, values)
+
+	source.Set("/orders/prod/table", "orders-v2")
+	source.Delete("/orders/prod/db/password")
+	values, err = source.GetPath(ctx, "/orders/prod")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"/orders/prod/table": "orders-v2"}, values)
+}
+
+func TestGetJSON(t *testing.T) {
+	type credentials struct {
+		Username string `json:"username"`
+		Port     int    `json:"port"`
+	}
+	ctx := context.Background()
+	source := NewMemorySource(map[string]string{
+		"db":      `{"username":"orders","port":5432}`,
+		"invalid": `{"username":"orders","port":"5432"}`,
+	})
+
+	got, err := GetJSON[credentials](ctx, source, "db")
+	require.NoError(t, err)
+	assert.Equal(t, credentials{Username: "orders", Port: 5432}, got)
+
+	_, err = GetJSON[credentials](ctx, source, "invalid")
+	assert.ErrorContains(t, err, "config: decoding invalid: ")
+
+	_, err = GetJSON[credentials](ctx, source, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
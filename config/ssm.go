@@ -0,0 +1,94 @@
+package config
+
+import (
+	"context"
+	"fmt"
+)
+
+// errorCodeParameterNotFound is the error code of SSM for a parameter that does not exist.
+const errorCodeParameterNotFound = "ParameterNotFound"
+
+// SSMClient interface should be implemented for clients of SSM Parameter Store, usually by adapting an ssm.Client. Its
+// methods mirror the operations of the SDK, one request per call, and errors should be returned as the SDK returns them.
+type SSMClient interface {
+	// GetParameter returns the parameter of input.
+	GetParameter(ctx context.Context, input *GetParameterInput) (*GetParameterOutput, error)
+	// GetParametersByPath returns a page of the parameters under the path of input.
+	GetParametersByPath(ctx context.Context, input *GetParametersByPathInput) (*GetParametersByPathOutput, error)
+}
+
+// GetParameterInput is the input of SSMClient.GetParameter.
+type GetParameterInput struct {
+	// Name is the name or ARN of the parameter.
+	Name string
+	// WithDecryption decrypts the value of a SecureString parameter.
+	WithDecryption bool
+}
+
+// GetParameterOutput is the output of SSMClient.GetParameter.
+type GetParameterOutput struct {
+	Parameter Parameter
+}
+
+// GetParametersByPathInput is the input of SSMClient.GetParametersByPath.
+type GetParametersByPathInput struct {
+	// Path is the hierarchy of the parameters, e.g. "/orders/prod".
+	Path string
+	// Recursive returns the parameters of every level of the hierarchy below Path.
+	Recursive bool
+	// WithDecryption decrypts the values of SecureString parameters.
+	WithDecryption bool
+	// NextToken is the NextToken of the previous page, or empty for the first page.
+	NextToken string
+}
+
+// GetParametersByPathOutput is the output of SSMClient.GetParametersByPath.
+type GetParametersByPathOutput struct {
+	Parameters []Parameter
+	// NextToken is the token of the next page, or empty for the last page.
+	NextToken string
+}
+
+// Parameter is an SSM Parameter Store parameter.
+type Parameter struct {
+	Name  string
+	Value string
+}
+
+// SSMSource is a Source of SSM Parameter Store parameters. SecureString parameters are decrypted.
+type SSMSource struct {
+	client SSMClient
+}
+
+// NewSSMSource returns an SSMSource of the parameters read with client.
+func NewSSMSource(client SSMClient) *SSMSource {
+	return &SSMSource{client: client}
+}
+
+// Get returns the value of the parameter name.
+func (s *SSMSource) Get(ctx context.Context, name string) (string, error) {
+	output, err := s.client.GetParameter(ctx, &GetParameterInput{Name: name, WithDecryption: true})
+	if err != nil {
+		return "", fmt.Errorf("config: getting parameter %s: %w", name, notFound(err, errorCodeParameterNotFound))
+	}
+	return output.Parameter.Value, nil
+}
+
+// GetPath returns the values of every parameter under path, recursively, across every page of results.
+func (s *SSMSource) GetPath(ctx context.Context, path string) (map[string]string, error) {
+	values := map[string]string{}
+	input := &GetParametersByPathInput{Path: path, Recursive: true, WithDecryption: true}
+	for {
+		output, err := s.client.GetParametersByPath(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("config: getting parameters by path %s: %w", path, err)
+		}
+		for _, parameter := range output.Parameters {
+			values[parameter.Name] = parameter.Value
+		}
+		if output.NextToken == "" {
+			return values, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
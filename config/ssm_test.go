@@ -0,0 +1,117 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// apiError is an error of an AWS API, like the smithy.APIError of the SDK.
+type apiError struct {
+	code string
+}
+
+func (e apiError) Error() string {
+	return "api error " + e.code
+}
+
+func (e apiError) ErrorCode() string {
+	return e.code
+}
+
+// fakeSSM returns the parameters of a MemorySource in pages of pageSize, recording the inputs of each request.
+type fakeSSM struct {
+	*MemorySource
+	pageSize int
+	inputs   []any
+	err      error
+}
+
+func (f *fakeSSM) GetParameter(ctx context.Context, input *GetParameterInput) (*GetParameterOutput, error) {
+	f.inputs = append(f.inputs, *input)
+	if f.err != nil {
+		return nil, f.err
+	}
+	value, err := f.Get(ctx, input.Name)
+	if err != nil {
+		return nil, apiError{code: "ParameterNotFound"}
+	}
+	return &GetParameterOutput{Parameter: Parameter{Name: input.Name, Value: value}}, nil
+}
+
+func (f *fakeSSM) GetParametersByPath(ctx context.Context, input *GetParametersByPathInput) (*GetParametersByPathOutput, error) {
+	f.inputs = append(f.inputs, *input)
+	if f.err != nil {
+		return nil, f.err
+	}
+	values, _ := f.GetPath(ctx, input.Path)
+	names := slices.Sorted(maps.Keys(values))
+	start, _ := strconv.Atoi(input.NextToken)
+	end := min(start+f.pageSize, len(names))
+	output := &GetParametersByPathOutput{}
+	for _, name := range names[start:end] {
+		output.Parameters = append(output.Parameters, Parameter{Name: name, Value: values[name]})
+	}
+	if end < len(names) {
+		output.NextToken = strconv.Itoa(end)
+	}
+	return output, nil
+}
+
+func TestSSMSource(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeSSM{pageSize: 10, MemorySource: NewMemorySource(map[string]string{
+		"/orders/prod/table": "orders",
+		"/orders/prod/key":   "s3cr3t",
+	})}
+	source := NewSSMSource(client)
+
+	value, err := source.Get(ctx, "/orders/prod/key")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	values, err := source.GetPath(ctx, "/orders/prod")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"/orders/prod/table": "orders", "/orders/prod/key": "s3cr3t"}, values)
+	assert.Equal(t, []any{
+		GetParameterInput{Name: "/orders/prod/key", WithDecryption: true},
+		GetParametersByPathInput{Path: "/orders/prod", Recursive: true, WithDecryption: true},
+	}, client.inputs)
+
+	_, err = source.Get(ctx, "/orders/prod/missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorAs(t, err, &apiError{})
+
+	client.err = apiError{code: "AccessDeniedException"}
+	_, err = source.Get(ctx, "/orders/prod/key")
+	assert.NotErrorIs(t, err, ErrNotFound)
+
+	client.err = errors.New("throttled")
+	_, err = source.GetPath(ctx, "/orders/prod")
+	assert.EqualError(t, err, "config: getting parameters by path /orders/prod: throttled")
+}
+
+func TestSSMSource_GetPath_pages(t *testing.T) {
+	parameters := map[string]string{}
+	for i := range 25 {
+		parameters["/orders/prod/"+strconv.Itoa(i)] = strings.Repeat("v", i)
+	}
+	client := &fakeSSM{pageSize: 10, MemorySource: NewMemorySource(parameters)}
+
+	values, err := NewSSMSource(client).GetPath(context.Background(), "/orders/prod")
+
+	require.NoError(t, err)
+	assert.Equal(t, parameters, values)
+	assert.Equal(t, []any{
+		GetParametersByPathInput{Path: "/orders/prod", Recursive: true, WithDecryption: true},
+		GetParametersByPathInput{Path: "/orders/prod", Recursive: true, WithDecryption: true, NextToken: "10"},
+		GetParametersByPathInput{Path: "/orders/prod", Recursive: true, WithDecryption: true, NextToken: "20"},
+	}, client.inputs)
+}
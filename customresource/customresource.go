@@ -0,0 +1,271 @@
+// Package customresource implements CloudFormation custom resources with typed handlers, taking care of the response
+// protocol: decoding the resource properties, choosing the physical resource id and sending the response to the
+// pre-signed URL of the request, including when the handler fails, panics or runs out of time.
+//
+//	provider := customresource.NewProvider[BucketPolicyProperties](logger, bucketPolicyHandler)
+//
+//	lambda.Start[cfn.Event](provider, middleware.CommonCloudFormation(logger))
+package customresource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/cfn"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+const (
+	defaultTimeoutSafetyMargin = 5 * time.Second
+
+	// MaxResponseSize is the maximum size in bytes of a response accepted by CloudFormation. A response exceeding it,
+	// usually because of a large Data map, is replaced with a FAILED response (see ErrResponseTooLarge).
+	MaxResponseSize = 4096
+	// maxReasonLength leaves room within MaxResponseSize for the rest of a FAILED response.
+	maxReasonLength = 1024
+)
+
+var (
+	// ErrUnknownRequestType is the reason for a FAILED response to a request that is not a Create, Update or Delete.
+	ErrUnknownRequestType = errors.New("unknown request type")
+	// ErrInvalidProperties is the reason for a FAILED response to a request whose resource properties cannot be
+	// decoded into the type of the handler.
+	ErrInvalidProperties = errors.New("invalid resource properties")
+	// ErrResponseTooLarge is the reason for a FAILED response replacing a response exceeding MaxResponseSize.
+	ErrResponseTooLarge = errors.New("response too large")
+	// ErrTimeout is the reason for a FAILED response to a request the handler did not complete before its deadline.
+	ErrTimeout = errors.New("handler did not complete before its deadline")
+)
+
+// Request [P any] is a custom resource request with its resource properties decoded into type P.
+type Request[P any] struct {
+	cfn.Event
+
+	// Properties are the decoded resource properties of the request. The raw properties remain available as
+	// Event.ResourceProperties.
+	Properties P
+	// OldProperties are the decoded resource properties before an Update, and the zero value for other requests.
+	OldProperties P
+}
+
+// Result is the outcome of a successful custom resource request.
+type Result struct {
+	// PhysicalResourceID identifies the resource. If it is empty, the RequestID is used for a Create and the existing
+	// physical resource id for an Update or Delete. Returning a different id from an Update replaces the resource:
+	// CloudFormation later sends a Delete for the old id.
+	PhysicalResourceID string
+	// Data are the outputs of the resource, available to the template with Fn::GetAtt.
+	Data map[string]any
+	// NoEcho masks Data when it is retrieved with Fn::GetAtt.
+	NoEcho bool
+}
+
+// Handler [P any] interface should be implemented for custom resources with resource properties of type P.
+type Handler[P any] interface {
+	// Create creates the resource.
+	Create(ctx context.Context, request Request[P]) (Result, error)
+	// Update updates the resource from request.OldProperties to request.Properties.
+	Update(ctx context.Context, request Request[P]) (Result, error)
+	// Delete deletes the resource. It is also called for a resource whose Create failed, with the RequestID of that
+	// Create as the physical resource id, so it should succeed when there is nothing to delete.
+	Delete(ctx context.Context, request Request[P]) (Result, error)
+}
+
+type providerOptions struct {
+	sender              Sender
+	timeoutSafetyMargin time.Duration
+}
+
+// ProviderOption configures NewProvider.
+type ProviderOption func(*providerOptions)
+
+// WithSender sets the Sender of responses. Defaults to an HTTPSender using http.DefaultClient.
+func WithSender(sender Sender) ProviderOption {
+	return func(o *providerOptions) {
+		o.sender = sender
+	}
+}
+
+// WithTimeoutSafetyMargin sets how long before the Lambda deadline a FAILED response is sent for a handler that has not
+// returned, leaving enough time to send it before Lambda stops the invocation. Defaults to 5s.
+func WithTimeoutSafetyMargin(d time.Duration) ProviderOption {
+	return func(o *providerOptions) {
+		o.timeoutSafetyMargin = d
+	}
+}
+
+// Provider [P any] handles custom resource requests with a Handler and sends their responses to CloudFormation. It
+// implements lambda.Handler[cfn.Event].
+//
+// The resource properties of each request are decoded as JSON into P. CloudFormation passes every scalar property as a
+// string, so numeric and boolean fields of P need the ",string" option of their json tag.
+//
+// A response is sent for every request, so a stack never waits for the hour CloudFormation allows a custom resource:
+// a FAILED response is sent when the properties cannot be decoded, or when the handler returns an error, panics or has
+// not returned shortly before the Lambda deadline (see WithTimeoutSafetyMargin). Handle only returns an error when the
+// response cannot be sent, so Lambda retries the request.
+type Provider[P any] struct {
+	logger  *slog.Logger
+	handler Handler[P]
+	opts    providerOptions
+}
+
+// NewProvider returns a Provider handling requests with handler, logging failed requests to logger.
+func NewProvider[P any](logger *slog.Logger, handler Handler[P], options ...ProviderOption) *Provider[P] {
+	opts := providerOptions{
+		sender:              NewHTTPSender(nil),
+		timeoutSafetyMargin: defaultTimeoutSafetyMargin,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+	return &Provider[P]{
+		logger:  logger,
+		handler: handler,
+		opts:    opts,
+	}
+}
+
+// Handle handles event with the Handler and sends the response to event.ResponseURL.
+func (p *Provider[P]) Handle(ctx context.Context, event cfn.Event) error {
+	result, err := p.run(ctx, event)
+
+	response := cfn.Response{
+		Status:             cfn.StatusSuccess,
+		RequestID:          event.RequestID,
+		LogicalResourceID:  event.LogicalResourceID,
+		StackID:            event.StackID,
+		PhysicalResourceID: physicalResourceID(event, result),
+		NoEcho:             result.NoEcho,
+		Data:               result.Data,
+	}
+	if err == nil {
+		err = checkResponseSize(response)
+	}
+	if err != nil {
+		response.PhysicalResourceID = physicalResourceID(event, Result{})
+		p.logger.ErrorContext(ctx, "Custom resource failed",
+			slog.String("request_type", string(event.RequestType)),
+			slog.String("logical_resource_id", event.LogicalResourceID),
+			slog.String("physical_resource_id", response.PhysicalResourceID),
+			slog.String("error", err.Error()),
+		)
+		response.Status = cfn.StatusFailed
+		response.Reason = reason(err)
+		response.NoEcho = false
+		response.Data = nil
+	}
+
+	if err := p.opts.sender.Send(ctx, event.ResponseURL, response); err != nil {
+		return fmt.Errorf("sending %s response: %w", response.Status, err)
+	}
+	return nil
+}
+
+// run calls the handler for event, returning an error if it panics or has not returned by the deadline.
+func (p *Provider[P]) run(ctx context.Context, event cfn.Event) (Result, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-p.opts.timeoutSafetyMargin))
+		defer cancel()
+	}
+
+	type outcome struct {
+		result Result
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+		result, err := p.handle(ctx, event)
+		done <- outcome{result: result, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-ctx.Done():
+		return Result{}, ErrTimeout
+	}
+}
+
+func (p *Provider[P]) handle(ctx context.Context, event cfn.Event) (Result, error) {
+	request := Request[P]{Event: event}
+	if err := decode(event.ResourceProperties, &request.Properties); err != nil {
+		return Result{}, fmt.Errorf("%w: %w", ErrInvalidProperties, err)
+	}
+
+	switch event.RequestType {
+	case cfn.RequestCreate:
+		return p.handler.Create(ctx, request)
+	case cfn.RequestUpdate:
+		if err := decode(event.OldResourceProperties, &request.OldProperties); err != nil {
+			return Result{}, fmt.Errorf("%w: old properties: %w", ErrInvalidProperties, err)
+		}
+		return p.handler.Update(ctx, request)
+	case cfn.RequestDelete:
+		return p.handler.Delete(ctx, request)
+	default:
+		return Result{}, fmt.Errorf("%w: %q", ErrUnknownRequestType, event.RequestType)
+	}
+}
+
+func decode(properties map[string]any, v any) error {
+	b, err := json.Marshal(properties)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// physicalResourceID returns the physical resource id of the response to event. A Delete always responds with the id
+// it was sent, as CloudFormation rejects any other.
+func physicalResourceID(event cfn.Event, result Result) string {
+	switch {
+	case event.RequestType == cfn.RequestDelete:
+		return event.PhysicalResourceID
+	case result.PhysicalResourceID != "":
+		return result.PhysicalResourceID
+	case event.RequestType == cfn.RequestCreate:
+		return event.RequestID
+	default:
+		return event.PhysicalResourceID
+	}
+}
+
+func checkResponseSize(response cfn.Response) error {
+	b, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("encoding response: %w", err)
+	}
+	if len(b) > MaxResponseSize {
+		return fmt.Errorf("%w: %d bytes, exceeding %d bytes", ErrResponseTooLarge, len(b), MaxResponseSize)
+	}
+	return nil
+}
+
+// reason returns the reason for a FAILED response with err, pointing at the log stream of the function.
+func reason(err error) string {
+	r := err.Error()
+	if len(r) > maxReasonLength {
+		// Truncate at the start of a rune, so a multi-byte character is not split
+		i := maxReasonLength
+		for i > 0 && !utf8.RuneStart(r[i]) {
+			i--
+		}
+		r = r[:i]
+	}
+	if lambdacontext.LogStreamName != "" {
+		r += " (see CloudWatch log stream " + lambdacontext.LogStreamName + ")"
+	}
+	return r
+}
@@ -0,0 +1,290 @@
+package customresource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-lambda-go/cfn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bucketProperties struct {
+	BucketName string `json:"BucketName"`
+	Versioned  bool   `json:"Versioned,string"`
+}
+
+type testHandler struct {
+	create func(context.Context, Request[bucketProperties]) (Result, error)
+	update func(context.Context, Request[bucketProperties]) (Result, error)
+	delete func(context.Context, Request[bucketProperties]) (Result, error)
+}
+
+func (h testHandler) Create(ctx context.Context, request Request[bucketProperties]) (Result, error) {
+	return h.create(ctx, request)
+}
+
+func (h testHandler) Update(ctx context.Context, request Request[bucketProperties]) (Result, error) {
+	return h.update(ctx, request)
+}
+
+func (h testHandler) Delete(ctx context.Context, request Request[bucketProperties]) (Result, error) {
+	return h.delete(ctx, request)
+}
+
+type recordingSender struct {
+	url       string
+	responses []cfn.Response
+	err       error
+}
+
+func (s *recordingSender) Send(_ context.Context, url string, response cfn.Response) error {
+	s.url = url
+	s.responses = append(s.responses, response)
+	return s.err
+}
+
+func newEvent(requestType cfn.RequestType) cfn.Event {
+	event := cfn.Event{
+		RequestType:        requestType,
+		RequestID:          "request-id-123",
+		ResponseURL:        "https://example.com/response",
+		ResourceType:       "Custom::Bucket",
+		LogicalResourceID:  "Bucket",
+		StackID:            "arn:aws:cloudformation:eu-west-2:123456789012:stack/app/1",
+		ResourceProperties: map[string]any{"ServiceToken": "arn", "BucketName": "new", "Versioned": "true"},
+	}
+	if requestType != cfn.RequestCreate {
+		event.PhysicalResourceID = "bucket-old"
+	}
+	if requestType == cfn.RequestUpdate {
+		event.OldResourceProperties = map[string]any{"ServiceToken": "arn", "BucketName": "old", "Versioned": "false"}
+	}
+	return event
+}
+
+func ok(result Result) func(context.Context, Request[bucketProperties]) (Result, error) {
+	return func(context.Context, Request[bucketProperties]) (Result, error) {
+		return result, nil
+	}
+}
+
+func TestProvider_Handle(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   cfn.Event
+		handler testHandler
+		want    cfn.Response
+	}{
+		{
+			name:  "create, physical resource id and data returned, success",
+			event: newEvent(cfn.RequestCreate),
+			handler: testHandler{create: func(_ context.Context, r Request[bucketProperties]) (Result, error) {
+				return Result{
+					PhysicalResourceID: "bucket-" + r.Properties.BucketName,
+					Data:               map[string]any{"Versioned": r.Properties.Versioned},
+				}, nil
+			}},
+			want: cfn.Response{
+				Status:             cfn.StatusSuccess,
+				PhysicalResourceID: "bucket-new",
+				Data:               map[string]any{"Versioned": true},
+			},
+		},
+		{
+			name:    "create, no physical resource id returned, request id used",
+			event:   newEvent(cfn.RequestCreate),
+			handler: testHandler{create: ok(Result{})},
+			want:    cfn.Response{Status: cfn.StatusSuccess, PhysicalResourceID: "request-id-123"},
+		},
+		{
+			name:  "update, old and new properties decoded, existing physical resource id used",
+			event: newEvent(cfn.RequestUpdate),
+			handler: testHandler{update: func(_ context.Context, r Request[bucketProperties]) (Result, error) {
+				return Result{Data: map[string]any{"From": r.OldProperties.BucketName, "To": r.Properties.BucketName}}, nil
+			}},
+			want: cfn.Response{
+				Status:             cfn.StatusSuccess,
+				PhysicalResourceID: "bucket-old",
+				Data:               map[string]any{"From": "old", "To": "new"},
+			},
+		},
+		{
+			name:    "update, new physical resource id returned, resource replaced",
+			event:   newEvent(cfn.RequestUpdate),
+			handler: testHandler{update: ok(Result{PhysicalResourceID: "bucket-new", NoEcho: true})},
+			want:    cfn.Response{Status: cfn.StatusSuccess, PhysicalResourceID: "bucket-new", NoEcho: true},
+		},
+		{
+			name:    "delete, different physical resource id returned, existing physical resource id used",
+			event:   newEvent(cfn.RequestDelete),
+			handler: testHandler{delete: ok(Result{PhysicalResourceID: "other"})},
+			want:    cfn.Response{Status: cfn.StatusSuccess, PhysicalResourceID: "bucket-old"},
+		},
+		{
+			name:  "update, handler returns error with new physical resource id, failed with existing physical resource id",
+			event: newEvent(cfn.RequestUpdate),
+			handler: testHandler{update: func(context.Context, Request[bucketProperties]) (Result, error) {
+				return Result{PhysicalResourceID: "bucket-new", Data: map[string]any{"a": "b"}}, errors.New("bucket exists")
+			}},
+			want: cfn.Response{Status: cfn.StatusFailed, PhysicalResourceID: "bucket-old", Reason: "bucket exists"},
+		},
+		{
+			name:  "create, handler panics, failed",
+			event: newEvent(cfn.RequestCreate),
+			handler: testHandler{create: func(context.Context, Request[bucketProperties]) (Result, error) {
+				panic("boom")
+			}},
+			want: cfn.Response{Status: cfn.StatusFailed, PhysicalResourceID: "request-id-123", Reason: "panic: boom"},
+		},
+		{
+			name: "create, invalid properties, failed without calling handler",
+			event: func() cfn.Event {
+				e := newEvent(cfn.RequestCreate)
+				e.ResourceProperties["Versioned"] = "maybe"
+				return e
+			}(),
+			handler: testHandler{},
+			want: cfn.Response{
+				Status:             cfn.StatusFailed,
+				PhysicalResourceID: "request-id-123",
+				Reason:             `invalid resource properties: json: cannot unmarshal string "maybe" into Go struct field bucketProperties.Versioned of type bool: invalid syntax`,
+			},
+		},
+		{
+			name: "unknown request type, failed",
+			event: func() cfn.Event {
+				e := newEvent(cfn.RequestCreate)
+				e.RequestType = "Import"
+				e.PhysicalResourceID = "bucket-old"
+				return e
+			}(),
+			handler: testHandler{},
+			want: cfn.Response{
+				Status:             cfn.StatusFailed,
+				PhysicalResourceID: "bucket-old",
+				Reason:             `unknown request type: "Import"`,
+			},
+		},
+		{
+			name:    "create, data exceeds max response size, failed without data",
+			event:   newEvent(cfn.RequestCreate),
+			handler: testHandler{create: ok(Result{Data: map[string]any{"Policy": strings.Repeat("a", MaxResponseSize)}})},
+			want: cfn.Response{
+				Status:             cfn.StatusFailed,
+				PhysicalResourceID: "request-id-123",
+				Reason:             fmt.Sprintf("response too large: 4303 bytes, exceeding %d bytes", MaxResponseSize),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &recordingSender{}
+			provider := NewProvider[bucketProperties](slog.New(slog.DiscardHandler), tt.handler, WithSender(sender))
+
+			err := provider.Handle(context.Background(), tt.event)
+
+			require.NoError(t, err)
+			assert.Equal(t, "https://example.com/response", sender.url)
+			tt.want.RequestID = "request-id-123"
+			tt.want.LogicalResourceID = "Bucket"
+			tt.want.StackID = "arn:aws:cloudformation:eu-west-2:123456789012:stack/app/1"
+			assert.Equal(t, []cfn.Response{tt.want}, sender.responses)
+		})
+	}
+}
+
+func TestProvider_Handle_timeout(t *testing.T) {
+	sender := &recordingSender{}
+	release := make(chan struct{})
+	defer close(release)
+	handler := testHandler{create: func(context.Context, Request[bucketProperties]) (Result, error) {
+		<-release
+		return Result{}, nil
+	}}
+	provider := NewProvider[bucketProperties](slog.New(slog.DiscardHandler), handler,
+		WithSender(sender),
+		WithTimeoutSafetyMargin(time.Second),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second+50*time.Millisecond)
+	defer cancel()
+
+	err := provider.Handle(ctx, newEvent(cfn.RequestCreate))
+
+	require.NoError(t, err)
+	require.Len(t, sender.responses, 1)
+	assert.Equal(t, cfn.StatusFailed, sender.responses[0].Status)
+	assert.Equal(t, ErrTimeout.Error(), sender.responses[0].Reason)
+	assert.NoError(t, ctx.Err(), "response sent before the Lambda deadline")
+}
+
+func TestProvider_Handle_sendError(t *testing.T) {
+	sender := &recordingSender{err: errors.New("connection refused")}
+	provider := NewProvider[bucketProperties](slog.New(slog.DiscardHandler), testHandler{create: ok(Result{})},
+		WithSender(sender),
+	)
+
+	err := provider.Handle(context.Background(), newEvent(cfn.RequestCreate))
+
+	assert.EqualError(t, err, "sending SUCCESS response: connection refused")
+}
+
+func TestProvider_Handle_httpSender(t *testing.T) {
+	var got cfn.Response
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+	event := newEvent(cfn.RequestDelete)
+	event.ResponseURL = server.URL
+	provider := NewProvider[bucketProperties](slog.New(slog.DiscardHandler), testHandler{delete: ok(Result{})},
+		WithSender(NewHTTPSender(server.Client())),
+	)
+
+	err := provider.Handle(context.Background(), event)
+
+	require.NoError(t, err)
+	assert.Equal(t, cfn.StatusSuccess, got.Status)
+	assert.Equal(t, "bucket-old", got.PhysicalResourceID)
+}
+
+func Test_reason(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "short, unchanged",
+			err:  errors.New("bucket exists"),
+			want: "bucket exists",
+		},
+		{
+			name: "long, truncated",
+			err:  errors.New(strings.Repeat("a", maxReasonLength+1)),
+			want: strings.Repeat("a", maxReasonLength),
+		},
+		{
+			name: "long multi-byte, truncated to a rune boundary",
+			err:  errors.New("a" + strings.Repeat("é", maxReasonLength/2)),
+			want: "a" + strings.Repeat("é", maxReasonLength/2-1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reason(tt.err)
+
+			assert.Equal(t, tt.want, got)
+			assert.True(t, utf8.ValidString(got))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package customresource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/cfn"
+)
+
+// Sender interface should be implemented for senders of custom resource responses to CloudFormation.
+type Sender interface {
+	// Send sends response to the pre-signed url of the request.
+	Send(ctx context.Context, url string, response cfn.Response) error
+}
+
+// HTTPSender sends responses to the pre-signed S3 url of each request with an HTTP PUT.
+type HTTPSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender returns an HTTPSender sending responses with client, or http.DefaultClient if client is nil.
+func NewHTTPSender(client *http.Client) *HTTPSender {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPSender{client: client}
+}
+
+// Send PUTs response as JSON to url, returning an error unless the response status is 200 OK.
+func (s *HTTPSender) Send(ctx context.Context, url string, response cfn.Response) error {
+	body, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("encoding response: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+
+	// No Content-Type is set: the url is signed without one, so S3 rejects a request that sets it.
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("unexpected status %d: %s", res.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package customresource
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/cfn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPSender_Send(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "200 ok, no error",
+			status:  http.StatusOK,
+			wantErr: assert.NoError,
+		},
+		{
+			name:   "403 forbidden, error with body",
+			status: http.StatusForbidden,
+			wantErr: func(t assert.TestingT, err error, _ ...any) bool {
+				return assert.EqualError(t, err, "unexpected status 403: <Error>SignatureDoesNotMatch</Error>")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotMethod, gotContentType, gotQuery string
+			var gotBody cfn.Response
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotMethod, gotContentType, gotQuery = r.Method, r.Header.Get("Content-Type"), r.URL.RawQuery
+				b, _ := io.ReadAll(r.Body)
+				_ = json.Unmarshal(b, &gotBody)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte("<Error>SignatureDoesNotMatch</Error>\n"))
+			}))
+			defer server.Close()
+			response := cfn.Response{Status: cfn.StatusSuccess, RequestID: "request-id-123", PhysicalResourceID: "bucket"}
+
+			err := NewHTTPSender(server.Client()).Send(context.Background(), server.URL+"/response?X-Amz-Signature=abc", response)
+
+			tt.wantErr(t, err)
+			assert.Equal(t, http.MethodPut, gotMethod)
+			assert.Empty(t, gotContentType)
+			assert.Equal(t, "X-Amz-Signature=abc", gotQuery)
+			assert.Equal(t, response, gotBody)
+		})
+	}
+}
+
+func TestHTTPSender_Send_requestError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.Close()
+
+	err := NewHTTPSender(nil).Send(context.Background(), server.URL, cfn.Response{Status: cfn.StatusSuccess})
+
+	assert.Error(t, err)
+}
+
+func TestHTTPSender_Send_invalidURL(t *testing.T) {
+	err := NewHTTPSender(nil).Send(context.Background(), "://bucket", cfn.Response{Status: cfn.StatusSuccess})
+
+	assert.ErrorContains(t, err, "creating request")
+}
+
+func TestNewHTTPSender(t *testing.T) {
+	assert.Same(t, http.DefaultClient, NewHTTPSender(nil).client)
+
+	client := &http.Client{}
+	assert.Same(t, client, NewHTTPSender(client).client)
+}
@@ -0,0 +1,76 @@
+package dynamodbstreams
+
+import (
+	"bytes"
+	"math/big"
+	"slices"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// ChangedAttributes returns the names of the top-level attributes added, removed or changed between oldImage and
+// newImage, sorted. Set attributes are compared regardless of order.
+func ChangedAttributes(oldImage, newImage map[string]events.DynamoDBAttributeValue) []string {
+	var changed []string
+	for name, oldValue := range oldImage {
+		if newValue, ok := newImage[name]; !ok || !Equal(oldValue, newValue) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range newImage {
+		if _, ok := oldImage[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// Equal reports whether a and b are the same attribute value. Set attribute values are compared regardless of order,
+// and numbers by value, so "1", "1.0" and "1E0" are equal.
+func Equal(a, b events.DynamoDBAttributeValue) bool {
+	if a.IsNull() || b.IsNull() {
+		return a.IsNull() == b.IsNull()
+	}
+	if a.DataType() != b.DataType() {
+		return false
+	}
+
+	switch a.DataType() {
+	case events.DataTypeString:
+		return a.String() == b.String()
+	case events.DataTypeNumber:
+		return canonicalNumber(a.Number()) == canonicalNumber(b.Number())
+	case events.DataTypeBoolean:
+		return a.Boolean() == b.Boolean()
+	case events.DataTypeBinary:
+		return bytes.Equal(a.Binary(), b.Binary())
+	case events.DataTypeList:
+		return slices.EqualFunc(a.List(), b.List(), Equal)
+	case events.DataTypeMap:
+		return len(ChangedAttributes(a.Map(), b.Map())) == 0
+	case events.DataTypeStringSet:
+		return equalSets(a.StringSet(), b.StringSet())
+	case events.DataTypeNumberSet:
+		return equalSets(mapSlice(a.NumberSet(), canonicalNumber), mapSlice(b.NumberSet(), canonicalNumber))
+	case events.DataTypeBinarySet:
+		return equalSets(mapSlice(a.BinarySet(), func(b []byte) string { return string(b) }),
+			mapSlice(b.BinarySet(), func(b []byte) string { return string(b) }))
+	default:
+		return false
+	}
+}
+
+func equalSets(a, b []string) bool {
+	return slices.Equal(slices.Sorted(slices.Values(a)), slices.Sorted(slices.Values(b)))
+}
+
+// canonicalNumber returns the exact value of the DynamoDB number n as a fraction in lowest terms, the same for every way
+// of writing the number, or n itself when it is not a number.
+func canonicalNumber(n string) string {
+	r, ok := new(big.Rat).SetString(n)
+	if !ok {
+		return n
+	}
+	return r.RatString()
+}
@@ -0,0 +1,61 @@
+package dynamodbstreams
+
+import (
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangedAttributes(t *testing.T) {
+	oldImage := map[string]events.DynamoDBAttributeValue{
+		"pk":      events.NewStringAttribute("1"),
+		"name":    events.NewStringAttribute("Jane"),
+		"tags":    events.NewStringSetAttribute([]string{"a", "b"}),
+		"removed": events.NewBooleanAttribute(true),
+		"address": events.NewMapAttribute(map[string]events.DynamoDBAttributeValue{
+			"postcode": events.NewStringAttribute("AB1 2CD"),
+		}),
+		"scores": events.NewListAttribute([]events.DynamoDBAttributeValue{events.NewNumberAttribute("1")}),
+	}
+	newImage := map[string]events.DynamoDBAttributeValue{
+		"pk":    events.NewStringAttribute("1"),
+		"name":  events.NewStringAttribute("Janet"),
+		"tags":  events.NewStringSetAttribute([]string{"b", "a"}),
+		"added": events.NewNumberAttribute("1"),
+		"address": events.NewMapAttribute(map[string]events.DynamoDBAttributeValue{
+			"postcode": events.NewStringAttribute("EF3 4GH"),
+		}),
+		"scores": events.NewListAttribute([]events.DynamoDBAttributeValue{events.NewNumberAttribute("1")}),
+	}
+
+	assert.Equal(t, []string{"added", "address", "name", "removed"}, ChangedAttributes(oldImage, newImage))
+	assert.Empty(t, ChangedAttributes(oldImage, oldImage))
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b events.DynamoDBAttributeValue
+		want bool
+	}{
+		{"same string", events.NewStringAttribute("a"), events.NewStringAttribute("a"), true},
+		{"different type", events.NewStringAttribute("1"), events.NewNumberAttribute("1"), false},
+		{"null and null", events.NewNullAttribute(), events.NewNullAttribute(), true},
+		{"null and string", events.NewNullAttribute(), events.NewStringAttribute(""), false},
+		{"binary", events.NewBinaryAttribute([]byte{1}), events.NewBinaryAttribute([]byte{1}), true},
+		{"binary set any order", events.NewBinarySetAttribute([][]byte{{1}, {2}}), events.NewBinarySetAttribute([][]byte{{2}, {1}}), true},
+		{"number trailing zero", events.NewNumberAttribute("1"), events.NewNumberAttribute("1.0"), true},
+		{"number exponent", events.NewNumberAttribute("1E2"), events.NewNumberAttribute("100"), true},
+		{"number precision", events.NewNumberAttribute("0.10000000000000000000000000000000000001"), events.NewNumberAttribute("0.1"), false},
+		{"number set", events.NewNumberSetAttribute([]string{"1"}), events.NewNumberSetAttribute([]string{"2"}), false},
+		{"number set by value", events.NewNumberSetAttribute([]string{"1E2", "1"}), events.NewNumberSetAttribute([]string{"1.0", "100"}), true},
+		{"list order matters", events.NewListAttribute([]events.DynamoDBAttributeValue{events.NewBooleanAttribute(true), events.NewBooleanAttribute(false)}),
+			events.NewListAttribute([]events.DynamoDBAttributeValue{events.NewBooleanAttribute(false), events.NewBooleanAttribute(true)}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Equal(tt.a, tt.b))
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Package dynamodbstreams processes DynamoDB stream events: decoding the images of each record into Go structs,
+// handling each record by its operation, and reporting partial batch failures.
+package dynamodbstreams
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Record [T any] is a DynamoDB stream record with its images decoded into type T.
+type Record[T any] struct {
+	events.DynamoDBEventRecord
+
+	// Old is the image of the item before it was modified or removed. It is nil for inserts, and for streams that do
+	// not include old images.
+	Old *T
+	// New is the image of the item after it was inserted or modified. It is nil for removals, and for streams that do
+	// not include new images.
+	New *T
+}
+
+// ChangedAttributes returns the names of the top-level attributes added, removed or changed by the record, sorted. It
+// is only meaningful for streams that include both old and new images.
+func (r Record[T]) ChangedAttributes() []string {
+	return ChangedAttributes(r.Change.OldImage, r.Change.NewImage)
+}
+
+// Handler [T any] interface should be implemented for handlers of DynamoDB stream records of items decoded into type T.
+type Handler[T any] interface {
+	// OnInsert handles a record of an item inserted into the table.
+	OnInsert(ctx context.Context, record Record[T]) error
+	// OnModify handles a record of an item modified in the table.
+	OnModify(ctx context.Context, record Record[T]) error
+	// OnRemove handles a record of an item removed from the table.
+	OnRemove(ctx context.Context, record Record[T]) error
+}
+
+// HandlerFuncs [T any] is an adapter to allow the use of ordinary functions as a Handler. Records of operations
+// without a func are ignored.
+type HandlerFuncs[T any] struct {
+	Insert func(ctx context.Context, record Record[T]) error
+	Modify func(ctx context.Context, record Record[T]) error
+	Remove func(ctx context.Context, record Record[T]) error
+}
+
+// OnInsert calls h.Insert, if set.
+func (h HandlerFuncs[T]) OnInsert(ctx context.Context, record Record[T]) error {
+	if h.Insert == nil {
+		return nil
+	}
+	return h.Insert(ctx, record)
+}
+
+// OnModify calls h.Modify, if set.
+func (h HandlerFuncs[T]) OnModify(ctx context.Context, record Record[T]) error {
+	if h.Modify == nil {
+		return nil
+	}
+	return h.Modify(ctx, record)
+}
+
+// OnRemove calls h.Remove, if set.
+func (h HandlerFuncs[T]) OnRemove(ctx context.Context, record Record[T]) error {
+	if h.Remove == nil {
+		return nil
+	}
+	return h.Remove(ctx, record)
+}
+
+// Processor [T any] handles each record of a DynamoDB stream event with a Handler, reporting partial batch failures.
+// It implements lambda.HandlerWithResponse[events.DynamoDBEvent, events.DynamoDBEventResponse]; the event source
+// mapping must have ReportBatchItemFailures enabled.
+//
+// Records are handled in order. Lambda retries a stream from the first failed record, so processing stops at the
+// first record that fails to decode or is rejected by the handler, which is reported as the batch item failure.
+type Processor[T any] struct {
+	logger  *slog.Logger
+	handler Handler[T]
+}
+
+// NewProcessor returns a Processor handling records with handler, logging failed records to logger.
+func NewProcessor[T any](logger *slog.Logger, handler Handler[T]) *Processor[T] {
+	return &Processor[T]{
+		logger:  logger,
+		handler: handler,
+	}
+}
+
+// Handle handles each record of event in order, stopping at the first failure.
+func (p *Processor[T]) Handle(ctx context.Context, event events.DynamoDBEvent) (events.DynamoDBEventResponse, error) {
+	response := events.DynamoDBEventResponse{BatchItemFailures: []events.DynamoDBBatchItemFailure{}}
+	for i, r := range event.Records {
+		if err := p.handle(ctx, r); err != nil {
+			p.logger.ErrorContext(ctx, "Record failed",
+				slog.String("event_id", r.EventID),
+				slog.String("event_name", r.EventName),
+				slog.String("sequence_number", r.Change.SequenceNumber),
+				slog.Int("remaining_records", len(event.Records)-i-1),
+				slog.String("error", err.Error()),
+			)
+			response.BatchItemFailures = append(response.BatchItemFailures, events.DynamoDBBatchItemFailure{
+				ItemIdentifier: r.Change.SequenceNumber,
+			})
+			break
+		}
+	}
+	return response, nil
+}
+
+func (p *Processor[T]) handle(ctx context.Context, r events.DynamoDBEventRecord) error {
+	record, err := decodeRecord[T](r)
+	if err != nil {
+		return err
+	}
+
+	switch events.DynamoDBOperationType(r.EventName) {
+	case events.DynamoDBOperationTypeInsert:
+		return p.handler.OnInsert(ctx, record)
+	case events.DynamoDBOperationTypeModify:
+		return p.handler.OnModify(ctx, record)
+	case events.DynamoDBOperationTypeRemove:
+		return p.handler.OnRemove(ctx, record)
+	default:
+		return fmt.Errorf("unknown event name %q", r.EventName)
+	}
+}
+
+func decodeRecord[T any](r events.DynamoDBEventRecord) (Record[T], error) {
+	record := Record[T]{DynamoDBEventRecord: r}
+	if len(r.Change.OldImage) > 0 {
+		record.Old = new(T)
+		if err := UnmarshalMap(r.Change.OldImage, record.Old); err != nil {
+			return record, fmt.Errorf("decoding old image: %w", err)
+		}
+	}
+	if len(r.Change.NewImage) > 0 {
+		record.New = new(T)
+		if err := UnmarshalMap(r.Change.NewImage, record.New); err != nil {
+			return record, fmt.Errorf("decoding new image: %w", err)
+		}
+	}
+	return record, nil
+}
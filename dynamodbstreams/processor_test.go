@@ -0,0 +1,121 @@
+package dynamodbstreams
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	ID   string `dynamodbav:"pk"`
+	Name string `dynamodbav:"name"`
+}
+
+func newRecord(op events.DynamoDBOperationType, seq string, oldImage, newImage map[string]events.DynamoDBAttributeValue) events.DynamoDBEventRecord {
+	return events.DynamoDBEventRecord{
+		EventID:   "event-" + seq,
+		EventName: string(op),
+		Change: events.DynamoDBStreamRecord{
+			SequenceNumber: seq,
+			OldImage:       oldImage,
+			NewImage:       newImage,
+		},
+	}
+}
+
+func image(id, name string) map[string]events.DynamoDBAttributeValue {
+	return map[string]events.DynamoDBAttributeValue{
+		"pk":   events.NewStringAttribute(id),
+		"name": events.NewStringAttribute(name),
+	}
+}
+
+func TestProcessor_Handle(t *testing.T) {
+	tests := []struct {
+		name         string
+		records      []events.DynamoDBEventRecord
+		wantHandled  []string
+		wantFailures []events.DynamoDBBatchItemFailure
+	}{
+		{
+			name: "all records handled by operation",
+			records: []events.DynamoDBEventRecord{
+				newRecord(events.DynamoDBOperationTypeInsert, "1", nil, image("1", "Jane")),
+				newRecord(events.DynamoDBOperationTypeModify, "2", image("1", "Jane"), image("1", "Janet")),
+				newRecord(events.DynamoDBOperationTypeRemove, "3", image("1", "Janet"), nil),
+			},
+			wantHandled:  []string{"insert:Jane", "modify:Jane->Janet[name]", "remove:Janet"},
+			wantFailures: []events.DynamoDBBatchItemFailure{},
+		},
+		{
+			name: "handler error, stops and reports record",
+			records: []events.DynamoDBEventRecord{
+				newRecord(events.DynamoDBOperationTypeInsert, "1", nil, image("1", "Jane")),
+				newRecord(events.DynamoDBOperationTypeInsert, "2", nil, image("2", "fail")),
+				newRecord(events.DynamoDBOperationTypeInsert, "3", nil, image("3", "Joe")),
+			},
+			wantHandled:  []string{"insert:Jane", "insert:fail"},
+			wantFailures: []events.DynamoDBBatchItemFailure{{ItemIdentifier: "2"}},
+		},
+		{
+			name: "decode error, stops and reports record",
+			records: []events.DynamoDBEventRecord{
+				newRecord(events.DynamoDBOperationTypeInsert, "1", nil, map[string]events.DynamoDBAttributeValue{
+					"name": events.NewBooleanAttribute(true),
+				}),
+				newRecord(events.DynamoDBOperationTypeInsert, "2", nil, image("2", "Joe")),
+			},
+			wantFailures: []events.DynamoDBBatchItemFailure{{ItemIdentifier: "1"}},
+		},
+		{
+			name: "unknown event name, stops and reports record",
+			records: []events.DynamoDBEventRecord{
+				newRecord("UPSERT", "1", nil, image("1", "Jane")),
+			},
+			wantFailures: []events.DynamoDBBatchItemFailure{{ItemIdentifier: "1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handled []string
+			handler := HandlerFuncs[item]{
+				Insert: func(_ context.Context, r Record[item]) error {
+					handled = append(handled, "insert:"+r.New.Name)
+					if r.New.Name == "fail" {
+						return errors.New("handler error")
+					}
+					return nil
+				},
+				Modify: func(_ context.Context, r Record[item]) error {
+					handled = append(handled, "modify:"+r.Old.Name+"->"+r.New.Name+"["+r.ChangedAttributes()[0]+"]")
+					return nil
+				},
+				Remove: func(_ context.Context, r Record[item]) error {
+					assert.Nil(t, r.New)
+					handled = append(handled, "remove:"+r.Old.Name)
+					return nil
+				},
+			}
+			p := NewProcessor[item](slog.New(slog.NewTextHandler(io.Discard, nil)), handler)
+
+			response, err := p.Handle(context.Background(), events.DynamoDBEvent{Records: tt.records})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHandled, handled)
+			assert.Equal(t, tt.wantFailures, response.BatchItemFailures)
+		})
+	}
+}
+
+func TestHandlerFuncs_Unset(t *testing.T) {
+	var h HandlerFuncs[item]
+
+	assert.NoError(t, h.OnInsert(context.Background(), Record[item]{}))
+	assert.NoError(t, h.OnModify(context.Background(), Record[item]{}))
+	assert.NoError(t, h.OnRemove(context.Background(), Record[item]{}))
+}
@@ -0,0 +1,397 @@
+package dynamodbstreams
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const tagName = "dynamodbav"
+
+var (
+	timeType        = reflect.TypeFor[time.Time]()
+	unmarshalerType = reflect.TypeFor[Unmarshaler]()
+
+	// ErrInvalidUnmarshal is returned by Unmarshal and UnmarshalMap when out is not a non-nil pointer.
+	ErrInvalidUnmarshal = errors.New("dynamodbstreams: unmarshal requires a non-nil pointer")
+)
+
+// Unmarshaler is implemented by types that decode themselves from a DynamoDB attribute value.
+type Unmarshaler interface {
+	UnmarshalDynamoDBAttributeValue(av events.DynamoDBAttributeValue) error
+}
+
+// UnmarshalError describes an attribute value that cannot be decoded into a Go type.
+type UnmarshalError struct {
+	// Path is the path of the attribute within the image, e.g. "address.lines[1]".
+	Path string
+	// DataType is the DynamoDB type of the attribute value.
+	DataType events.DynamoDBDataType
+	// Type is the Go type the attribute value could not be decoded into.
+	Type reflect.Type
+	// Err is the underlying error, if any.
+	Err error
+}
+
+// Error implements error.
+func (e *UnmarshalError) Error() string {
+	msg := fmt.Sprintf("dynamodbstreams: cannot unmarshal %s into %s", dataTypeName(e.DataType), e.Type)
+	if e.Path != "" {
+		msg += " at " + e.Path
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *UnmarshalError) Unwrap() error {
+	return e.Err
+}
+
+// UnmarshalMap decodes an image (or the keys) of a stream record into out, which must be a non-nil pointer,
+// typically to a struct.
+//
+// Struct fields are decoded from the attribute named by their `dynamodbav` tag, or otherwise the field name, as with
+// the AWS SDK's attributevalue package. A tag of "-" skips the field and untagged embedded structs are flattened.
+// Numbers decode into any numeric kind, or time.Time as unix seconds; strings decode into strings, or time.Time as
+// RFC 3339; lists and sets decode into slices and arrays; maps decode into structs and maps with string keys; and any
+// value decodes into an empty interface as string, float64, bool, []byte, []any, map[string]any, []string,
+// []float64 or [][]byte. Types implementing Unmarshaler decode themselves.
+func UnmarshalMap(image map[string]events.DynamoDBAttributeValue, out any) error {
+	return Unmarshal(events.NewMapAttribute(image), out)
+}
+
+// Unmarshal decodes av into out, which must be a non-nil pointer. See UnmarshalMap.
+func Unmarshal(av events.DynamoDBAttributeValue, out any) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return ErrInvalidUnmarshal
+	}
+	return decode("", av, v.Elem())
+}
+
+func decode(path string, av events.DynamoDBAttributeValue, v reflect.Value) error {
+	if v.Kind() != reflect.Pointer && v.CanAddr() && v.Addr().Type().Implements(unmarshalerType) {
+		if u, ok := v.Addr().Interface().(Unmarshaler); ok {
+			if err := u.UnmarshalDynamoDBAttributeValue(av); err != nil {
+				return &UnmarshalError{Path: path, DataType: av.DataType(), Type: v.Type(), Err: err}
+			}
+			return nil
+		}
+	}
+
+	if av.IsNull() {
+		v.SetZero()
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decode(path, av, v.Elem())
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return &UnmarshalError{Path: path, DataType: av.DataType(), Type: v.Type()}
+		}
+		v.Set(reflect.ValueOf(toInterface(av)))
+		return nil
+	default:
+	}
+
+	fail := func(err error) error {
+		return &UnmarshalError{Path: path, DataType: av.DataType(), Type: v.Type(), Err: err}
+	}
+
+	if v.Type() == timeType {
+		return decodeTime(av, v, fail)
+	}
+
+	switch av.DataType() {
+	case events.DataTypeString:
+		if v.Kind() != reflect.String {
+			return fail(nil)
+		}
+		v.SetString(av.String())
+	case events.DataTypeNumber:
+		return decodeNumber(av.Number(), v, fail)
+	case events.DataTypeBoolean:
+		if v.Kind() != reflect.Bool {
+			return fail(nil)
+		}
+		v.SetBool(av.Boolean())
+	case events.DataTypeBinary:
+		if !isBytes(v.Type()) {
+			return fail(nil)
+		}
+		v.SetBytes(append([]byte(nil), av.Binary()...))
+	case events.DataTypeList:
+		return decodeList(path, av.List(), v, fail)
+	case events.DataTypeStringSet:
+		return decodeList(path, mapSlice(av.StringSet(), events.NewStringAttribute), v, fail)
+	case events.DataTypeNumberSet:
+		return decodeList(path, mapSlice(av.NumberSet(), events.NewNumberAttribute), v, fail)
+	case events.DataTypeBinarySet:
+		return decodeList(path, mapSlice(av.BinarySet(), events.NewBinaryAttribute), v, fail)
+	case events.DataTypeMap:
+		switch v.Kind() {
+		case reflect.Struct:
+			return decodeStruct(path, av.Map(), v)
+		case reflect.Map:
+			return decodeMap(path, av.Map(), v, fail)
+		default:
+			return fail(nil)
+		}
+	default:
+		return fail(nil)
+	}
+	return nil
+}
+
+func decodeTime(av events.DynamoDBAttributeValue, v reflect.Value, fail func(error) error) error {
+	switch av.DataType() {
+	case events.DataTypeString:
+		t, err := time.Parse(time.RFC3339Nano, av.String())
+		if err != nil {
+			return fail(err)
+		}
+		v.Set(reflect.ValueOf(t))
+	case events.DataTypeNumber:
+		secs, err := strconv.ParseInt(av.Number(), 10, 64)
+		if err != nil {
+			return fail(err)
+		}
+		v.Set(reflect.ValueOf(time.Unix(secs, 0).UTC()))
+	default:
+		return fail(nil)
+	}
+	return nil
+}
+
+func decodeNumber(n string, v reflect.Value, fail func(error) error) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(n, 10, v.Type().Bits())
+		if err != nil {
+			return fail(err)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(n, 10, v.Type().Bits())
+		if err != nil {
+			return fail(err)
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(n, v.Type().Bits())
+		if err != nil {
+			return fail(err)
+		}
+		v.SetFloat(f)
+	default:
+		return fail(nil)
+	}
+	return nil
+}
+
+func decodeList(path string, list []events.DynamoDBAttributeValue, v reflect.Value, fail func(error) error) error {
+	switch v.Kind() {
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), len(list), len(list))
+		for i, item := range list {
+			if err := decode(fmt.Sprintf("%s[%d]", path, i), item, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+	case reflect.Array:
+		if len(list) > v.Len() {
+			return fail(fmt.Errorf("%d items do not fit in array", len(list)))
+		}
+		v.SetZero()
+		for i, item := range list {
+			if err := decode(fmt.Sprintf("%s[%d]", path, i), item, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return fail(nil)
+	}
+	return nil
+}
+
+func decodeMap(path string, m map[string]events.DynamoDBAttributeValue, v reflect.Value, fail func(error) error) error {
+	if v.Type().Key().Kind() != reflect.String {
+		return fail(nil)
+	}
+	out := reflect.MakeMapWithSize(v.Type(), len(m))
+	for key, item := range m {
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := decode(joinPath(path, key), item, elem); err != nil {
+			return err
+		}
+		out.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+	}
+	v.Set(out)
+	return nil
+}
+
+func decodeStruct(path string, m map[string]events.DynamoDBAttributeValue, v reflect.Value) error {
+	for name, index := range structFields(v.Type()) {
+		item, ok := m[name]
+		if !ok {
+			continue
+		}
+		field, err := v.FieldByIndexErr(index)
+		if err != nil {
+			// A nil embedded pointer to a struct
+			field = allocateField(v, index)
+		}
+		if err := decode(joinPath(path, name), item, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func allocateField(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// structFields returns the index of each decodable field of struct type t, keyed by attribute name.
+func structFields(t reflect.Type) map[string][]int {
+	fields := map[string][]int{}
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		for i := range t.NumField() {
+			f := t.Field(i)
+			tag := f.Tag.Get(tagName)
+			if tag == "-" {
+				continue
+			}
+			name, _, _ := strings.Cut(tag, ",")
+			fieldIndex := append(append([]int(nil), index...), i)
+
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+				// Like encoding/json, skip embedded pointers to unexported structs, which cannot be allocated
+				if !f.IsExported() && f.Type.Kind() == reflect.Pointer {
+					continue
+				}
+				walk(ft, fieldIndex)
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			// Shallower fields take precedence over those of embedded structs
+			if existing, ok := fields[name]; !ok || len(existing) > len(fieldIndex) {
+				fields[name] = fieldIndex
+			}
+		}
+	}
+	walk(t, nil)
+	return fields
+}
+
+func toInterface(av events.DynamoDBAttributeValue) any {
+	switch av.DataType() {
+	case events.DataTypeString:
+		return av.String()
+	case events.DataTypeNumber:
+		f, _ := strconv.ParseFloat(av.Number(), 64)
+		return f
+	case events.DataTypeBoolean:
+		return av.Boolean()
+	case events.DataTypeBinary:
+		return append([]byte(nil), av.Binary()...)
+	case events.DataTypeList:
+		return mapSlice(av.List(), toInterface)
+	case events.DataTypeMap:
+		m := make(map[string]any, len(av.Map()))
+		for k, item := range av.Map() {
+			m[k] = toInterface(item)
+		}
+		return m
+	case events.DataTypeStringSet:
+		return append([]string(nil), av.StringSet()...)
+	case events.DataTypeNumberSet:
+		return mapSlice(av.NumberSet(), func(n string) float64 {
+			f, _ := strconv.ParseFloat(n, 64)
+			return f
+		})
+	case events.DataTypeBinarySet:
+		return mapSlice(av.BinarySet(), func(b []byte) []byte { return append([]byte(nil), b...) })
+	default:
+		return nil
+	}
+}
+
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+func mapSlice[T, U any](s []T, fn func(T) U) []U {
+	out := make([]U, len(s))
+	for i, item := range s {
+		out[i] = fn(item)
+	}
+	return out
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func dataTypeName(dt events.DynamoDBDataType) string {
+	switch dt {
+	case events.DataTypeBinary:
+		return "B"
+	case events.DataTypeBoolean:
+		return "BOOL"
+	case events.DataTypeBinarySet:
+		return "BS"
+	case events.DataTypeList:
+		return "L"
+	case events.DataTypeMap:
+		return "M"
+	case events.DataTypeNumber:
+		return "N"
+	case events.DataTypeNumberSet:
+		return "NS"
+	case events.DataTypeNull:
+		return "NULL"
+	case events.DataTypeString:
+		return "S"
+	case events.DataTypeStringSet:
+		return "SS"
+	default:
+		return strconv.Itoa(int(dt))
+	}
+}
@@ -0,0 +1,180 @@
+package dynamodbstreams
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type address struct {
+	Lines    []string `dynamodbav:"lines"`
+	Postcode string   `dynamodbav:"postcode"`
+}
+
+type audit struct {
+	CreatedAt time.Time `dynamodbav:"created_at"`
+	UpdatedAt time.Time `dynamodbav:"updated_at"`
+}
+
+type status string
+
+func (s *status) UnmarshalDynamoDBAttributeValue(av events.DynamoDBAttributeValue) error {
+	if av.DataType() != events.DataTypeString {
+		return errors.New("status must be a string")
+	}
+	*s = status("status:" + av.String())
+	return nil
+}
+
+type customer struct {
+	audit
+
+	ID       string            `dynamodbav:"pk"`
+	Name     string            `dynamodbav:"name"`
+	Age      int               `dynamodbav:"age"`
+	Score    float64           `dynamodbav:"score"`
+	Active   bool              `dynamodbav:"active"`
+	Avatar   []byte            `dynamodbav:"avatar"`
+	Tags     []string          `dynamodbav:"tags"`
+	Lucky    [2]int            `dynamodbav:"lucky"`
+	Address  *address          `dynamodbav:"address"`
+	Labels   map[string]string `dynamodbav:"labels"`
+	Extra    any               `dynamodbav:"extra"`
+	Status   status            `dynamodbav:"status"`
+	Nickname *string           `dynamodbav:"nickname"`
+	Ignored  string            `dynamodbav:"-"`
+	Untagged string
+	private  string
+}
+
+func TestUnmarshalMap(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	image := map[string]events.DynamoDBAttributeValue{
+		"pk":         events.NewStringAttribute("customer#1"),
+		"name":       events.NewStringAttribute("Jane"),
+		"age":        events.NewNumberAttribute("42"),
+		"score":      events.NewNumberAttribute("9.5"),
+		"active":     events.NewBooleanAttribute(true),
+		"avatar":     events.NewBinaryAttribute([]byte{1, 2}),
+		"tags":       events.NewStringSetAttribute([]string{"a", "b"}),
+		"lucky":      events.NewNumberSetAttribute([]string{"7", "13"}),
+		"created_at": events.NewStringAttribute(created.Format(time.RFC3339Nano)),
+		"updated_at": events.NewNumberAttribute("1704164645"),
+		"address": events.NewMapAttribute(map[string]events.DynamoDBAttributeValue{
+			"lines":    events.NewListAttribute([]events.DynamoDBAttributeValue{events.NewStringAttribute("1 High St")}),
+			"postcode": events.NewStringAttribute("AB1 2CD"),
+		}),
+		"labels": events.NewMapAttribute(map[string]events.DynamoDBAttributeValue{
+			"tier": events.NewStringAttribute("gold"),
+		}),
+		"extra": events.NewListAttribute([]events.DynamoDBAttributeValue{
+			events.NewNumberAttribute("1"),
+			events.NewStringAttribute("two"),
+			events.NewNullAttribute(),
+		}),
+		"status":   events.NewStringAttribute("active"),
+		"nickname": events.NewNullAttribute(),
+		"Untagged": events.NewStringAttribute("untagged"),
+		"Ignored":  events.NewStringAttribute("ignored"),
+		"private":  events.NewStringAttribute("private"),
+		"unknown":  events.NewStringAttribute("unknown"),
+	}
+
+	var got customer
+	require.NoError(t, UnmarshalMap(image, &got))
+
+	assert.Equal(t, customer{
+		audit:    audit{CreatedAt: created, UpdatedAt: created},
+		ID:       "customer#1",
+		Name:     "Jane",
+		Age:      42,
+		Score:    9.5,
+		Active:   true,
+		Avatar:   []byte{1, 2},
+		Tags:     []string{"a", "b"},
+		Lucky:    [2]int{7, 13},
+		Address:  &address{Lines: []string{"1 High St"}, Postcode: "AB1 2CD"},
+		Labels:   map[string]string{"tier": "gold"},
+		Extra:    []any{float64(1), "two", nil},
+		Status:   "status:active",
+		Untagged: "untagged",
+	}, got)
+}
+
+func TestUnmarshalMap_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    map[string]events.DynamoDBAttributeValue
+		out      any
+		wantPath string
+	}{
+		{
+			name:     "string into int",
+			image:    map[string]events.DynamoDBAttributeValue{"age": events.NewStringAttribute("old")},
+			out:      &customer{},
+			wantPath: "age",
+		},
+		{
+			name:     "number overflows int8",
+			image:    map[string]events.DynamoDBAttributeValue{"N": events.NewNumberAttribute("300")},
+			out:      &struct{ N int8 }{},
+			wantPath: "N",
+		},
+		{
+			name: "nested list item",
+			image: map[string]events.DynamoDBAttributeValue{"address": events.NewMapAttribute(map[string]events.DynamoDBAttributeValue{
+				"lines": events.NewListAttribute([]events.DynamoDBAttributeValue{events.NewBooleanAttribute(true)}),
+			})},
+			out:      &customer{},
+			wantPath: "address.lines[0]",
+		},
+		{
+			name:     "too many items for array",
+			image:    map[string]events.DynamoDBAttributeValue{"lucky": events.NewNumberSetAttribute([]string{"1", "2", "3"})},
+			out:      &customer{},
+			wantPath: "lucky",
+		},
+		{
+			name:     "unmarshaler error",
+			image:    map[string]events.DynamoDBAttributeValue{"status": events.NewNumberAttribute("1")},
+			out:      &customer{},
+			wantPath: "status",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := UnmarshalMap(tt.image, tt.out)
+
+			var unmarshalErr *UnmarshalError
+			require.ErrorAs(t, err, &unmarshalErr)
+			assert.Equal(t, tt.wantPath, unmarshalErr.Path)
+		})
+	}
+}
+
+func TestUnmarshal_InvalidOut(t *testing.T) {
+	var c customer
+
+	assert.ErrorIs(t, Unmarshal(events.NewStringAttribute("x"), c), ErrInvalidUnmarshal)
+	assert.ErrorIs(t, Unmarshal(events.NewStringAttribute("x"), (*customer)(nil)), ErrInvalidUnmarshal)
+}
+
+func TestUnmarshalMap_EmbeddedUnexportedPointer(t *testing.T) {
+	type withEmbedded struct {
+		*address
+		Name string `dynamodbav:"name"`
+	}
+	image := map[string]events.DynamoDBAttributeValue{
+		"postcode": events.NewStringAttribute("AB1 2CD"),
+		"name":     events.NewStringAttribute("Jane"),
+	}
+
+	var got withEmbedded
+	require.NoError(t, UnmarshalMap(image, &got))
+
+	assert.Equal(t, withEmbedded{Name: "Jane"}, got)
+}
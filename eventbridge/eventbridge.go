@@ -0,0 +1,135 @@
+// Package eventbridge routes EventBridge events to typed handlers by source and detail-type, decoding the detail of
+// each event into the Go type registered for it.
+//
+//	router := eventbridge.NewRouter(eventbridge.WithStrict())
+//	eventbridge.Handle(router, "com.example.orders", "OrderPlaced", orderPlacedHandler)
+//	router.Default(auditHandler)
+//
+//	lambda.Start[events.EventBridgeEvent](router, middleware.CommonEventBridge(logger))
+package eventbridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var (
+	// ErrUnknownDetailType is returned by a strict Router for an event from a source with registered handlers, but
+	// none for the detail-type of the event.
+	ErrUnknownDetailType = errors.New("eventbridge: unknown detail-type")
+	// ErrNoRoute is returned by a Router for an event without a registered handler when there is no default route.
+	ErrNoRoute = errors.New("eventbridge: no route for event")
+	// ErrInvalidDetail is returned by a Router when the detail of an event cannot be decoded into the registered type.
+	ErrInvalidDetail = errors.New("eventbridge: invalid detail")
+)
+
+// Event [D any] is an EventBridge event with its detail decoded into type D.
+type Event[D any] struct {
+	events.EventBridgeEvent
+
+	// Detail is the decoded detail of the event. The raw detail remains available as EventBridgeEvent.Detail.
+	Detail D
+}
+
+// Handler [D any] interface should be implemented for handlers of EventBridge events with detail type D.
+type Handler[D any] interface {
+	// Handle handles an event.
+	Handle(ctx context.Context, event Event[D]) error
+}
+
+// HandlerFunc [D any] is an adapter to allow the use of ordinary functions as a Handler.
+type HandlerFunc[D any] func(ctx context.Context, event Event[D]) error
+
+// Handle calls f(ctx, event).
+func (f HandlerFunc[D]) Handle(ctx context.Context, event Event[D]) error {
+	return f(ctx, event)
+}
+
+type routeKey struct {
+	source     string
+	detailType string
+}
+
+type routerOptions struct {
+	strict bool
+}
+
+// RouterOption configures NewRouter.
+type RouterOption func(*routerOptions)
+
+// WithStrict rejects events from a source with registered handlers, but none for the detail-type of the event, with
+// ErrUnknownDetailType, rather than passing them to the default route.
+func WithStrict() RouterOption {
+	return func(o *routerOptions) {
+		o.strict = true
+	}
+}
+
+// Router routes EventBridge events to handlers registered with Handle by source and detail-type. It implements
+// lambda.Handler[events.EventBridgeEvent].
+//
+// Handlers must be registered before the Router handles events.
+type Router struct {
+	opts         routerOptions
+	routes       map[routeKey]func(context.Context, events.EventBridgeEvent) error
+	sources      map[string]bool
+	defaultRoute func(context.Context, events.EventBridgeEvent) error
+}
+
+// NewRouter returns a Router without any registered handlers.
+func NewRouter(options ...RouterOption) *Router {
+	opts := routerOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+	return &Router{
+		opts:    opts,
+		routes:  map[routeKey]func(context.Context, events.EventBridgeEvent) error{},
+		sources: map[string]bool{},
+	}
+}
+
+// Handle registers handler for events from source with detailType, decoding the detail of each event into type D.
+// Panics if a handler is already registered for source and detailType.
+func Handle[D any](r *Router, source, detailType string, handler Handler[D]) {
+	key := routeKey{source: source, detailType: detailType}
+	if _, ok := r.routes[key]; ok {
+		panic(fmt.Sprintf("eventbridge: handler already registered for source %q and detail-type %q", source, detailType))
+	}
+	r.sources[source] = true
+	r.routes[key] = func(ctx context.Context, event events.EventBridgeEvent) error {
+		var detail D
+		if len(event.Detail) > 0 {
+			if err := json.Unmarshal(event.Detail, &detail); err != nil {
+				return fmt.Errorf("%w: source %q, detail-type %q: %w", ErrInvalidDetail, event.Source, event.DetailType, err)
+			}
+		}
+		return handler.Handle(ctx, Event[D]{EventBridgeEvent: event, Detail: detail})
+	}
+}
+
+// Default registers handler for events without a handler registered with Handle, with the detail of each event left
+// undecoded.
+func (r *Router) Default(handler Handler[json.RawMessage]) {
+	r.defaultRoute = func(ctx context.Context, event events.EventBridgeEvent) error {
+		return handler.Handle(ctx, Event[json.RawMessage]{EventBridgeEvent: event, Detail: event.Detail})
+	}
+}
+
+// Handle routes event to the handler registered for its source and detail-type, or otherwise the default route.
+func (r *Router) Handle(ctx context.Context, event events.EventBridgeEvent) error {
+	if route, ok := r.routes[routeKey{source: event.Source, detailType: event.DetailType}]; ok {
+		return route(ctx, event)
+	}
+	if r.opts.strict && r.sources[event.Source] {
+		return fmt.Errorf("%w: source %q, detail-type %q", ErrUnknownDetailType, event.Source, event.DetailType)
+	}
+	if r.defaultRoute != nil {
+		return r.defaultRoute(ctx, event)
+	}
+	return fmt.Errorf("%w: source %q, detail-type %q", ErrNoRoute, event.Source, event.DetailType)
+}
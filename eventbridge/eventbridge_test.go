@@ -0,0 +1,117 @@
+package eventbridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderPlaced struct {
+	OrderID string `json:"orderId"`
+}
+
+func newEvent(source, detailType, detail string) events.EventBridgeEvent {
+	return events.EventBridgeEvent{
+		ID:         "event-id",
+		Source:     source,
+		DetailType: detailType,
+		Detail:     json.RawMessage(detail),
+	}
+}
+
+func TestRouter_Handle(t *testing.T) {
+	handlerErr := errors.New("handler error")
+
+	tests := []struct {
+		name        string
+		options     []RouterOption
+		withDefault bool
+		event       events.EventBridgeEvent
+		wantRoute   string
+		wantErr     error
+	}{
+		{
+			name:      "registered route, detail decoded",
+			event:     newEvent("orders", "OrderPlaced", `{"orderId":"123"}`),
+			wantRoute: "OrderPlaced:123",
+		},
+		{
+			name:      "registered route, handler returns error, returns error",
+			event:     newEvent("orders", "OrderCancelled", `{}`),
+			wantRoute: "OrderCancelled",
+			wantErr:   handlerErr,
+		},
+		{
+			name:    "registered route, invalid detail, returns ErrInvalidDetail",
+			event:   newEvent("orders", "OrderPlaced", `{"orderId":1}`),
+			wantErr: ErrInvalidDetail,
+		},
+		{
+			name:        "unknown detail-type, default route",
+			withDefault: true,
+			event:       newEvent("orders", "OrderShipped", `{}`),
+			wantRoute:   "default:OrderShipped",
+		},
+		{
+			name:        "unknown detail-type, strict, returns ErrUnknownDetailType",
+			options:     []RouterOption{WithStrict()},
+			withDefault: true,
+			event:       newEvent("orders", "OrderShipped", `{}`),
+			wantErr:     ErrUnknownDetailType,
+		},
+		{
+			name:        "unknown source, strict, default route",
+			options:     []RouterOption{WithStrict()},
+			withDefault: true,
+			event:       newEvent("payments", "PaymentTaken", `{}`),
+			wantRoute:   "default:PaymentTaken",
+		},
+		{
+			name:    "unknown source, no default route, returns ErrNoRoute",
+			event:   newEvent("payments", "PaymentTaken", `{}`),
+			wantErr: ErrNoRoute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var route string
+			r := NewRouter(tt.options...)
+			Handle(r, "orders", "OrderPlaced", HandlerFunc[orderPlaced](func(_ context.Context, event Event[orderPlaced]) error {
+				assert.Equal(t, "event-id", event.ID)
+				route = "OrderPlaced:" + event.Detail.OrderID
+				return nil
+			}))
+			Handle(r, "orders", "OrderCancelled", HandlerFunc[map[string]any](func(context.Context, Event[map[string]any]) error {
+				route = "OrderCancelled"
+				return handlerErr
+			}))
+			if tt.withDefault {
+				r.Default(HandlerFunc[json.RawMessage](func(_ context.Context, event Event[json.RawMessage]) error {
+					assert.JSONEq(t, `{}`, string(event.Detail))
+					route = "default:" + event.DetailType
+					return nil
+				}))
+			}
+
+			err := r.Handle(context.Background(), tt.event)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantRoute, route)
+		})
+	}
+}
+
+func TestHandle_Duplicate(t *testing.T) {
+	r := NewRouter()
+	handler := HandlerFunc[orderPlaced](func(context.Context, Event[orderPlaced]) error { return nil })
+	Handle(r, "orders", "OrderPlaced", handler)
+
+	require.Panics(t, func() {
+		Handle(r, "orders", "OrderPlaced", handler)
+	})
+}
@@ -0,0 +1,117 @@
+// Package cognitoredact redacts Cognito user pool trigger events, so the cognito package and the middleware that logs
+// the events of trigger handlers started on their own share the same redaction without depending on each other.
+package cognitoredact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// RedactedValue replaces redacted values.
+const RedactedValue = "[REDACTED]"
+
+// unredactedAttributes are user attributes that identify or describe a user without being personal data.
+var unredactedAttributes = map[string]struct{}{
+	"sub":                   {},
+	"email_verified":        {},
+	"phone_number_verified": {},
+	"cognito:user_status":   {},
+}
+
+// Header returns the header of event if it is one of the Cognito user pool trigger event types of
+// github.com/aws/aws-lambda-go/events.
+func Header(event any) (events.CognitoEventUserPoolsHeader, bool) {
+	switch e := event.(type) {
+	case events.CognitoEventUserPoolsPreSignup:
+		return e.CognitoEventUserPoolsHeader, true
+	case events.CognitoEventUserPoolsPostConfirmation:
+		return e.CognitoEventUserPoolsHeader, true
+	case events.CognitoEventUserPoolsPreAuthentication:
+		return e.CognitoEventUserPoolsHeader, true
+	case events.CognitoEventUserPoolsPostAuthentication:
+		return e.CognitoEventUserPoolsHeader, true
+	case events.CognitoEventUserPoolsPreTokenGen:
+		return e.CognitoEventUserPoolsHeader, true
+	case events.CognitoEventUserPoolsPreTokenGenV2_0:
+		return e.CognitoEventUserPoolsHeader, true
+	case events.CognitoEventUserPoolsCustomMessage:
+		return e.CognitoEventUserPoolsHeader, true
+	case events.CognitoEventUserPoolsDefineAuthChallenge:
+		return e.CognitoEventUserPoolsHeader, true
+	case events.CognitoEventUserPoolsCreateAuthChallenge:
+		return e.CognitoEventUserPoolsHeader, true
+	case events.CognitoEventUserPoolsVerifyAuthChallenge:
+		return e.CognitoEventUserPoolsHeader, true
+	case events.CognitoEventUserPoolsMigrateUser:
+		return e.CognitoEventUserPoolsHeader, true
+	}
+	return events.CognitoEventUserPoolsHeader{}, false
+}
+
+// Redact returns a redacted copy of the JSON encoding of event, a Cognito user pool trigger event, as a map. The
+// username, the values of user attributes other than sub, email_verified, phone_number_verified and
+// cognito:user_status, passwords, validation data, client metadata, challenge answers and private challenge parameters
+// are replaced with RedactedValue. An event that does not encode to a JSON object is replaced with RedactedValue.
+func Redact(event any) any {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return RedactedValue
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil || m == nil {
+		return RedactedValue
+	}
+
+	redactString(m, "userName")
+	for _, key := range []string{"request", "response"} {
+		section, ok := m[key].(map[string]any)
+		if !ok {
+			continue
+		}
+		redactAttributes(section, "userAttributes", unredactedAttributes)
+		redactAttributes(section, "validationData", nil)
+		redactAttributes(section, "privateChallengeParameters", nil)
+		redactAttributes(section, "clientMetadata", nil)
+		redactString(section, "password")
+		redactString(section, "challengeAnswer")
+	}
+	return m
+}
+
+// HashUsername returns a hash of username identifying a user in logs without logging the username, which is often an
+// email address.
+func HashUsername(username string) string {
+	sum := sha256.Sum256([]byte(username))
+	return hex.EncodeToString(sum[:8])
+}
+
+// LogFields returns the user pool id, trigger source and a hash of the username of header (see HashUsername).
+func LogFields(header events.CognitoEventUserPoolsHeader) []slog.Attr {
+	return []slog.Attr{
+		slog.String("user_pool_id", header.UserPoolID),
+		slog.String("trigger_source", header.TriggerSource),
+		slog.String("username_hash", HashUsername(header.UserName)),
+	}
+}
+
+func redactString(m map[string]any, key string) {
+	if v, ok := m[key]; ok && v != nil && v != "" {
+		m[key] = RedactedValue
+	}
+}
+
+func redactAttributes(m map[string]any, key string, unredacted map[string]struct{}) {
+	attributes, ok := m[key].(map[string]any)
+	if !ok {
+		return
+	}
+	for name := range attributes {
+		if _, ok := unredacted[name]; !ok {
+			attributes[name] = RedactedValue
+		}
+	}
+}
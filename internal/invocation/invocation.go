@@ -0,0 +1,34 @@
+// Package invocation carries details of the current Lambda invocation on its context, so the lambda package that
+// tracks them and the middleware that logs them don't depend on each other.
+package invocation
+
+import (
+	"context"
+)
+
+// Info describes a single invocation of a handler on this container.
+type Info struct {
+	// ColdStart is true for the first invocation on this container.
+	ColdStart bool
+	// Seq is the number of invocations on this container so far, including this one.
+	Seq uint64
+	// FunctionName is the name of the Lambda function.
+	FunctionName string
+	// FunctionVersion is the version of the Lambda function.
+	FunctionVersion string
+	// MemoryLimitInMB is the memory configured for the Lambda function.
+	MemoryLimitInMB int
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying info.
+func NewContext(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+// FromContext returns the Info carried by ctx, if any.
+func FromContext(ctx context.Context) (Info, bool) {
+	info, ok := ctx.Value(contextKey{}).(Info)
+	return info, ok
+}
@@ -0,0 +1,16 @@
+// Package requestid generates request ids in the format used by Lambda, for the packages that fake Lambda invocations
+// outside of Lambda.
+package requestid
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// New returns a random request id in the format used by Lambda.
+func New() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
@@ -0,0 +1,133 @@
+package kinesis
+
+import (
+	"bytes"
+	"crypto/md5" // #nosec G501 -- the KPL aggregation format checksums records with MD5
+	"encoding/binary"
+	"errors"
+)
+
+var aggregationMagic = []byte{0xF3, 0x89, 0x9A, 0xC2}
+
+var errMalformedAggregate = errors.New("malformed aggregated record")
+
+// userRecord is a record within a KPL aggregated record.
+type userRecord struct {
+	partitionKey string
+	data         []byte
+}
+
+// deaggregate returns the user records within a record aggregated by the Kinesis Producer Library, and whether data is
+// an aggregated record at all. As with the KPL, data without the aggregation magic bytes or with an invalid checksum
+// is not an aggregated record.
+//
+// The format is the magic bytes, an AggregatedRecord protobuf message and the MD5 checksum of the message:
+//
+//	message AggregatedRecord {
+//	  repeated string partition_key_table     = 1;
+//	  repeated string explicit_hash_key_table = 2;
+//	  repeated Record records                 = 3;
+//	}
+//	message Record {
+//	  required uint64 partition_key_index     = 1;
+//	  optional uint64 explicit_hash_key_index = 2;
+//	  required bytes  data                    = 3;
+//	  repeated Tag    tags                    = 4;
+//	}
+func deaggregate(data []byte) ([]userRecord, bool, error) {
+	if len(data) < len(aggregationMagic)+md5.Size || !bytes.HasPrefix(data, aggregationMagic) {
+		return nil, false, nil
+	}
+	message := data[len(aggregationMagic) : len(data)-md5.Size]
+	checksum := md5.Sum(message) // #nosec G401 -- integrity check defined by the KPL, not a security control
+	if !bytes.Equal(checksum[:], data[len(data)-md5.Size:]) {
+		return nil, false, nil
+	}
+
+	var partitionKeys []string
+	var records [][]byte
+	err := readFields(message, func(field uint64, value []byte) error {
+		switch field {
+		case 1:
+			partitionKeys = append(partitionKeys, string(value))
+		case 3:
+			records = append(records, value)
+		default:
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, true, err
+	}
+
+	userRecords := make([]userRecord, 0, len(records))
+	for _, record := range records {
+		var r userRecord
+		err := readFields(record, func(field uint64, value []byte) error {
+			switch field {
+			case 1:
+				index, n := binary.Uvarint(value)
+				if n <= 0 || index >= uint64(len(partitionKeys)) {
+					return errMalformedAggregate
+				}
+				r.partitionKey = partitionKeys[index]
+			case 3:
+				r.data = value
+			default:
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, true, err
+		}
+		userRecords = append(userRecords, r)
+	}
+	return userRecords, true, nil
+}
+
+// readFields calls fn with the number and value of each field of a protobuf message. Varint values are passed
+// undecoded, and fixed width values are skipped.
+func readFields(message []byte, fn func(field uint64, value []byte) error) error {
+	for len(message) > 0 {
+		key, n := binary.Uvarint(message)
+		if n <= 0 {
+			return errMalformedAggregate
+		}
+		message = message[n:]
+
+		var value []byte
+		switch key & 0x7 {
+		case 0: // varint
+			_, n = binary.Uvarint(message)
+			if n <= 0 {
+				return errMalformedAggregate
+			}
+			value, message = message[:n], message[n:]
+		case 1: // 64-bit
+			if len(message) < 8 {
+				return errMalformedAggregate
+			}
+			message = message[8:]
+			continue
+		case 2: // length-delimited
+			length, n := binary.Uvarint(message)
+			if n <= 0 || length > uint64(len(message)-n) {
+				return errMalformedAggregate
+			}
+			value, message = message[n:n+int(length)], message[n+int(length):] // #nosec G115 -- bounded by len(message)
+		case 5: // 32-bit
+			if len(message) < 4 {
+				return errMalformedAggregate
+			}
+			message = message[4:]
+			continue
+		default:
+			return errMalformedAggregate
+		}
+
+		if err := fn(key>>3, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Package kinesis processes Kinesis stream events record by record: decoding the data of each record into a Go type,
+// optionally de-aggregating records produced by the Kinesis Producer Library, and checkpointing on the first failure.
+package kinesis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// Record [T any] is a Kinesis record with its data decoded into type T.
+type Record[T any] struct {
+	events.KinesisEventRecord
+
+	// ShardID is the id of the shard the record was read from.
+	ShardID string
+	// PartitionKey is the partition key of the record, or of the user record within an aggregated record.
+	PartitionKey string
+	// SubSequenceNumber is the index of the user record within an aggregated record, or 0.
+	SubSequenceNumber int
+	// Data is the decoded data of the record, or of the user record within an aggregated record.
+	Data T
+}
+
+// Handler [T any] interface should be implemented for handlers of Kinesis records with data decoded into type T.
+type Handler[T any] interface {
+	// Handle handles a record.
+	Handle(ctx context.Context, record Record[T]) error
+}
+
+// HandlerFunc [T any] is an adapter to allow the use of ordinary functions as a Handler.
+type HandlerFunc[T any] func(ctx context.Context, record Record[T]) error
+
+// Handle calls f(ctx, record).
+func (f HandlerFunc[T]) Handle(ctx context.Context, record Record[T]) error {
+	return f(ctx, record)
+}
+
+type processorOptions struct {
+	deaggregate bool
+}
+
+// ProcessorOption configures NewProcessor.
+type ProcessorOption func(*processorOptions)
+
+// WithDeaggregation de-aggregates records produced by the Kinesis Producer Library, handling each user record within an
+// aggregated record separately. Records that are not aggregated are handled as they are.
+//
+// Lambda checkpoints by the sequence number of the aggregated record, so if a user record fails, every user record
+// within the aggregated record is retried.
+func WithDeaggregation() ProcessorOption {
+	return func(o *processorOptions) {
+		o.deaggregate = true
+	}
+}
+
+// Processor [T any] handles each record of a Kinesis stream event with a Handler, reporting partial batch failures.
+// It implements lambda.HandlerWithResponse[events.KinesisEvent, events.KinesisEventResponse]; the event source
+// mapping must have ReportBatchItemFailures enabled.
+//
+// The data of each record is decoded as JSON into T, unless T is []byte or json.RawMessage. Records are handled in
+// order. Lambda retries a shard from the first failed record, so processing stops at the first record that fails to
+// decode or is rejected by the handler, whose sequence number is reported as the checkpoint.
+type Processor[T any] struct {
+	logger  *slog.Logger
+	handler Handler[T]
+	opts    processorOptions
+}
+
+// NewProcessor returns a Processor handling records with handler, logging failed records to logger.
+func NewProcessor[T any](logger *slog.Logger, handler Handler[T], options ...ProcessorOption) *Processor[T] {
+	opts := processorOptions{}
+	for _, option := range options {
+		option(&opts)
+	}
+	return &Processor[T]{
+		logger:  logger,
+		handler: handler,
+		opts:    opts,
+	}
+}
+
+// Handle handles each record of event in order, stopping at the first failure.
+func (p *Processor[T]) Handle(ctx context.Context, event events.KinesisEvent) (events.KinesisEventResponse, error) {
+	response := events.KinesisEventResponse{BatchItemFailures: []events.KinesisBatchItemFailure{}}
+	for i, r := range event.Records {
+		if err := p.handle(ctx, r); err != nil {
+			p.logger.ErrorContext(ctx, "Record failed",
+				slog.String("event_id", r.EventID),
+				slog.String("sequence_number", r.Kinesis.SequenceNumber),
+				slog.Int("remaining_records", len(event.Records)-i-1),
+				slog.String("error", err.Error()),
+			)
+			response.BatchItemFailures = append(response.BatchItemFailures, events.KinesisBatchItemFailure{
+				ItemIdentifier: r.Kinesis.SequenceNumber,
+			})
+			break
+		}
+	}
+	return response, nil
+}
+
+func (p *Processor[T]) handle(ctx context.Context, r events.KinesisEventRecord) error {
+	userRecords := []userRecord{{partitionKey: r.Kinesis.PartitionKey, data: r.Kinesis.Data}}
+	if p.opts.deaggregate {
+		deaggregated, ok, err := deaggregate(r.Kinesis.Data)
+		if err != nil {
+			return err
+		}
+		if ok {
+			userRecords = deaggregated
+		}
+	}
+
+	for i, u := range userRecords {
+		data, err := decode[T](u.data)
+		if err != nil {
+			return fmt.Errorf("decoding data of sub-sequence %d: %w", i, err)
+		}
+		record := Record[T]{
+			KinesisEventRecord: r,
+			ShardID:            ShardID(r),
+			PartitionKey:       u.partitionKey,
+			SubSequenceNumber:  i,
+			Data:               data,
+		}
+		if err := p.handler.Handle(ctx, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ShardID returns the id of the shard r was read from.
+func ShardID(r events.KinesisEventRecord) string {
+	shardID, _, _ := strings.Cut(r.EventID, ":")
+	return shardID
+}
+
+func decode[T any](data []byte) (T, error) {
+	var v T
+	switch p := any(&v).(type) {
+	case *[]byte:
+		*p = data
+	case *json.RawMessage:
+		*p = data
+	default:
+		if err := json.Unmarshal(data, &v); err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
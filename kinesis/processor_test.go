@@ -0,0 +1,167 @@
+package kinesis
+
+import (
+	"context"
+	"crypto/md5" // #nosec G501 -- building KPL aggregated records
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+)
+
+type order struct {
+	ID string `json:"id"`
+}
+
+func newRecord(seq, partitionKey string, data []byte) events.KinesisEventRecord {
+	return events.KinesisEventRecord{
+		EventID: "shardId-000000000001:" + seq,
+		Kinesis: events.KinesisRecord{
+			SequenceNumber: seq,
+			PartitionKey:   partitionKey,
+			Data:           data,
+		},
+	}
+}
+
+func protoField(field uint64, value []byte) []byte {
+	b := binary.AppendUvarint(nil, field<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+func protoVarint(field, value uint64) []byte {
+	return binary.AppendUvarint(binary.AppendUvarint(nil, field<<3), value)
+}
+
+// aggregate builds a KPL aggregated record of data, each with its own partition key.
+func aggregate(partitionKeys []string, data [][]byte) []byte {
+	var message []byte
+	for _, pk := range partitionKeys {
+		message = append(message, protoField(1, []byte(pk))...)
+	}
+	for i, d := range data {
+		record := append(protoVarint(1, uint64(i)), protoField(3, d)...)
+		message = append(message, protoField(3, record)...)
+	}
+	checksum := md5.Sum(message) // #nosec G401
+	return append(append(append([]byte(nil), aggregationMagic...), message...), checksum[:]...)
+}
+
+func TestProcessor_Handle(t *testing.T) {
+	aggregated := aggregate([]string{"pk-a", "pk-b"}, [][]byte{[]byte(`{"id":"a"}`), []byte(`{"id":"b"}`)})
+	badChecksum := append([]byte(nil), aggregated...)
+	badChecksum[len(badChecksum)-1] ^= 0xff
+	malformed := aggregate(nil, [][]byte{[]byte(`{"id":"a"}`)}) // partition key index out of range
+
+	tests := []struct {
+		name         string
+		options      []ProcessorOption
+		records      []events.KinesisEventRecord
+		wantHandled  []string
+		wantFailures []events.KinesisBatchItemFailure
+	}{
+		{
+			name: "all records handled in order",
+			records: []events.KinesisEventRecord{
+				newRecord("1", "pk-1", []byte(`{"id":"1"}`)),
+				newRecord("2", "pk-2", []byte(`{"id":"2"}`)),
+			},
+			wantHandled:  []string{"1/pk-1/0/1", "2/pk-2/0/2"},
+			wantFailures: []events.KinesisBatchItemFailure{},
+		},
+		{
+			name: "handler error, stops and checkpoints record",
+			records: []events.KinesisEventRecord{
+				newRecord("1", "pk-1", []byte(`{"id":"1"}`)),
+				newRecord("2", "pk-2", []byte(`{"id":"fail"}`)),
+				newRecord("3", "pk-3", []byte(`{"id":"3"}`)),
+			},
+			wantHandled:  []string{"1/pk-1/0/1", "2/pk-2/0/fail"},
+			wantFailures: []events.KinesisBatchItemFailure{{ItemIdentifier: "2"}},
+		},
+		{
+			name: "decode error, stops and checkpoints record",
+			records: []events.KinesisEventRecord{
+				newRecord("1", "pk-1", []byte(`not json`)),
+				newRecord("2", "pk-2", []byte(`{"id":"2"}`)),
+			},
+			wantFailures: []events.KinesisBatchItemFailure{{ItemIdentifier: "1"}},
+		},
+		{
+			name:    "deaggregation, user records handled with partition keys",
+			options: []ProcessorOption{WithDeaggregation()},
+			records: []events.KinesisEventRecord{
+				newRecord("1", "pk-1", aggregated),
+				newRecord("2", "pk-2", []byte(`{"id":"2"}`)),
+			},
+			wantHandled:  []string{"1/pk-a/0/a", "1/pk-b/1/b", "2/pk-2/0/2"},
+			wantFailures: []events.KinesisBatchItemFailure{},
+		},
+		{
+			name:    "deaggregation, invalid checksum, handled as a plain record",
+			options: []ProcessorOption{WithDeaggregation()},
+			records: []events.KinesisEventRecord{
+				newRecord("1", "pk-1", badChecksum),
+			},
+			wantFailures: []events.KinesisBatchItemFailure{{ItemIdentifier: "1"}},
+		},
+		{
+			name:    "deaggregation, malformed aggregate, stops and checkpoints record",
+			options: []ProcessorOption{WithDeaggregation()},
+			records: []events.KinesisEventRecord{
+				newRecord("1", "pk-1", malformed),
+			},
+			wantFailures: []events.KinesisBatchItemFailure{{ItemIdentifier: "1"}},
+		},
+		{
+			name: "no deaggregation, aggregated record is not decoded",
+			records: []events.KinesisEventRecord{
+				newRecord("1", "pk-1", aggregated),
+			},
+			wantFailures: []events.KinesisBatchItemFailure{{ItemIdentifier: "1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handled []string
+			handler := HandlerFunc[order](func(_ context.Context, r Record[order]) error {
+				assert.Equal(t, "shardId-000000000001", r.ShardID)
+				handled = append(handled, r.Kinesis.SequenceNumber+"/"+r.PartitionKey+"/"+strconv.Itoa(r.SubSequenceNumber)+"/"+r.Data.ID)
+				if r.Data.ID == "fail" {
+					return errors.New("handler error")
+				}
+				return nil
+			})
+			p := NewProcessor[order](slog.New(slog.NewTextHandler(io.Discard, nil)), handler, tt.options...)
+
+			response, err := p.Handle(context.Background(), events.KinesisEvent{Records: tt.records})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantHandled, handled)
+			assert.Equal(t, tt.wantFailures, response.BatchItemFailures)
+		})
+	}
+}
+
+func TestProcessor_Handle_RawData(t *testing.T) {
+	var got []string
+	handler := HandlerFunc[json.RawMessage](func(_ context.Context, r Record[json.RawMessage]) error {
+		got = append(got, string(r.Data))
+		return nil
+	})
+	p := NewProcessor[json.RawMessage](slog.New(slog.NewTextHandler(io.Discard, nil)), handler)
+
+	_, err := p.Handle(context.Background(), events.KinesisEvent{Records: []events.KinesisEventRecord{
+		newRecord("1", "pk-1", []byte(`not json`)),
+	}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"not json"}, got)
+}
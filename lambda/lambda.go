@@ -5,6 +5,8 @@ import (
 
 	"github.com/aws/aws-lambda-go/lambda"
 
+	"github.com/ellogroup/ello-golang-clock/clock"
+
 	"github.com/ellogroup/ello-golang-aws/v2/lambda/middleware"
 )
 
@@ -16,19 +18,26 @@ type Handler[E any] interface {
 
 // Start initiates a lambda container for a handler and middleware of events that do not return a response.
 // sigTermCallbacks are callbacks to be triggered when the lambda container is closed.
+//
+// Each invocation's context carries its Invocation (see IsColdStart and InvocationFromContext), and Stats reports on
+// the container.
 func Start[E any](handler Handler[E], middlewares []middleware.NoResponse[E], sigTermCallbacks ...func()) {
 	lambda.StartWithOptions(
-		wrappedHandlerFn(handler, middlewares...),
+		trackedHandlerFn(startLifecycle(), wrappedHandlerFn(handler, middlewares...)),
 		lambda.WithEnableSIGTERM(sigTermCallbacks...),
 	)
 }
 
+// HandlerFn returns the func Start passes to the Lambda runtime: handler wrapped in middlewares, with each invocation's
+// context carrying its Invocation. Use it to invoke a handler outside the Lambda runtime (e.g. locally or in tests)
+// exactly as Start would. Each func returned tracks its own invocations, as a new container would, so its first
+// invocation is a cold start; Stats only reports on the handler started with Start/StartWithResponse.
+func HandlerFn[E any](handler Handler[E], middlewares []middleware.NoResponse[E]) func(context.Context, E) error {
+	return trackedHandlerFn(newLifecycle(clock.NewSystem()), wrappedHandlerFn(handler, middlewares...))
+}
+
 func wrappedHandlerFn[E any](handler Handler[E], middlewares ...middleware.NoResponse[E]) func(context.Context, E) error {
-	handlerFn := handler.Handle
-	for i := len(middlewares) - 1; i >= 0; i-- {
-		handlerFn = middlewares[i].Wrap(handlerFn)
-	}
-	return handlerFn
+	return middleware.NewChain(middlewares...).Then(handler.Handle)
 }
 
 // HandlerWithResponse [E, R any] interface should be implemented for handlers of event type E that return a response
@@ -40,17 +49,25 @@ type HandlerWithResponse[E, R any] interface {
 
 // StartWithResponse initiates a lambda container for a handler and middleware of events that return a response type R.
 // sigTermCallbacks are callbacks to be triggered when the lambda container is closed.
+//
+// Each invocation's context carries its Invocation (see IsColdStart and InvocationFromContext), and Stats reports on
+// the container.
 func StartWithResponse[E, R any](handler HandlerWithResponse[E, R], middlewares []middleware.WithResponse[E, R], sigTermCallbacks ...func()) {
 	lambda.StartWithOptions(
-		wrappedHandlerWithResponseFn(handler, middlewares...),
+		trackedHandlerWithResponseFn(startLifecycle(), wrappedHandlerWithResponseFn(handler, middlewares...)),
 		lambda.WithEnableSIGTERM(sigTermCallbacks...),
 	)
 }
 
+// HandlerWithResponseFn returns the func StartWithResponse passes to the Lambda runtime: handler wrapped in
+// middlewares, with each invocation's context carrying its Invocation. Use it to invoke a handler outside the Lambda
+// runtime (e.g. locally or in tests) exactly as StartWithResponse would. Each func returned tracks its own invocations,
+// as a new container would, so its first invocation is a cold start; Stats only reports on the handler started with
+// Start/StartWithResponse.
+func HandlerWithResponseFn[E, R any](handler HandlerWithResponse[E, R], middlewares []middleware.WithResponse[E, R]) func(context.Context, E) (R, error) {
+	return trackedHandlerWithResponseFn(newLifecycle(clock.NewSystem()), wrappedHandlerWithResponseFn(handler, middlewares...))
+}
+
 func wrappedHandlerWithResponseFn[E, R any](handler HandlerWithResponse[E, R], middlewares ...middleware.WithResponse[E, R]) func(context.Context, E) (R, error) {
-	handlerFn := handler.Handle
-	for i := len(middlewares) - 1; i >= 0; i-- {
-		handlerFn = middlewares[i].Wrap(handlerFn)
-	}
-	return handlerFn
+	return middleware.NewChainWithResponse(middlewares...).Then(handler.Handle)
 }
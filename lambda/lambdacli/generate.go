@@ -0,0 +1,82 @@
+package lambdacli
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/lambdatest"
+)
+
+var samples = map[string]func() any{
+	"apigw-v1": func() any {
+		return lambdatest.NewAPIGatewayV1Request(http.MethodPost, "/customers/123").
+			WithResource("/customers/{id}").
+			WithPathParameter("id", "123").
+			WithHeader("Accept", "application/json").
+			WithJSONBody(map[string]string{"name": "Jane"}).
+			Build()
+	},
+	"apigw-v2": func() any {
+		return lambdatest.NewAPIGatewayV2Request(http.MethodPost, "/customers/123").
+			WithRouteKey("POST /customers/{id}").
+			WithPathParameter("id", "123").
+			WithHeader("Accept", "application/json").
+			WithJSONBody(map[string]string{"name": "Jane"}).
+			Build()
+	},
+	"sqs": func() any {
+		return lambdatest.NewSQSEvent(lambdatest.NewJSONSQSMessage(map[string]string{"id": "123"}).Build())
+	},
+	"sns": func() any {
+		return lambdatest.NewSNSEvent(lambdatest.NewJSONSNSRecord(map[string]string{"id": "123"}).Build())
+	},
+	"s3": func() any {
+		return lambdatest.NewS3Event(lambdatest.NewS3Record("ObjectCreated:Put", "bucket", "path/to/object.json").WithSize(1024).Build())
+	},
+	"eventbridge": func() any {
+		return lambdatest.NewEventBridgeEvent("com.example.orders", "OrderPlaced").
+			WithDetail(map[string]string{"id": "123"}).
+			Build()
+	},
+	"dynamodb": func() any {
+		keys := map[string]events.DynamoDBAttributeValue{"pk": events.NewStringAttribute("customer#123")}
+		image := map[string]events.DynamoDBAttributeValue{
+			"pk":   events.NewStringAttribute("customer#123"),
+			"name": events.NewStringAttribute("Jane"),
+		}
+		return lambdatest.NewDynamoDBEvent(lambdatest.NewDynamoDBInsert(keys, image).Build())
+	},
+	"scheduled": func() any {
+		return lambdatest.NewEventBridgeEvent("aws.events", "Scheduled Event").
+			WithResources(fmt.Sprintf("arn:aws:events:%s:%s:rule/schedule", lambdatest.Region, lambdatest.AccountID)).
+			Build()
+	},
+}
+
+// EventTypes returns the event types the generate subcommand prints samples of.
+func EventTypes() []string {
+	return slices.Sorted(maps.Keys(samples))
+}
+
+func (c *CLI) generate(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 1 {
+		_, _ = fmt.Fprintf(stderr, "generate requires an event type: %s\n", strings.Join(EventTypes(), ", "))
+		return exitUsage
+	}
+	sample, ok := samples[args[0]]
+	if !ok {
+		_, _ = fmt.Fprintf(stderr, "unknown event type %q, event types: %s\n", args[0], strings.Join(EventTypes(), ", "))
+		return exitUsage
+	}
+	if err := writeJSON(stdout, sample()); err != nil {
+		_, _ = fmt.Fprintf(stderr, "encoding event: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,221 @@
+// Package lambdacli builds a command line tool for invoking handlers locally with event fixture files, through the
+// same middleware wrapping as lambda.Start/lambda.StartWithResponse, printing the response and the captured logs.
+//
+//	func main() {
+//		cli := lambdacli.New()
+//		lambdacli.Register(cli, "orders-queue", ordersHandler, middleware.CommonSQS)
+//		lambdacli.RegisterWithResponse(cli, "api", apiHandler, middleware.CommonAPIGatewayV1)
+//		os.Exit(cli.Main())
+//	}
+//
+// The tool has the subcommands:
+//
+//	invoke [-event file] [-timeout duration] <handler>  invoke a handler with an event from a file, or stdin
+//	generate <event type>                                print a sample event
+//	list                                                 list the registered handlers
+package lambdacli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/ellogroup/ello-golang-aws/v2/lambda"
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/lambdatest"
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/middleware"
+)
+
+const (
+	exitOK      = 0
+	exitError   = 1
+	exitUsage   = 2
+	stdinEvent  = "-"
+	usageHeader = `Usage:
+  %[1]s invoke [-event file] [-timeout duration] <handler>
+  %[1]s generate <event type>
+  %[1]s list
+`
+)
+
+type invokeFn func(ctx context.Context, payload []byte) (response any, hasResponse bool, records []slog.Record, err error)
+
+// CLI is a command line tool for invoking registered handlers locally.
+type CLI struct {
+	name     string
+	handlers map[string]invokeFn
+}
+
+// New returns a CLI without any registered handlers.
+func New() *CLI {
+	name := "lambda-invoke"
+	if len(os.Args) > 0 {
+		name = os.Args[0]
+	}
+	return &CLI{
+		name:     name,
+		handlers: map[string]invokeFn{},
+	}
+}
+
+// Register registers handler, for events that do not return a response, as name. middlewares builds the middleware
+// for the handler from the logger whose records are printed - e.g. middleware.CommonSQS - and may be nil.
+func Register[E any, M ~[]middleware.NoResponse[E]](c *CLI, name string, handler lambda.Handler[E], middlewares func(*slog.Logger) M) {
+	invoker := lambdatest.NewInvoker(handler, middlewares)
+	c.handlers[name] = func(ctx context.Context, payload []byte) (any, bool, []slog.Record, error) {
+		var event E
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, false, nil, fmt.Errorf("decoding event: %w", err)
+		}
+		result := invoker.Invoke(ctx, event)
+		return nil, false, result.Records, result.Err
+	}
+}
+
+// RegisterWithResponse registers handler, for events that return a response, as name. middlewares builds the
+// middleware for the handler from the logger whose records are printed - e.g. middleware.CommonAPIGatewayV1 - and
+// may be nil.
+func RegisterWithResponse[E, R any, M ~[]middleware.WithResponse[E, R]](c *CLI, name string, handler lambda.HandlerWithResponse[E, R], middlewares func(*slog.Logger) M) {
+	invoker := lambdatest.NewInvokerWithResponse(handler, middlewares)
+	c.handlers[name] = func(ctx context.Context, payload []byte) (any, bool, []slog.Record, error) {
+		var event E
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return nil, false, nil, fmt.Errorf("decoding event: %w", err)
+		}
+		result := invoker.Invoke(ctx, event)
+		return result.Response, true, result.Records, result.Err
+	}
+}
+
+// Main runs the CLI with the arguments, standard streams and signals of the process, returning the exit code.
+func (c *CLI) Main() int {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	return c.Run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+}
+
+// Run runs the CLI with args (excluding the program name), returning the exit code: 0 on success, 1 if the handler
+// returned an error or the event could not be read, and 2 on a usage error. Responses and sample events are written
+// to stdout as JSON; logs and errors are written to stderr, logs as JSON lines.
+func (c *CLI) Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		c.usage(stderr)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "invoke":
+		return c.invoke(ctx, args[1:], stdin, stdout, stderr)
+	case "generate":
+		return c.generate(args[1:], stdout, stderr)
+	case "list":
+		for _, name := range c.names() {
+			_, _ = fmt.Fprintln(stdout, name)
+		}
+		return exitOK
+	case "help", "-h", "-help", "--help":
+		c.usage(stdout)
+		return exitOK
+	default:
+		_, _ = fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		c.usage(stderr)
+		return exitUsage
+	}
+}
+
+func (c *CLI) invoke(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("invoke", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	eventFile := flags.String("event", stdinEvent, "JSON event file, or - for stdin")
+	timeout := flags.Duration("timeout", lambdatest.DefaultTimeout, "time until the invocation deadline")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		_, _ = fmt.Fprintln(stderr, "invoke requires a handler name")
+		c.usage(stderr)
+		return exitUsage
+	}
+
+	name := flags.Arg(0)
+	fn, ok := c.handlers[name]
+	if !ok {
+		_, _ = fmt.Fprintf(stderr, "unknown handler %q, registered handlers: %s\n", name, strings.Join(c.names(), ", "))
+		return exitUsage
+	}
+
+	payload, err := readEvent(*eventFile, stdin)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "reading event: %v\n", err)
+		return exitError
+	}
+
+	ctx, cancel := lambdatest.NewContext(ctx, lambdatest.WithTimeout(*timeout))
+	defer cancel()
+
+	response, hasResponse, records, err := fn(ctx, payload)
+	printRecords(ctx, stderr, records)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitError
+	}
+	if hasResponse {
+		if err := writeJSON(stdout, response); err != nil {
+			_, _ = fmt.Fprintf(stderr, "encoding response: %v\n", err)
+			return exitError
+		}
+	}
+	return exitOK
+}
+
+func (c *CLI) usage(w io.Writer) {
+	_, _ = fmt.Fprintf(w, usageHeader, c.name)
+	_, _ = fmt.Fprintf(w, "\nHandlers: %s\n", strings.Join(c.names(), ", "))
+	_, _ = fmt.Fprintf(w, "Event types: %s\n", strings.Join(EventTypes(), ", "))
+}
+
+func (c *CLI) names() []string {
+	names := make([]string, 0, len(c.handlers))
+	for name := range c.handlers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func readEvent(file string, stdin io.Reader) ([]byte, error) {
+	var payload []byte
+	var err error
+	if file == stdinEvent {
+		payload, err = io.ReadAll(stdin)
+	} else {
+		payload, err = os.ReadFile(file) // #nosec G304 -- reading the event file named by the user is the point
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(payload) {
+		return nil, errors.New("event is not valid JSON")
+	}
+	return payload, nil
+}
+
+func printRecords(ctx context.Context, w io.Writer, records []slog.Record) {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})
+	for _, r := range records {
+		_ = handler.Handle(ctx, r)
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,150 @@
+package lambdacli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/middleware"
+)
+
+type sqsHandler struct{}
+
+func (sqsHandler) Handle(_ context.Context, event events.SQSEvent) error {
+	if event.Records[0].Body == "fail" {
+		return errors.New("handler error")
+	}
+	return nil
+}
+
+type apiHandler struct{}
+
+func (apiHandler) Handle(_ context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+	return events.APIGatewayProxyResponse{StatusCode: 200, Body: event.Path}, nil
+}
+
+func newTestCLI() *CLI {
+	cli := New()
+	Register(cli, "queue", sqsHandler{}, middleware.CommonSQS)
+	RegisterWithResponse(cli, "api", apiHandler{}, middleware.CommonAPIGatewayV1)
+	return cli
+}
+
+func TestCLI_Run(t *testing.T) {
+	eventFile := filepath.Join(t.TempDir(), "event.json")
+	require.NoError(t, os.WriteFile(eventFile, []byte(`{"path":"/from-file"}`), 0o600))
+
+	tests := []struct {
+		name       string
+		args       []string
+		stdin      string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "invoke with response from stdin",
+			args:       []string{"invoke", "api"},
+			stdin:      `{"path":"/customers"}`,
+			wantCode:   exitOK,
+			wantStdout: `"body": "/customers"`,
+			wantStderr: `"msg":"Request complete"`,
+		},
+		{
+			name:       "invoke with response from file",
+			args:       []string{"invoke", "-event", eventFile, "api"},
+			wantCode:   exitOK,
+			wantStdout: `"body": "/from-file"`,
+		},
+		{
+			name:       "invoke without response",
+			args:       []string{"invoke", "queue"},
+			stdin:      `{"Records":[{"body":"ok"}]}`,
+			wantCode:   exitOK,
+			wantStderr: `"msg":"Request started"`,
+		},
+		{
+			name:       "handler error",
+			args:       []string{"invoke", "queue"},
+			stdin:      `{"Records":[{"body":"fail"}]}`,
+			wantCode:   exitError,
+			wantStderr: "error: handler error",
+		},
+		{
+			name:       "invalid event",
+			args:       []string{"invoke", "queue"},
+			stdin:      `not json`,
+			wantCode:   exitError,
+			wantStderr: "event is not valid JSON",
+		},
+		{
+			name:       "unknown handler",
+			args:       []string{"invoke", "missing"},
+			wantCode:   exitUsage,
+			wantStderr: `unknown handler "missing", registered handlers: api, queue`,
+		},
+		{
+			name:       "missing handler",
+			args:       []string{"invoke"},
+			wantCode:   exitUsage,
+			wantStderr: "invoke requires a handler name",
+		},
+		{
+			name:       "list",
+			args:       []string{"list"},
+			wantCode:   exitOK,
+			wantStdout: "api\nqueue\n",
+		},
+		{
+			name:       "unknown command",
+			args:       []string{"deploy"},
+			wantCode:   exitUsage,
+			wantStderr: `unknown command "deploy"`,
+		},
+		{
+			name:       "no command",
+			wantCode:   exitUsage,
+			wantStderr: "Usage:",
+		},
+		{
+			name:       "unknown event type",
+			args:       []string{"generate", "kafka"},
+			wantCode:   exitUsage,
+			wantStderr: `unknown event type "kafka"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := newTestCLI().Run(context.Background(), tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+			assert.Equal(t, tt.wantCode, code, stderr.String())
+			assert.Contains(t, stdout.String(), tt.wantStdout)
+			assert.Contains(t, stderr.String(), tt.wantStderr)
+		})
+	}
+}
+
+func TestCLI_Run_Generate(t *testing.T) {
+	for _, eventType := range EventTypes() {
+		t.Run(eventType, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := New().Run(context.Background(), []string{"generate", eventType}, nil, &stdout, &stderr)
+
+			assert.Equal(t, exitOK, code, stderr.String())
+			assert.True(t, json.Valid(stdout.Bytes()))
+		})
+	}
+	assert.Equal(t, []string{"apigw-v1", "apigw-v2", "dynamodb", "eventbridge", "s3", "scheduled", "sns", "sqs"}, EventTypes())
+}
@@ -0,0 +1,83 @@
+// Package lambdatest provides utilities for testing Lambda handlers: builders for common AWS events, a fake Lambda
+// context, and an invoker that runs a handler through its middleware exactly as lambda.Start/lambda.StartWithResponse
+// do, capturing the log records written along the way.
+package lambdatest
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+
+	"github.com/ellogroup/ello-golang-aws/v2/internal/requestid"
+)
+
+// DefaultTimeout is the time until the deadline of a context returned by NewContext, matching Lambda's default
+// function timeout.
+const DefaultTimeout = 3 * time.Second
+
+type contextOptions struct {
+	requestID          string
+	invokedFunctionArn string
+	deadline           time.Time
+	timeout            time.Duration
+}
+
+// ContextOption configures NewContext.
+type ContextOption func(*contextOptions)
+
+// WithRequestID sets the AwsRequestID of the Lambda context. Defaults to a random id.
+func WithRequestID(id string) ContextOption {
+	return func(o *contextOptions) {
+		o.requestID = id
+	}
+}
+
+// WithInvokedFunctionArn sets the InvokedFunctionArn of the Lambda context.
+func WithInvokedFunctionArn(arn string) ContextOption {
+	return func(o *contextOptions) {
+		o.invokedFunctionArn = arn
+	}
+}
+
+// WithDeadline sets the deadline of the context.
+func WithDeadline(deadline time.Time) ContextOption {
+	return func(o *contextOptions) {
+		o.deadline = deadline
+	}
+}
+
+// WithTimeout sets the deadline of the context to d from now. Defaults to DefaultTimeout.
+func WithTimeout(d time.Duration) ContextOption {
+	return func(o *contextOptions) {
+		o.timeout = d
+	}
+}
+
+// NewContext returns a copy of parent carrying a fake lambdacontext.LambdaContext and a deadline, like the context the
+// Lambda runtime passes to a handler. The returned cancel func must be called once the invocation is complete.
+func NewContext(parent context.Context, options ...ContextOption) (context.Context, context.CancelFunc) {
+	opts := contextOptions{
+		requestID: NewRequestID(),
+		timeout:   DefaultTimeout,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	deadline := opts.deadline
+	if deadline.IsZero() {
+		deadline = time.Now().Add(opts.timeout)
+	}
+
+	ctx := lambdacontext.NewContext(parent, &lambdacontext.LambdaContext{
+		AwsRequestID:       opts.requestID,
+		InvokedFunctionArn: opts.invokedFunctionArn,
+	})
+	return context.WithDeadline(ctx, deadline)
+}
+
+// NewRequestID returns a random request id in the format used by Lambda.
+func NewRequestID() string {
+	return requestid.New()
+}
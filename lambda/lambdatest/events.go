@@ -0,0 +1,554 @@
+package lambdatest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	// Region is the AWS region used by the event builders.
+	Region = "eu-west-2"
+	// AccountID is the AWS account id used by the event builders.
+	AccountID = "123456789012"
+)
+
+// APIGatewayV1RequestBuilder builds an events.APIGatewayProxyRequest.
+type APIGatewayV1RequestBuilder struct {
+	request events.APIGatewayProxyRequest
+}
+
+// NewAPIGatewayV1Request returns a builder for an API Gateway REST API (v1) request for method and path. The resource
+// defaults to path.
+func NewAPIGatewayV1Request(method, path string) *APIGatewayV1RequestBuilder {
+	return &APIGatewayV1RequestBuilder{
+		request: events.APIGatewayProxyRequest{
+			Resource:   path,
+			Path:       path,
+			HTTPMethod: method,
+			RequestContext: events.APIGatewayProxyRequestContext{
+				AccountID:    AccountID,
+				RequestID:    NewRequestID(),
+				Stage:        "test",
+				ResourcePath: path,
+				HTTPMethod:   method,
+				Path:         path,
+				Identity:     events.APIGatewayRequestIdentity{SourceIP: "127.0.0.1"},
+			},
+		},
+	}
+}
+
+// WithResource sets the resource (route template) of the request, e.g. "/users/{id}".
+func (b *APIGatewayV1RequestBuilder) WithResource(resource string) *APIGatewayV1RequestBuilder {
+	b.request.Resource = resource
+	b.request.RequestContext.ResourcePath = resource
+	return b
+}
+
+// WithHeader adds a header to the request.
+func (b *APIGatewayV1RequestBuilder) WithHeader(name, value string) *APIGatewayV1RequestBuilder {
+	b.request.Headers = setValue(b.request.Headers, name, value)
+	b.request.MultiValueHeaders = addValue(b.request.MultiValueHeaders, name, value)
+	return b
+}
+
+// WithQuery adds a query string parameter to the request.
+func (b *APIGatewayV1RequestBuilder) WithQuery(name, value string) *APIGatewayV1RequestBuilder {
+	b.request.QueryStringParameters = setValue(b.request.QueryStringParameters, name, value)
+	b.request.MultiValueQueryStringParameters = addValue(b.request.MultiValueQueryStringParameters, name, value)
+	return b
+}
+
+// WithPathParameter sets a path parameter of the request.
+func (b *APIGatewayV1RequestBuilder) WithPathParameter(name, value string) *APIGatewayV1RequestBuilder {
+	b.request.PathParameters = setValue(b.request.PathParameters, name, value)
+	return b
+}
+
+// WithRequestID sets the request id of the request context.
+func (b *APIGatewayV1RequestBuilder) WithRequestID(id string) *APIGatewayV1RequestBuilder {
+	b.request.RequestContext.RequestID = id
+	return b
+}
+
+// WithBody sets the body of the request.
+func (b *APIGatewayV1RequestBuilder) WithBody(body string) *APIGatewayV1RequestBuilder {
+	b.request.Body = body
+	b.request.IsBase64Encoded = false
+	return b
+}
+
+// WithJSONBody sets the body of the request to v encoded as JSON, and the Content-Type header to application/json.
+// Panics if v cannot be encoded.
+func (b *APIGatewayV1RequestBuilder) WithJSONBody(v any) *APIGatewayV1RequestBuilder {
+	return b.WithBody(mustJSON(v)).WithHeader("Content-Type", "application/json")
+}
+
+// WithBase64Body sets the body of the request to body encoded as base64, as API Gateway does for binary media types.
+func (b *APIGatewayV1RequestBuilder) WithBase64Body(body []byte) *APIGatewayV1RequestBuilder {
+	b.request.Body = base64.StdEncoding.EncodeToString(body)
+	b.request.IsBase64Encoded = true
+	return b
+}
+
+// Build returns the request.
+func (b *APIGatewayV1RequestBuilder) Build() events.APIGatewayProxyRequest {
+	r := b.request
+	r.Headers = maps.Clone(r.Headers)
+	r.MultiValueHeaders = cloneValues(r.MultiValueHeaders)
+	r.QueryStringParameters = maps.Clone(r.QueryStringParameters)
+	r.MultiValueQueryStringParameters = cloneValues(r.MultiValueQueryStringParameters)
+	r.PathParameters = maps.Clone(r.PathParameters)
+	return r
+}
+
+// APIGatewayV2RequestBuilder builds an events.APIGatewayV2HTTPRequest.
+type APIGatewayV2RequestBuilder struct {
+	request events.APIGatewayV2HTTPRequest
+	query   url.Values
+}
+
+// NewAPIGatewayV2Request returns a builder for an API Gateway HTTP API (v2) request for method and path. The route
+// key defaults to "METHOD path".
+func NewAPIGatewayV2Request(method, path string) *APIGatewayV2RequestBuilder {
+	routeKey := method + " " + path
+	return &APIGatewayV2RequestBuilder{
+		request: events.APIGatewayV2HTTPRequest{
+			Version:  "2.0",
+			RouteKey: routeKey,
+			RawPath:  path,
+			RequestContext: events.APIGatewayV2HTTPRequestContext{
+				RouteKey:  routeKey,
+				AccountID: AccountID,
+				Stage:     "$default",
+				RequestID: NewRequestID(),
+				HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+					Method:   method,
+					Path:     path,
+					Protocol: "HTTP/1.1",
+					SourceIP: "127.0.0.1",
+				},
+			},
+		},
+		query: url.Values{},
+	}
+}
+
+// WithRouteKey sets the route key of the request, e.g. "GET /users/{id}".
+func (b *APIGatewayV2RequestBuilder) WithRouteKey(routeKey string) *APIGatewayV2RequestBuilder {
+	b.request.RouteKey = routeKey
+	b.request.RequestContext.RouteKey = routeKey
+	return b
+}
+
+// WithHeader adds a header to the request. Header names are lower-cased and repeated values joined with commas, as
+// API Gateway does.
+func (b *APIGatewayV2RequestBuilder) WithHeader(name, value string) *APIGatewayV2RequestBuilder {
+	name = strings.ToLower(name)
+	if existing, ok := b.request.Headers[name]; ok {
+		value = existing + "," + value
+	}
+	b.request.Headers = setValue(b.request.Headers, name, value)
+	return b
+}
+
+// WithQuery adds a query string parameter to the request.
+func (b *APIGatewayV2RequestBuilder) WithQuery(name, value string) *APIGatewayV2RequestBuilder {
+	b.query.Add(name, value)
+	b.request.QueryStringParameters = setValue(b.request.QueryStringParameters, name, strings.Join(b.query[name], ","))
+	b.request.RawQueryString = b.query.Encode()
+	return b
+}
+
+// WithPathParameter sets a path parameter of the request.
+func (b *APIGatewayV2RequestBuilder) WithPathParameter(name, value string) *APIGatewayV2RequestBuilder {
+	b.request.PathParameters = setValue(b.request.PathParameters, name, value)
+	return b
+}
+
+// WithCookie adds a cookie to the request.
+func (b *APIGatewayV2RequestBuilder) WithCookie(name, value string) *APIGatewayV2RequestBuilder {
+	b.request.Cookies = append(b.request.Cookies, name+"="+value)
+	return b
+}
+
+// WithRequestID sets the request id of the request context.
+func (b *APIGatewayV2RequestBuilder) WithRequestID(id string) *APIGatewayV2RequestBuilder {
+	b.request.RequestContext.RequestID = id
+	return b
+}
+
+// WithBody sets the body of the request.
+func (b *APIGatewayV2RequestBuilder) WithBody(body string) *APIGatewayV2RequestBuilder {
+	b.request.Body = body
+	b.request.IsBase64Encoded = false
+	return b
+}
+
+// WithJSONBody sets the body of the request to v encoded as JSON, and the content-type header to application/json.
+// Panics if v cannot be encoded.
+func (b *APIGatewayV2RequestBuilder) WithJSONBody(v any) *APIGatewayV2RequestBuilder {
+	return b.WithBody(mustJSON(v)).WithHeader("Content-Type", "application/json")
+}
+
+// WithBase64Body sets the body of the request to body encoded as base64, as API Gateway does for binary payloads.
+func (b *APIGatewayV2RequestBuilder) WithBase64Body(body []byte) *APIGatewayV2RequestBuilder {
+	b.request.Body = base64.StdEncoding.EncodeToString(body)
+	b.request.IsBase64Encoded = true
+	return b
+}
+
+// Build returns the request.
+func (b *APIGatewayV2RequestBuilder) Build() events.APIGatewayV2HTTPRequest {
+	r := b.request
+	r.Headers = maps.Clone(r.Headers)
+	r.QueryStringParameters = maps.Clone(r.QueryStringParameters)
+	r.PathParameters = maps.Clone(r.PathParameters)
+	r.Cookies = append([]string(nil), r.Cookies...)
+	return r
+}
+
+// SQSMessageBuilder builds an events.SQSMessage.
+type SQSMessageBuilder struct {
+	message events.SQSMessage
+}
+
+// NewSQSMessage returns a builder for an SQS message with body.
+func NewSQSMessage(body string) *SQSMessageBuilder {
+	return &SQSMessageBuilder{
+		message: events.SQSMessage{
+			MessageId:      NewRequestID(),
+			ReceiptHandle:  NewRequestID(),
+			Body:           body,
+			Attributes:     map[string]string{"ApproximateReceiveCount": "1"},
+			EventSource:    "aws:sqs",
+			EventSourceARN: fmt.Sprintf("arn:aws:sqs:%s:%s:queue", Region, AccountID),
+			AWSRegion:      Region,
+		},
+	}
+}
+
+// NewJSONSQSMessage returns a builder for an SQS message with v encoded as JSON as its body. Panics if v cannot be
+// encoded.
+func NewJSONSQSMessage(v any) *SQSMessageBuilder {
+	return NewSQSMessage(mustJSON(v))
+}
+
+// WithMessageID sets the id of the message.
+func (b *SQSMessageBuilder) WithMessageID(id string) *SQSMessageBuilder {
+	b.message.MessageId = id
+	return b
+}
+
+// WithQueueARN sets the ARN of the queue the message was received from.
+func (b *SQSMessageBuilder) WithQueueARN(arn string) *SQSMessageBuilder {
+	b.message.EventSourceARN = arn
+	return b
+}
+
+// WithAttribute sets a system attribute of the message, e.g. "ApproximateReceiveCount" or "MessageGroupId".
+func (b *SQSMessageBuilder) WithAttribute(name, value string) *SQSMessageBuilder {
+	b.message.Attributes = setValue(b.message.Attributes, name, value)
+	return b
+}
+
+// WithMessageAttribute sets a String message attribute of the message.
+func (b *SQSMessageBuilder) WithMessageAttribute(name, value string) *SQSMessageBuilder {
+	if b.message.MessageAttributes == nil {
+		b.message.MessageAttributes = map[string]events.SQSMessageAttribute{}
+	}
+	b.message.MessageAttributes[name] = events.SQSMessageAttribute{StringValue: &value, DataType: "String"}
+	return b
+}
+
+// Build returns the message.
+func (b *SQSMessageBuilder) Build() events.SQSMessage {
+	m := b.message
+	m.Attributes = maps.Clone(m.Attributes)
+	m.MessageAttributes = maps.Clone(m.MessageAttributes)
+	return m
+}
+
+// NewSQSEvent returns an SQS event delivering messages.
+func NewSQSEvent(messages ...events.SQSMessage) events.SQSEvent {
+	return events.SQSEvent{Records: messages}
+}
+
+// SNSRecordBuilder builds an events.SNSEventRecord.
+type SNSRecordBuilder struct {
+	record events.SNSEventRecord
+}
+
+// NewSNSRecord returns a builder for an SNS record delivering message.
+func NewSNSRecord(message string) *SNSRecordBuilder {
+	topicARN := fmt.Sprintf("arn:aws:sns:%s:%s:topic", Region, AccountID)
+	return &SNSRecordBuilder{
+		record: events.SNSEventRecord{
+			EventVersion:         "1.0",
+			EventSource:          "aws:sns",
+			EventSubscriptionArn: topicARN + ":" + NewRequestID(),
+			SNS: events.SNSEntity{
+				Type:      "Notification",
+				MessageID: NewRequestID(),
+				TopicArn:  topicARN,
+				Message:   message,
+				Timestamp: time.Now().UTC(),
+			},
+		},
+	}
+}
+
+// NewJSONSNSRecord returns a builder for an SNS record delivering v encoded as JSON. Panics if v cannot be encoded.
+func NewJSONSNSRecord(v any) *SNSRecordBuilder {
+	return NewSNSRecord(mustJSON(v))
+}
+
+// WithMessageID sets the id of the message.
+func (b *SNSRecordBuilder) WithMessageID(id string) *SNSRecordBuilder {
+	b.record.SNS.MessageID = id
+	return b
+}
+
+// WithTopicARN sets the ARN of the topic the message was published to.
+func (b *SNSRecordBuilder) WithTopicARN(arn string) *SNSRecordBuilder {
+	b.record.SNS.TopicArn = arn
+	return b
+}
+
+// WithSubject sets the subject of the message.
+func (b *SNSRecordBuilder) WithSubject(subject string) *SNSRecordBuilder {
+	b.record.SNS.Subject = subject
+	return b
+}
+
+// WithMessageAttribute sets a String message attribute of the message, in the format SNS delivers it to Lambda.
+func (b *SNSRecordBuilder) WithMessageAttribute(name, value string) *SNSRecordBuilder {
+	if b.record.SNS.MessageAttributes == nil {
+		b.record.SNS.MessageAttributes = map[string]any{}
+	}
+	b.record.SNS.MessageAttributes[name] = map[string]any{"Type": "String", "Value": value}
+	return b
+}
+
+// Build returns the record.
+func (b *SNSRecordBuilder) Build() events.SNSEventRecord {
+	r := b.record
+	r.SNS.MessageAttributes = maps.Clone(r.SNS.MessageAttributes)
+	return r
+}
+
+// NewSNSEvent returns an SNS event delivering records.
+func NewSNSEvent(records ...events.SNSEventRecord) events.SNSEvent {
+	return events.SNSEvent{Records: records}
+}
+
+// S3RecordBuilder builds an events.S3EventRecord.
+type S3RecordBuilder struct {
+	record events.S3EventRecord
+}
+
+// NewS3Record returns a builder for an S3 notification record of eventName (e.g. "ObjectCreated:Put") for the object
+// key in bucket. The key is URL encoded as S3 does, with URLDecodedKey set to key.
+func NewS3Record(eventName, bucket, key string) *S3RecordBuilder {
+	return &S3RecordBuilder{
+		record: events.S3EventRecord{
+			EventVersion: "2.1",
+			EventSource:  "aws:s3",
+			AWSRegion:    Region,
+			EventTime:    time.Now().UTC(),
+			EventName:    eventName,
+			S3: events.S3Entity{
+				SchemaVersion: "1.0",
+				Bucket: events.S3Bucket{
+					Name: bucket,
+					Arn:  "arn:aws:s3:::" + bucket,
+				},
+				Object: events.S3Object{
+					Key:           url.QueryEscape(key),
+					URLDecodedKey: key,
+				},
+			},
+		},
+	}
+}
+
+// WithSize sets the size of the object.
+func (b *S3RecordBuilder) WithSize(size int64) *S3RecordBuilder {
+	b.record.S3.Object.Size = size
+	return b
+}
+
+// WithVersionID sets the version id of the object.
+func (b *S3RecordBuilder) WithVersionID(id string) *S3RecordBuilder {
+	b.record.S3.Object.VersionID = id
+	return b
+}
+
+// WithETag sets the ETag of the object.
+func (b *S3RecordBuilder) WithETag(etag string) *S3RecordBuilder {
+	b.record.S3.Object.ETag = etag
+	return b
+}
+
+// Build returns the record.
+func (b *S3RecordBuilder) Build() events.S3EventRecord {
+	return b.record
+}
+
+// NewS3Event returns an S3 event delivering records.
+func NewS3Event(records ...events.S3EventRecord) events.S3Event {
+	return events.S3Event{Records: records}
+}
+
+// EventBridgeEventBuilder builds an events.EventBridgeEvent.
+type EventBridgeEventBuilder struct {
+	event events.EventBridgeEvent
+}
+
+// NewEventBridgeEvent returns a builder for an EventBridge event from source with detailType.
+func NewEventBridgeEvent(source, detailType string) *EventBridgeEventBuilder {
+	return &EventBridgeEventBuilder{
+		event: events.EventBridgeEvent{
+			Version:    "0",
+			ID:         NewRequestID(),
+			DetailType: detailType,
+			Source:     source,
+			AccountID:  AccountID,
+			Time:       time.Now().UTC(),
+			Region:     Region,
+			Resources:  []string{},
+			Detail:     json.RawMessage("{}"),
+		},
+	}
+}
+
+// WithDetail sets the detail of the event to v encoded as JSON. Panics if v cannot be encoded.
+func (b *EventBridgeEventBuilder) WithDetail(v any) *EventBridgeEventBuilder {
+	b.event.Detail = json.RawMessage(mustJSON(v))
+	return b
+}
+
+// WithResources adds resources to the event.
+func (b *EventBridgeEventBuilder) WithResources(resources ...string) *EventBridgeEventBuilder {
+	b.event.Resources = append(b.event.Resources, resources...)
+	return b
+}
+
+// WithTime sets the time of the event.
+func (b *EventBridgeEventBuilder) WithTime(t time.Time) *EventBridgeEventBuilder {
+	b.event.Time = t
+	return b
+}
+
+// Build returns the event.
+func (b *EventBridgeEventBuilder) Build() events.EventBridgeEvent {
+	e := b.event
+	e.Resources = append([]string{}, e.Resources...)
+	return e
+}
+
+// DynamoDBRecordBuilder builds an events.DynamoDBEventRecord.
+type DynamoDBRecordBuilder struct {
+	record events.DynamoDBEventRecord
+}
+
+// NewDynamoDBInsert returns a builder for a DynamoDB stream record of an item inserted with keys and newImage.
+func NewDynamoDBInsert(keys, newImage map[string]events.DynamoDBAttributeValue) *DynamoDBRecordBuilder {
+	return newDynamoDBRecord(events.DynamoDBOperationTypeInsert, keys, newImage, nil)
+}
+
+// NewDynamoDBModify returns a builder for a DynamoDB stream record of an item with keys modified from oldImage to
+// newImage.
+func NewDynamoDBModify(keys, oldImage, newImage map[string]events.DynamoDBAttributeValue) *DynamoDBRecordBuilder {
+	return newDynamoDBRecord(events.DynamoDBOperationTypeModify, keys, newImage, oldImage)
+}
+
+// NewDynamoDBRemove returns a builder for a DynamoDB stream record of an item with keys and oldImage removed.
+func NewDynamoDBRemove(keys, oldImage map[string]events.DynamoDBAttributeValue) *DynamoDBRecordBuilder {
+	return newDynamoDBRecord(events.DynamoDBOperationTypeRemove, keys, nil, oldImage)
+}
+
+func newDynamoDBRecord(op events.DynamoDBOperationType, keys, newImage, oldImage map[string]events.DynamoDBAttributeValue) *DynamoDBRecordBuilder {
+	return &DynamoDBRecordBuilder{
+		record: events.DynamoDBEventRecord{
+			AWSRegion:      Region,
+			EventID:        NewRequestID(),
+			EventName:      string(op),
+			EventSource:    "aws:dynamodb",
+			EventVersion:   "1.1",
+			EventSourceArn: fmt.Sprintf("arn:aws:dynamodb:%s:%s:table/table/stream/2024-01-01T00:00:00.000", Region, AccountID),
+			Change: events.DynamoDBStreamRecord{
+				ApproximateCreationDateTime: events.SecondsEpochTime{Time: time.Now().UTC().Truncate(time.Second)},
+				Keys:                        keys,
+				NewImage:                    newImage,
+				OldImage:                    oldImage,
+				SequenceNumber:              "1",
+				StreamViewType:              "NEW_AND_OLD_IMAGES",
+			},
+		},
+	}
+}
+
+// WithStreamARN sets the ARN of the stream the record was read from.
+func (b *DynamoDBRecordBuilder) WithStreamARN(arn string) *DynamoDBRecordBuilder {
+	b.record.EventSourceArn = arn
+	return b
+}
+
+// WithSequenceNumber sets the sequence number of the record.
+func (b *DynamoDBRecordBuilder) WithSequenceNumber(sequenceNumber string) *DynamoDBRecordBuilder {
+	b.record.Change.SequenceNumber = sequenceNumber
+	return b
+}
+
+// Build returns the record.
+func (b *DynamoDBRecordBuilder) Build() events.DynamoDBEventRecord {
+	return b.record
+}
+
+// NewDynamoDBEvent returns a DynamoDB stream event delivering records.
+func NewDynamoDBEvent(records ...events.DynamoDBEventRecord) events.DynamoDBEvent {
+	return events.DynamoDBEvent{Records: records}
+}
+
+func mustJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("lambdatest: encoding JSON: %v", err))
+	}
+	return string(b)
+}
+
+func setValue(m map[string]string, key, value string) map[string]string {
+	if m == nil {
+		m = map[string]string{}
+	}
+	m[key] = value
+	return m
+}
+
+func addValue(m map[string][]string, key, value string) map[string][]string {
+	if m == nil {
+		m = map[string][]string{}
+	}
+	m[key] = append(m[key], value)
+	return m
+}
+
+func cloneValues(m map[string][]string) map[string][]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string][]string, len(m))
+	for k, v := range m {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
@@ -0,0 +1,155 @@
+package lambdatest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIGatewayV1Request(t *testing.T) {
+	r := NewAPIGatewayV1Request(http.MethodPost, "/users/123").
+		WithResource("/users/{id}").
+		WithPathParameter("id", "123").
+		WithHeader("X-Trace", "a").
+		WithHeader("X-Trace", "b").
+		WithQuery("q", "1").
+		WithRequestID("req-1").
+		WithJSONBody(map[string]string{"name": "x"}).
+		Build()
+
+	assert.Equal(t, http.MethodPost, r.HTTPMethod)
+	assert.Equal(t, "/users/{id}", r.Resource)
+	assert.Equal(t, "/users/{id}", r.RequestContext.ResourcePath)
+	assert.Equal(t, "/users/123", r.Path)
+	assert.Equal(t, "123", r.PathParameters["id"])
+	assert.Equal(t, "b", r.Headers["X-Trace"])
+	assert.Equal(t, []string{"a", "b"}, r.MultiValueHeaders["X-Trace"])
+	assert.Equal(t, "application/json", r.Headers["Content-Type"])
+	assert.Equal(t, "1", r.QueryStringParameters["q"])
+	assert.Equal(t, "req-1", r.RequestContext.RequestID)
+	assert.JSONEq(t, `{"name":"x"}`, r.Body)
+	assert.False(t, r.IsBase64Encoded)
+}
+
+func TestNewAPIGatewayV1Request_Base64Body(t *testing.T) {
+	r := NewAPIGatewayV1Request(http.MethodPut, "/files").WithBase64Body([]byte{0xff, 0x00}).Build()
+
+	assert.True(t, r.IsBase64Encoded)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0xff, 0x00}), r.Body)
+}
+
+func TestNewAPIGatewayV1Request_BuildCopies(t *testing.T) {
+	b := NewAPIGatewayV1Request(http.MethodGet, "/").WithHeader("A", "1")
+	first := b.Build()
+	b.WithHeader("B", "2")
+
+	assert.NotContains(t, first.Headers, "B")
+}
+
+func TestNewAPIGatewayV2Request(t *testing.T) {
+	r := NewAPIGatewayV2Request(http.MethodGet, "/users/123").
+		WithRouteKey("GET /users/{id}").
+		WithPathParameter("id", "123").
+		WithHeader("Accept", "a").
+		WithHeader("Accept", "b").
+		WithQuery("q", "1").
+		WithQuery("q", "2").
+		WithCookie("session", "abc").
+		WithBody("hello").
+		Build()
+
+	assert.Equal(t, "GET /users/{id}", r.RouteKey)
+	assert.Equal(t, "GET /users/{id}", r.RequestContext.RouteKey)
+	assert.Equal(t, http.MethodGet, r.RequestContext.HTTP.Method)
+	assert.Equal(t, "/users/123", r.RawPath)
+	assert.Equal(t, "a,b", r.Headers["accept"])
+	assert.Equal(t, "1,2", r.QueryStringParameters["q"])
+	assert.Equal(t, "q=1&q=2", r.RawQueryString)
+	assert.Equal(t, []string{"session=abc"}, r.Cookies)
+	assert.Equal(t, "hello", r.Body)
+}
+
+func TestNewSQSEvent(t *testing.T) {
+	e := NewSQSEvent(
+		NewSQSMessage("one").WithMessageID("1").WithMessageAttribute("type", "created").Build(),
+		NewJSONSQSMessage(map[string]int{"n": 2}).WithQueueARN("arn:queue").WithAttribute("MessageGroupId", "g").Build(),
+	)
+
+	require.Len(t, e.Records, 2)
+	assert.Equal(t, "1", e.Records[0].MessageId)
+	assert.Equal(t, "one", e.Records[0].Body)
+	assert.Equal(t, "created", *e.Records[0].MessageAttributes["type"].StringValue)
+	assert.JSONEq(t, `{"n":2}`, e.Records[1].Body)
+	assert.Equal(t, "arn:queue", e.Records[1].EventSourceARN)
+	assert.Equal(t, "g", e.Records[1].Attributes["MessageGroupId"])
+	assert.Equal(t, "1", e.Records[1].Attributes["ApproximateReceiveCount"])
+}
+
+func TestNewSNSEvent(t *testing.T) {
+	e := NewSNSEvent(NewJSONSNSRecord(map[string]int{"n": 1}).
+		WithTopicARN("arn:topic").
+		WithSubject("subject").
+		WithMessageAttribute("type", "created").
+		Build())
+
+	require.Len(t, e.Records, 1)
+	assert.JSONEq(t, `{"n":1}`, e.Records[0].SNS.Message)
+	assert.Equal(t, "arn:topic", e.Records[0].SNS.TopicArn)
+	assert.Equal(t, "subject", e.Records[0].SNS.Subject)
+	assert.Equal(t, map[string]any{"Type": "String", "Value": "created"}, e.Records[0].SNS.MessageAttributes["type"])
+}
+
+func TestNewS3Event(t *testing.T) {
+	e := NewS3Event(NewS3Record("ObjectCreated:Put", "bucket", "path/to a+b.txt").WithSize(10).Build())
+
+	require.Len(t, e.Records, 1)
+	assert.Equal(t, "bucket", e.Records[0].S3.Bucket.Name)
+	assert.Equal(t, "path%2Fto+a%2Bb.txt", e.Records[0].S3.Object.Key)
+	assert.Equal(t, "path/to a+b.txt", e.Records[0].S3.Object.URLDecodedKey)
+	assert.Equal(t, int64(10), e.Records[0].S3.Object.Size)
+
+	// The builder matches the key decoding applied when unmarshalling a real notification
+	b, err := json.Marshal(e)
+	require.NoError(t, err)
+	var decoded events.S3Event
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, "path/to a+b.txt", decoded.Records[0].S3.Object.URLDecodedKey)
+}
+
+func TestNewEventBridgeEvent(t *testing.T) {
+	e := NewEventBridgeEvent("orders", "OrderPlaced").
+		WithDetail(map[string]string{"id": "1"}).
+		WithResources("arn:a", "arn:b").
+		Build()
+
+	assert.Equal(t, "orders", e.Source)
+	assert.Equal(t, "OrderPlaced", e.DetailType)
+	assert.JSONEq(t, `{"id":"1"}`, string(e.Detail))
+	assert.Equal(t, []string{"arn:a", "arn:b"}, e.Resources)
+}
+
+func TestNewDynamoDBEvent(t *testing.T) {
+	keys := map[string]events.DynamoDBAttributeValue{"pk": events.NewStringAttribute("1")}
+	image := map[string]events.DynamoDBAttributeValue{"pk": events.NewStringAttribute("1"), "n": events.NewNumberAttribute("2")}
+
+	e := NewDynamoDBEvent(
+		NewDynamoDBInsert(keys, image).Build(),
+		NewDynamoDBModify(keys, image, image).WithSequenceNumber("2").Build(),
+		NewDynamoDBRemove(keys, image).Build(),
+	)
+
+	require.Len(t, e.Records, 3)
+	assert.Equal(t, "INSERT", e.Records[0].EventName)
+	assert.Equal(t, image, e.Records[0].Change.NewImage)
+	assert.Nil(t, e.Records[0].Change.OldImage)
+	assert.Equal(t, "MODIFY", e.Records[1].EventName)
+	assert.Equal(t, "2", e.Records[1].Change.SequenceNumber)
+	assert.Equal(t, "REMOVE", e.Records[2].EventName)
+	assert.Equal(t, image, e.Records[2].Change.OldImage)
+	assert.Nil(t, e.Records[2].Change.NewImage)
+}
@@ -0,0 +1,133 @@
+package lambdatest
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+
+	"github.com/ellogroup/ello-golang-aws/v2/lambda"
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/middleware"
+)
+
+// Result is the outcome of invoking a handler that does not return a response.
+type Result struct {
+	// Err is the error returned by the handler (and its middleware).
+	Err error
+	// Records are the log records written during the invocation.
+	Records []slog.Record
+}
+
+// ResultWithResponse [R any] is the outcome of invoking a handler that returns a response type R.
+type ResultWithResponse[R any] struct {
+	// Response is the response returned by the handler (and its middleware).
+	Response R
+	// Err is the error returned by the handler (and its middleware).
+	Err error
+	// Records are the log records written during the invocation.
+	Records []slog.Record
+}
+
+// Invoker [E any] invokes a handler of event type E that does not return a response, through its middleware, exactly
+// as lambda.Start does. Invocations through the same Invoker share a container, so only the first is a cold start.
+//
+// An Invoker is not safe for concurrent use.
+type Invoker[E any] struct {
+	handlerFn func(context.Context, E) error
+	logs      *LogRecorder
+}
+
+// NewInvoker returns an Invoker for handler. middlewares builds the middleware for the handler from a logger writing
+// to the Invoker's LogRecorder - e.g. middleware.Common[E] or middleware.CommonSQS - and may be nil.
+func NewInvoker[E any, M ~[]middleware.NoResponse[E]](handler lambda.Handler[E], middlewares func(*slog.Logger) M) *Invoker[E] {
+	logs := NewLogRecorder()
+	var m M
+	if middlewares != nil {
+		m = middlewares(slog.New(logs))
+	}
+	return &Invoker[E]{
+		handlerFn: lambda.HandlerFn(handler, m),
+		logs:      logs,
+	}
+}
+
+// Invoke invokes the handler with event. If ctx does not carry a Lambda context, one is added with NewContext.
+func (i *Invoker[E]) Invoke(ctx context.Context, event E) Result {
+	ctx, cancel := invocationContext(ctx)
+	defer cancel()
+
+	from := i.logs.len()
+	err := i.handlerFn(ctx, event)
+	return Result{
+		Err:     err,
+		Records: i.logs.since(from),
+	}
+}
+
+// Logs returns the LogRecorder of every invocation.
+func (i *Invoker[E]) Logs() *LogRecorder {
+	return i.logs
+}
+
+// Invoke invokes handler with event through its middleware, exactly as lambda.Start would, as the first invocation
+// of a new container. See NewInvoker.
+func Invoke[E any, M ~[]middleware.NoResponse[E]](ctx context.Context, handler lambda.Handler[E], middlewares func(*slog.Logger) M, event E) Result {
+	return NewInvoker(handler, middlewares).Invoke(ctx, event)
+}
+
+// InvokerWithResponse [E, R any] invokes a handler of event type E that returns a response type R, through its
+// middleware, exactly as lambda.StartWithResponse does. Invocations through the same InvokerWithResponse share a
+// container, so only the first is a cold start.
+//
+// An InvokerWithResponse is not safe for concurrent use.
+type InvokerWithResponse[E, R any] struct {
+	handlerFn func(context.Context, E) (R, error)
+	logs      *LogRecorder
+}
+
+// NewInvokerWithResponse returns an InvokerWithResponse for handler. middlewares builds the middleware for the handler
+// from a logger writing to the InvokerWithResponse's LogRecorder - e.g. middleware.CommonAPIGatewayV1 - and may be
+// nil.
+func NewInvokerWithResponse[E, R any, M ~[]middleware.WithResponse[E, R]](handler lambda.HandlerWithResponse[E, R], middlewares func(*slog.Logger) M) *InvokerWithResponse[E, R] {
+	logs := NewLogRecorder()
+	var m M
+	if middlewares != nil {
+		m = middlewares(slog.New(logs))
+	}
+	return &InvokerWithResponse[E, R]{
+		handlerFn: lambda.HandlerWithResponseFn(handler, m),
+		logs:      logs,
+	}
+}
+
+// Invoke invokes the handler with event. If ctx does not carry a Lambda context, one is added with NewContext.
+func (i *InvokerWithResponse[E, R]) Invoke(ctx context.Context, event E) ResultWithResponse[R] {
+	ctx, cancel := invocationContext(ctx)
+	defer cancel()
+
+	from := i.logs.len()
+	response, err := i.handlerFn(ctx, event)
+	return ResultWithResponse[R]{
+		Response: response,
+		Err:      err,
+		Records:  i.logs.since(from),
+	}
+}
+
+// Logs returns the LogRecorder of every invocation.
+func (i *InvokerWithResponse[E, R]) Logs() *LogRecorder {
+	return i.logs
+}
+
+// InvokeWithResponse invokes handler with event through its middleware, exactly as lambda.StartWithResponse would,
+// as the first invocation of a new container. See NewInvokerWithResponse.
+func InvokeWithResponse[E, R any, M ~[]middleware.WithResponse[E, R]](ctx context.Context, handler lambda.HandlerWithResponse[E, R], middlewares func(*slog.Logger) M, event E) ResultWithResponse[R] {
+	return NewInvokerWithResponse(handler, middlewares).Invoke(ctx, event)
+}
+
+func invocationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := lambdacontext.FromContext(ctx); ok {
+		return ctx, func() {}
+	}
+	return NewContext(ctx)
+}
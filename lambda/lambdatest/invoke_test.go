@@ -0,0 +1,197 @@
+package lambdatest
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ellogroup/ello-golang-aws/v2/lambda"
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/middleware"
+)
+
+type handlerFunc[E any] func(context.Context, E) error
+
+func (f handlerFunc[E]) Handle(ctx context.Context, event E) error {
+	return f(ctx, event)
+}
+
+type handlerWithResponseFunc[E, R any] func(context.Context, E) (R, error)
+
+func (f handlerWithResponseFunc[E, R]) Handle(ctx context.Context, event E) (R, error) {
+	return f(ctx, event)
+}
+
+func TestInvoke(t *testing.T) {
+	handlerErr := errors.New("handler error")
+	handler := handlerFunc[events.SQSEvent](func(ctx context.Context, event events.SQSEvent) error {
+		lc, ok := lambdacontext.FromContext(ctx)
+		require.True(t, ok)
+		assert.NotEmpty(t, lc.AwsRequestID)
+		_, ok = ctx.Deadline()
+		assert.True(t, ok)
+		assert.True(t, lambda.IsColdStart(ctx))
+		assert.Equal(t, "body", event.Records[0].Body)
+		return handlerErr
+	})
+
+	result := Invoke(context.Background(), handler, middleware.CommonSQS, NewSQSEvent(NewSQSMessage("body").Build()))
+
+	assert.ErrorIs(t, result.Err, handlerErr)
+	require.NotEmpty(t, result.Records)
+	assert.Equal(t, "Request started", result.Records[0].Message)
+}
+
+func TestInvoker_Invoke(t *testing.T) {
+	var coldStarts []bool
+	handler := handlerFunc[events.SNSEvent](func(ctx context.Context, _ events.SNSEvent) error {
+		coldStarts = append(coldStarts, lambda.IsColdStart(ctx))
+		return nil
+	})
+	invoker := NewInvoker(handler, middleware.CommonSNS)
+
+	first := invoker.Invoke(context.Background(), NewSNSEvent(NewSNSRecord("one").Build()))
+	second := invoker.Invoke(context.Background(), NewSNSEvent(NewSNSRecord("two").Build()))
+
+	assert.NoError(t, first.Err)
+	assert.NoError(t, second.Err)
+	assert.Equal(t, []bool{true, false}, coldStarts)
+	assert.NotEmpty(t, second.Records)
+	assert.Len(t, invoker.Logs().Records(), len(first.Records)+len(second.Records))
+}
+
+func TestInvoke_ExistingLambdaContext(t *testing.T) {
+	ctx, cancel := NewContext(context.Background(), WithRequestID("req-1"))
+	defer cancel()
+
+	var requestID string
+	handler := handlerFunc[events.S3Event](func(ctx context.Context, _ events.S3Event) error {
+		lc, _ := lambdacontext.FromContext(ctx)
+		requestID = lc.AwsRequestID
+		return nil
+	})
+
+	result := Invoke[events.S3Event, []middleware.NoResponse[events.S3Event]](ctx, handler, nil, NewS3Event())
+
+	assert.NoError(t, result.Err)
+	assert.Empty(t, result.Records)
+	assert.Equal(t, "req-1", requestID)
+}
+
+func TestInvokeWithResponse(t *testing.T) {
+	handler := handlerWithResponseFunc[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](
+		func(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			middleware.LoggerFromContext(ctx, slog.Default()).InfoContext(ctx, "Handling", "id", event.PathParameters["id"])
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: event.PathParameters["id"]}, nil
+		})
+
+	event := NewAPIGatewayV1Request(http.MethodGet, "/users/123").
+		WithResource("/users/{id}").
+		WithPathParameter("id", "123").
+		Build()
+	result := InvokeWithResponse(context.Background(), handler, middleware.CommonAPIGatewayV1, event)
+
+	assert.NoError(t, result.Err)
+	assert.Equal(t, http.StatusOK, result.Response.StatusCode)
+	assert.Equal(t, "123", result.Response.Body)
+	assert.NotEmpty(t, result.Records)
+}
+
+func TestNewContext(t *testing.T) {
+	deadline := time.Now().Add(time.Minute).Truncate(time.Second)
+	ctx, cancel := NewContext(context.Background(),
+		WithRequestID("req-1"),
+		WithInvokedFunctionArn("arn:aws:lambda:eu-west-2:123456789012:function:fn"),
+		WithDeadline(deadline),
+	)
+	defer cancel()
+
+	lc, ok := lambdacontext.FromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "req-1", lc.AwsRequestID)
+	assert.Equal(t, "arn:aws:lambda:eu-west-2:123456789012:function:fn", lc.InvokedFunctionArn)
+	got, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.Equal(t, deadline, got)
+}
+
+func TestNewContext_DefaultTimeout(t *testing.T) {
+	ctx, cancel := NewContext(context.Background())
+	defer cancel()
+
+	got, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(DefaultTimeout), got, time.Second)
+}
+
+func TestLogRecorder(t *testing.T) {
+	logs := NewLogRecorder()
+	logger := slog.New(logs).With("service", "test").WithGroup("request")
+
+	logger.Debug("first", "id", 1)
+	logger.Error("second")
+
+	assert.Equal(t, []string{"first", "second"}, logs.Messages())
+	r, ok := logs.Find("first")
+	require.True(t, ok)
+	attrs := Attrs(r)
+	assert.Equal(t, "test", attrs["service"].String())
+	assert.Equal(t, slog.KindGroup, attrs["request"].Kind())
+	_, ok = logs.Find("third")
+	assert.False(t, ok)
+}
+
+func TestLogRecorder_groupsAndAttrs(t *testing.T) {
+	tests := []struct {
+		name   string
+		logger func(*slog.Logger) *slog.Logger
+		want   []slog.Attr
+	}{
+		{
+			name:   "no groups",
+			logger: func(l *slog.Logger) *slog.Logger { return l.With("a", 1) },
+			want:   []slog.Attr{slog.Int("a", 1), slog.Int("id", 1)},
+		},
+		{
+			name:   "attrs before group, outside it",
+			logger: func(l *slog.Logger) *slog.Logger { return l.With("a", 1).WithGroup("g").With("b", 2) },
+			want:   []slog.Attr{slog.Int("a", 1), slog.Group("g", slog.Int("b", 2), slog.Int("id", 1))},
+		},
+		{
+			name:   "nested groups",
+			logger: func(l *slog.Logger) *slog.Logger { return l.WithGroup("a").With("x", 1).WithGroup("b") },
+			want:   []slog.Attr{slog.Group("a", slog.Int("x", 1), slog.Group("b", slog.Int("id", 1)))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := NewLogRecorder()
+			tt.logger(slog.New(logs)).Info("msg", "id", 1)
+
+			r, ok := logs.Find("msg")
+			require.True(t, ok)
+			var got []slog.Attr
+			r.Attrs(func(a slog.Attr) bool {
+				got = append(got, a)
+				return true
+			})
+			assert.Equal(t, len(tt.want), len(got))
+			for i := range min(len(tt.want), len(got)) {
+				assert.True(t, tt.want[i].Equal(got[i]), "attr %d: want %s, got %s", i, tt.want[i], got[i])
+			}
+		})
+	}
+
+	// A group without attributes is omitted.
+	logs := NewLogRecorder()
+	slog.New(logs).WithGroup("g").Info("msg")
+	r, _ := logs.Find("msg")
+	assert.Equal(t, 0, r.NumAttrs())
+}
@@ -0,0 +1,135 @@
+package lambdatest
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// LogRecorder is a slog.Handler that records every log record it handles, at every level, for assertions.
+type LogRecorder struct {
+	mu      *sync.Mutex
+	records *[]slog.Record
+	// goas are the groups and attributes added with WithGroup and WithAttrs, in the order they were added.
+	goas []groupOrAttrs
+}
+
+// groupOrAttrs is either a group opened with WithGroup or attributes added with WithAttrs.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// NewLogRecorder returns an empty LogRecorder. Use it with slog.New to create a logger for the code under test.
+func NewLogRecorder() *LogRecorder {
+	return &LogRecorder{
+		mu:      &sync.Mutex{},
+		records: &[]slog.Record{},
+	}
+}
+
+// Enabled implements slog.Handler. Every level is enabled.
+func (*LogRecorder) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle implements slog.Handler, recording r with any groups and attributes added with WithGroup and WithAttrs, nested
+// as a slog.JSONHandler would write them.
+func (l *LogRecorder) Handle(_ context.Context, r slog.Record) error {
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	for i := len(l.goas) - 1; i >= 0; i-- {
+		goa := l.goas[i]
+		if goa.group == "" {
+			attrs = append(slices.Clone(goa.attrs), attrs...)
+			continue
+		}
+		if len(attrs) == 0 {
+			// A group without attributes is omitted.
+			continue
+		}
+		attrs = []slog.Attr{{Key: goa.group, Value: slog.GroupValue(attrs...)}}
+	}
+	recorded := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	recorded.AddAttrs(attrs...)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	*l.records = append(*l.records, recorded)
+	return nil
+}
+
+// WithAttrs implements slog.Handler. The attributes are placed in any groups opened with WithGroup before them.
+func (l *LogRecorder) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return l
+	}
+	return l.with(groupOrAttrs{attrs: attrs})
+}
+
+// WithGroup implements slog.Handler. The attributes added after it, and those of the record, are placed in the group.
+func (l *LogRecorder) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return l
+	}
+	return l.with(groupOrAttrs{group: name})
+}
+
+// Records returns every record recorded so far.
+func (l *LogRecorder) Records() []slog.Record {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(*l.records)
+}
+
+// Messages returns the message of every record recorded so far.
+func (l *LogRecorder) Messages() []string {
+	records := l.Records()
+	messages := make([]string, 0, len(records))
+	for _, r := range records {
+		messages = append(messages, r.Message)
+	}
+	return messages
+}
+
+// Find returns the first record recorded with msg.
+func (l *LogRecorder) Find(msg string) (slog.Record, bool) {
+	for _, r := range l.Records() {
+		if r.Message == msg {
+			return r, true
+		}
+	}
+	return slog.Record{}, false
+}
+
+// Attrs returns the attributes of r, keyed by name.
+func Attrs(r slog.Record) map[string]slog.Value {
+	attrs := make(map[string]slog.Value, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value
+		return true
+	})
+	return attrs
+}
+
+func (l *LogRecorder) with(goa groupOrAttrs) *LogRecorder {
+	c := *l
+	c.goas = append(slices.Clone(l.goas), goa)
+	return &c
+}
+
+func (l *LogRecorder) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(*l.records)
+}
+
+func (l *LogRecorder) since(i int) []slog.Record {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone((*l.records)[i:])
+}
@@ -0,0 +1,370 @@
+package lambdatest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	runtimeAPIEnv          = "AWS_LAMBDA_RUNTIME_API"
+	defaultRuntimeStartup  = 5 * time.Second
+	headerRequestID        = "Lambda-Runtime-Aws-Request-Id"
+	headerDeadlineMS       = "Lambda-Runtime-Deadline-Ms"
+	headerFunctionArn      = "Lambda-Runtime-Invoked-Function-Arn"
+	headerTraceID          = "Lambda-Runtime-Trace-Id"
+	headerExtensionName    = "Lambda-Extension-Name"
+	headerExtensionID      = "Lambda-Extension-Identifier"
+	defaultRuntimeFunction = "arn:aws:lambda:" + Region + ":" + AccountID + ":function:lambdatest"
+)
+
+var (
+	// ErrRuntimeClosed is returned by Runtime.Invoke once the Runtime is closed.
+	ErrRuntimeClosed = errors.New("lambdatest: runtime closed")
+	// ErrRuntimeNotStarted is returned by Runtime.Start when the runtime does not request its first invocation in
+	// time.
+	ErrRuntimeNotStarted = errors.New("lambdatest: runtime did not request an invocation")
+	// ErrSIGTERMNotEnabled is returned by Runtime.Shutdown when the runtime has not registered an extension, which
+	// Lambda requires before it sends SIGTERM.
+	ErrSIGTERMNotEnabled = errors.New("lambdatest: SIGTERM not enabled, no extension registered")
+)
+
+// InvocationError is an error reported by a runtime, to the error or init error endpoints of the Runtime API.
+type InvocationError struct {
+	Message    string          `json:"errorMessage"`
+	Type       string          `json:"errorType"`
+	StackTrace json.RawMessage `json:"stackTrace,omitempty"`
+}
+
+// Error implements error.
+func (e *InvocationError) Error() string {
+	return e.Type + ": " + e.Message
+}
+
+type runtimeOptions struct {
+	functionArn     string
+	functionTimeout time.Duration
+	startTimeout    time.Duration
+}
+
+// RuntimeOption configures NewRuntime.
+type RuntimeOption func(*runtimeOptions)
+
+// WithRuntimeFunctionArn sets the invoked function ARN of each invocation.
+func WithRuntimeFunctionArn(arn string) RuntimeOption {
+	return func(o *runtimeOptions) {
+		o.functionArn = arn
+	}
+}
+
+// WithRuntimeFunctionTimeout sets the time from each invocation until its deadline. Defaults to DefaultTimeout.
+func WithRuntimeFunctionTimeout(d time.Duration) RuntimeOption {
+	return func(o *runtimeOptions) {
+		o.functionTimeout = d
+	}
+}
+
+// WithRuntimeStartTimeout sets how long Runtime.Start waits for the runtime to request its first invocation. Defaults
+// to 5s.
+func WithRuntimeStartTimeout(d time.Duration) RuntimeOption {
+	return func(o *runtimeOptions) {
+		o.startTimeout = d
+	}
+}
+
+type runtimeResult struct {
+	payload json.RawMessage
+	err     error
+}
+
+type runtimeInvocation struct {
+	id      string
+	payload []byte
+	result  chan runtimeResult
+}
+
+// Runtime is a local implementation of the Lambda Runtime API (next invocation, response, error and init error) and
+// the Extensions API registration used to enable SIGTERM, listening on a loopback port. It feeds events to a handler
+// started with lambda.Start or lambda.StartWithResponse, exercising the real bootstrap path without deploying.
+//
+//	rt, _ := lambdatest.NewRuntime()
+//	defer rt.Close()
+//	_ = rt.Start(func() { lambda.Start(handler, middlewares, onShutdown) })
+//	payload, err := rt.Invoke(ctx, event)
+//
+// lambda.Start never returns, and exits the process if the Runtime API becomes unavailable, so a Runtime keeps
+// serving the runtime it started for the life of the process. As on Lambda, a handler that panics exits the process.
+type Runtime struct {
+	opts        runtimeOptions
+	listener    net.Listener
+	server      *http.Server
+	invocations chan *runtimeInvocation
+	polled      chan struct{}
+	pollOnce    sync.Once
+	initErr     chan *InvocationError
+	closed      chan struct{}
+	closeOnce   sync.Once
+
+	mu         sync.Mutex
+	pending    map[string]*runtimeInvocation
+	extensions int
+}
+
+// NewRuntime starts a Runtime listening on a random loopback port.
+func NewRuntime(options ...RuntimeOption) (*Runtime, error) {
+	opts := runtimeOptions{
+		functionArn:     defaultRuntimeFunction,
+		functionTimeout: DefaultTimeout,
+		startTimeout:    defaultRuntimeStartup,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("lambdatest: listening for runtime API: %w", err)
+	}
+
+	r := &Runtime{
+		opts:        opts,
+		listener:    listener,
+		invocations: make(chan *runtimeInvocation),
+		polled:      make(chan struct{}),
+		initErr:     make(chan *InvocationError, 1),
+		closed:      make(chan struct{}),
+		pending:     map[string]*runtimeInvocation{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /2018-06-01/runtime/invocation/next", r.next)
+	mux.HandleFunc("POST /2018-06-01/runtime/invocation/{id}/response", r.response)
+	mux.HandleFunc("POST /2018-06-01/runtime/invocation/{id}/error", r.invocationError)
+	mux.HandleFunc("POST /2018-06-01/runtime/init/error", r.initError)
+	mux.HandleFunc("POST /2020-01-01/extension/register", r.registerExtension)
+	mux.HandleFunc("GET /2020-01-01/extension/event/next", r.nextExtensionEvent)
+	r.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		_ = r.server.Serve(listener)
+	}()
+	return r, nil
+}
+
+// Addr returns the host:port the Runtime is listening on, the value of AWS_LAMBDA_RUNTIME_API for a runtime using it.
+func (r *Runtime) Addr() string {
+	return r.listener.Addr().String()
+}
+
+// Start points AWS_LAMBDA_RUNTIME_API at the Runtime and calls start on a new goroutine - typically a func calling
+// lambda.Start or lambda.StartWithResponse. It returns once the runtime has requested its first invocation, restoring
+// AWS_LAMBDA_RUNTIME_API, or with the InvocationError the runtime reported to the init error endpoint.
+//
+// Start modifies the environment of the process, so must not be used by parallel tests.
+func (r *Runtime) Start(start func()) error {
+	previous, set := os.LookupEnv(runtimeAPIEnv)
+	if err := os.Setenv(runtimeAPIEnv, r.Addr()); err != nil {
+		return fmt.Errorf("lambdatest: setting %s: %w", runtimeAPIEnv, err)
+	}
+	defer func() {
+		if set {
+			_ = os.Setenv(runtimeAPIEnv, previous)
+		} else {
+			_ = os.Unsetenv(runtimeAPIEnv)
+		}
+	}()
+
+	go start()
+
+	timer := time.NewTimer(r.opts.startTimeout)
+	defer timer.Stop()
+	select {
+	case <-r.polled:
+		return nil
+	case err := <-r.initErr:
+		return err
+	case <-timer.C:
+		return ErrRuntimeNotStarted
+	}
+}
+
+// Invoke invokes the runtime with event, returning the response payload or, if the handler returned an error, an
+// *InvocationError. event is encoded as JSON unless it is a []byte or json.RawMessage.
+//
+// If ctx is done, or the Runtime closed, once the runtime has received the event, Invoke returns without waiting for
+// the handler, which carries on running; its response is accepted and discarded.
+func (r *Runtime) Invoke(ctx context.Context, event any) (json.RawMessage, error) {
+	var payload []byte
+	switch e := event.(type) {
+	case []byte:
+		payload = e
+	case json.RawMessage:
+		payload = e
+	default:
+		var err error
+		if payload, err = json.Marshal(event); err != nil {
+			return nil, fmt.Errorf("lambdatest: encoding event: %w", err)
+		}
+	}
+
+	inv := &runtimeInvocation{
+		id:      NewRequestID(),
+		payload: payload,
+		result:  make(chan runtimeResult, 1),
+	}
+	r.mu.Lock()
+	r.pending[inv.id] = inv
+	r.mu.Unlock()
+
+	select {
+	case r.invocations <- inv:
+	case <-r.closed:
+		r.forget(inv.id)
+		return nil, ErrRuntimeClosed
+	case <-ctx.Done():
+		r.forget(inv.id)
+		return nil, ctx.Err()
+	}
+
+	// Once dispatched, the invocation stays pending until the runtime responds, even if Invoke returns first, as
+	// aws-lambda-go exits the process if its response is rejected.
+	select {
+	case result := <-inv.result:
+		return result.payload, result.err
+	case <-r.closed:
+		return nil, ErrRuntimeClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Close stops the Runtime accepting invocations. The runtime started with Start is left waiting for its next
+// invocation.
+func (r *Runtime) Close() {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+	})
+}
+
+func (r *Runtime) next(w http.ResponseWriter, req *http.Request) {
+	r.pollOnce.Do(func() {
+		close(r.polled)
+	})
+
+	var inv *runtimeInvocation
+	select {
+	case inv = <-r.invocations:
+	case <-req.Context().Done():
+		return
+	}
+
+	w.Header().Set(headerRequestID, inv.id)
+	w.Header().Set(headerDeadlineMS, strconv.FormatInt(time.Now().Add(r.opts.functionTimeout).UnixMilli(), 10))
+	w.Header().Set(headerFunctionArn, r.opts.functionArn)
+	w.Header().Set(headerTraceID, newTraceID())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(inv.payload)
+}
+
+func (r *Runtime) response(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.complete(w, req.PathValue("id"), runtimeResult{payload: body})
+}
+
+func (r *Runtime) invocationError(w http.ResponseWriter, req *http.Request) {
+	invErr, err := decodeInvocationError(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.complete(w, req.PathValue("id"), runtimeResult{err: invErr})
+}
+
+func (r *Runtime) complete(w http.ResponseWriter, id string, result runtimeResult) {
+	r.mu.Lock()
+	inv, ok := r.pending[id]
+	delete(r.pending, id)
+	r.mu.Unlock()
+	if !ok {
+		http.Error(w, "unknown request id "+id, http.StatusBadRequest)
+		return
+	}
+	// result is buffered, so the response of an invocation Invoke no longer waits for is discarded.
+	inv.result <- result
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (r *Runtime) initError(w http.ResponseWriter, req *http.Request) {
+	invErr, err := decodeInvocationError(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	select {
+	case r.initErr <- invErr:
+	default:
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (r *Runtime) registerExtension(w http.ResponseWriter, req *http.Request) {
+	_, _ = io.Copy(io.Discard, req.Body)
+	r.mu.Lock()
+	r.extensions++
+	r.mu.Unlock()
+
+	w.Header().Set(headerExtensionID, NewRequestID())
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"functionName":    "lambdatest",
+		"functionVersion": "$LATEST",
+		"handler":         req.Header.Get(headerExtensionName),
+	})
+}
+
+// nextExtensionEvent never returns an event, like Lambda for an extension registered for no events.
+func (*Runtime) nextExtensionEvent(_ http.ResponseWriter, req *http.Request) {
+	<-req.Context().Done()
+}
+
+func (r *Runtime) forget(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.pending, id)
+}
+
+func (r *Runtime) sigtermEnabled() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.extensions > 0
+}
+
+func newTraceID() string {
+	id := strings.ReplaceAll(NewRequestID(), "-", "")
+	return fmt.Sprintf("Root=1-%08x-%s;Sampled=0", time.Now().Unix(), id[:24])
+}
+
+func decodeInvocationError(req *http.Request) (*InvocationError, error) {
+	var invErr InvocationError
+	if err := json.NewDecoder(req.Body).Decode(&invErr); err != nil {
+		return nil, fmt.Errorf("decoding error: %w", err)
+	}
+	if errorType := req.Header.Get("Lambda-Runtime-Function-Error-Type"); errorType != "" && invErr.Type == "" {
+		invErr.Type = errorType
+	}
+	return &invErr, nil
+}
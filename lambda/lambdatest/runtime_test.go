@@ -0,0 +1,164 @@
+package lambdatest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ellogroup/ello-golang-aws/v2/lambda"
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/middleware"
+)
+
+func newTestRuntime(t *testing.T, options ...RuntimeOption) *Runtime {
+	t.Helper()
+	rt, err := NewRuntime(options...)
+	require.NoError(t, err)
+	t.Cleanup(rt.Close)
+	return rt
+}
+
+func TestRuntime_Invoke(t *testing.T) {
+	rt := newTestRuntime(t, WithRuntimeFunctionArn("arn:aws:lambda:eu-west-2:123456789012:function:fn"))
+
+	var coldStarts []bool
+	var arn string
+	handler := handlerWithResponseFunc[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](
+		func(ctx context.Context, event events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			coldStarts = append(coldStarts, lambda.IsColdStart(ctx))
+			lc, _ := lambdacontext.FromContext(ctx)
+			arn = lc.InvokedFunctionArn
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: event.Path}, nil
+		})
+	require.NoError(t, rt.Start(func() {
+		lambda.StartWithResponse(handler, middleware.CommonAPIGatewayV1(slog.New(NewLogRecorder())))
+	}))
+
+	for _, path := range []string{"/one", "/two"} {
+		payload, err := rt.Invoke(context.Background(), NewAPIGatewayV1Request(http.MethodGet, path).Build())
+		require.NoError(t, err)
+
+		var response events.APIGatewayProxyResponse
+		require.NoError(t, json.Unmarshal(payload, &response))
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, path, response.Body)
+	}
+	assert.Equal(t, []bool{true, false}, coldStarts)
+	assert.Equal(t, "arn:aws:lambda:eu-west-2:123456789012:function:fn", arn)
+}
+
+func TestRuntime_Invoke_Error(t *testing.T) {
+	rt := newTestRuntime(t)
+
+	handler := handlerFunc[events.SQSEvent](func(context.Context, events.SQSEvent) error {
+		return errors.New("handler error")
+	})
+	require.NoError(t, rt.Start(func() {
+		lambda.Start(handler, nil)
+	}))
+
+	_, err := rt.Invoke(context.Background(), NewSQSEvent(NewSQSMessage("body").Build()))
+
+	var invErr *InvocationError
+	require.ErrorAs(t, err, &invErr)
+	assert.Equal(t, "handler error", invErr.Message)
+	assert.Equal(t, "errorString", invErr.Type)
+}
+
+func TestRuntime_Shutdown(t *testing.T) {
+	rt := newTestRuntime(t)
+
+	shutdown := make(chan struct{})
+	handler := handlerFunc[events.SQSEvent](func(context.Context, events.SQSEvent) error {
+		return nil
+	})
+	require.NoError(t, rt.Start(func() {
+		lambda.Start(handler, nil, func() { close(shutdown) })
+	}))
+
+	require.NoError(t, rt.Shutdown(context.Background()))
+
+	select {
+	case <-shutdown:
+	case <-time.After(time.Second):
+		t.Fatal("shutdown callback not called")
+	}
+}
+
+func TestRuntime_Shutdown_NotEnabled(t *testing.T) {
+	rt := newTestRuntime(t)
+
+	assert.ErrorIs(t, rt.Shutdown(context.Background()), ErrSIGTERMNotEnabled)
+}
+
+func TestRuntime_Start_InitError(t *testing.T) {
+	rt := newTestRuntime(t)
+
+	err := rt.Start(func() {
+		body := bytes.NewBufferString(`{"errorMessage":"bad config","errorType":"Runtime.ConfigError"}`)
+		resp, err := http.Post("http://"+rt.Addr()+"/2018-06-01/runtime/init/error", "application/json", body)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	})
+
+	var invErr *InvocationError
+	require.ErrorAs(t, err, &invErr)
+	assert.Equal(t, "bad config", invErr.Message)
+	assert.Equal(t, "Runtime.ConfigError", invErr.Type)
+}
+
+func TestRuntime_Start_Timeout(t *testing.T) {
+	rt := newTestRuntime(t, WithRuntimeStartTimeout(10*time.Millisecond))
+
+	assert.ErrorIs(t, rt.Start(func() {}), ErrRuntimeNotStarted)
+}
+
+func TestRuntime_Invoke_Closed(t *testing.T) {
+	rt := newTestRuntime(t)
+	rt.Close()
+
+	_, err := rt.Invoke(context.Background(), []byte(`{}`))
+
+	assert.ErrorIs(t, err, ErrRuntimeClosed)
+}
+
+func TestRuntime_Invoke_CancelledAfterDispatch(t *testing.T) {
+	rt := newTestRuntime(t)
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	handler := handlerWithResponseFunc[string, string](func(_ context.Context, event string) (string, error) {
+		if event == "slow" {
+			started <- struct{}{}
+			<-release
+		}
+		return event, nil
+	})
+	require.NoError(t, rt.Start(func() {
+		lambda.StartWithResponse(handler, nil)
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, err := rt.Invoke(ctx, "slow")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// The late response is discarded, and the runtime keeps serving.
+	close(release)
+	payload, err := rt.Invoke(context.Background(), "fast")
+	require.NoError(t, err)
+	assert.JSONEq(t, `"fast"`, string(payload))
+}
@@ -0,0 +1,38 @@
+//go:build unix
+
+package lambdatest
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Shutdown sends SIGTERM to the process, as Lambda does to a runtime that has registered an extension before shutting
+// down its container, triggering the callbacks passed to lambda.Start/lambda.StartWithResponse. The callbacks run
+// asynchronously; Shutdown returns once the signal has been delivered. It returns ErrSIGTERMNotEnabled if no
+// extension has been registered.
+//
+// The signal is delivered to the whole process, so the callbacks of every runtime started in the process are
+// triggered.
+func (r *Runtime) Shutdown(ctx context.Context) error {
+	if !r.sigtermEnabled() {
+		return ErrSIGTERMNotEnabled
+	}
+
+	// Keep the process alive, whether or not the runtime was started with callbacks
+	signaled := make(chan os.Signal, 1)
+	signal.Notify(signaled, syscall.SIGTERM)
+	defer signal.Stop(signaled)
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		return err
+	}
+	select {
+	case <-signaled:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
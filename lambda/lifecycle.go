@@ -0,0 +1,127 @@
+package lambda
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+
+	"github.com/ellogroup/ello-golang-aws/v2/internal/invocation"
+)
+
+// Invocation describes a single invocation of the handler on this container.
+type Invocation = invocation.Info
+
+// InvocationFromContext returns the Invocation of a handler started with Start/StartWithResponse.
+func InvocationFromContext(ctx context.Context) (Invocation, bool) {
+	return invocation.FromContext(ctx)
+}
+
+// IsColdStart returns true if ctx belongs to the first invocation on this container.
+func IsColdStart(ctx context.Context) bool {
+	info, ok := invocation.FromContext(ctx)
+	return ok && info.ColdStart
+}
+
+// ContainerStats describes the Lambda container (execution environment) the handler is running on.
+type ContainerStats struct {
+	// StartedAt is when the handler was started with Start/StartWithResponse.
+	StartedAt time.Time
+	// FirstInvocationAt is when the first (cold start) invocation was received, zero until then.
+	FirstInvocationAt time.Time
+	// Invocations is the number of invocations received so far.
+	Invocations uint64
+	// InitializationType is "on-demand", "provisioned-concurrency" or "snap-start".
+	InitializationType string
+	// FunctionName is the name of the Lambda function.
+	FunctionName string
+	// FunctionVersion is the version of the Lambda function.
+	FunctionVersion string
+	// MemoryLimitInMB is the memory configured for the Lambda function.
+	MemoryLimitInMB int
+}
+
+// Stats returns the ContainerStats of the handler started with Start/StartWithResponse.
+func Stats() ContainerStats {
+	if l := currentLifecycle.Load(); l != nil {
+		return l.stats()
+	}
+	return ContainerStats{}
+}
+
+var currentLifecycle atomic.Pointer[lifecycle]
+
+// lifecycle tracks the invocations received by a container.
+type lifecycle struct {
+	clock     clock.Clock
+	startedAt time.Time
+
+	mu                sync.Mutex
+	invocations       uint64
+	firstInvocationAt time.Time
+}
+
+func newLifecycle(clk clock.Clock) *lifecycle {
+	return &lifecycle{
+		clock:     clk,
+		startedAt: clk.Now(),
+	}
+}
+
+// startLifecycle creates the lifecycle for the container and makes it the one reported by Stats.
+func startLifecycle() *lifecycle {
+	l := newLifecycle(clock.NewSystem())
+	currentLifecycle.Store(l)
+	return l
+}
+
+// begin records a new invocation and returns a copy of ctx carrying its Invocation.
+func (l *lifecycle) begin(ctx context.Context) context.Context {
+	l.mu.Lock()
+	l.invocations++
+	seq := l.invocations
+	if seq == 1 {
+		l.firstInvocationAt = l.clock.Now()
+	}
+	l.mu.Unlock()
+
+	return invocation.NewContext(ctx, Invocation{
+		ColdStart:       seq == 1,
+		Seq:             seq,
+		FunctionName:    lambdacontext.FunctionName,
+		FunctionVersion: lambdacontext.FunctionVersion,
+		MemoryLimitInMB: lambdacontext.MemoryLimitInMB,
+	})
+}
+
+func (l *lifecycle) stats() ContainerStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return ContainerStats{
+		StartedAt:          l.startedAt,
+		FirstInvocationAt:  l.firstInvocationAt,
+		Invocations:        l.invocations,
+		InitializationType: os.Getenv("AWS_LAMBDA_INITIALIZATION_TYPE"),
+		FunctionName:       lambdacontext.FunctionName,
+		FunctionVersion:    lambdacontext.FunctionVersion,
+		MemoryLimitInMB:    lambdacontext.MemoryLimitInMB,
+	}
+}
+
+func trackedHandlerFn[E any](l *lifecycle, handlerFn func(context.Context, E) error) func(context.Context, E) error {
+	return func(ctx context.Context, event E) error {
+		return handlerFn(l.begin(ctx), event)
+	}
+}
+
+func trackedHandlerWithResponseFn[E, R any](l *lifecycle, handlerFn func(context.Context, E) (R, error)) func(context.Context, E) (R, error) {
+	return func(ctx context.Context, event E) (R, error) {
+		return handlerFn(l.begin(ctx), event)
+	}
+}
@@ -0,0 +1,91 @@
+package lambda
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+)
+
+func Test_lifecycle_begin(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+	l := newLifecycle(clock.NewFixed(now))
+
+	first := l.begin(context.Background())
+	second := l.begin(context.Background())
+
+	assert.True(t, IsColdStart(first))
+	assert.False(t, IsColdStart(second))
+
+	info, ok := InvocationFromContext(first)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), info.Seq)
+
+	info, ok = InvocationFromContext(second)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), info.Seq)
+
+	stats := l.stats()
+	assert.Equal(t, now, stats.StartedAt)
+	assert.Equal(t, now, stats.FirstInvocationAt)
+	assert.Equal(t, uint64(2), stats.Invocations)
+}
+
+func TestIsColdStart_notStarted(t *testing.T) {
+	assert.False(t, IsColdStart(context.Background()))
+
+	_, ok := InvocationFromContext(context.Background())
+	assert.False(t, ok)
+}
+
+func Test_trackedHandlerFn(t *testing.T) {
+	l := newLifecycle(clock.NewSystem())
+
+	var coldStarts []bool
+	fn := trackedHandlerFn(l, func(ctx context.Context, _ string) error {
+		coldStarts = append(coldStarts, IsColdStart(ctx))
+		return nil
+	})
+	_ = fn(context.Background(), "event")
+	_ = fn(context.Background(), "event")
+
+	assert.Equal(t, []bool{true, false}, coldStarts)
+}
+
+func Test_trackedHandlerWithResponseFn(t *testing.T) {
+	l := newLifecycle(clock.NewSystem())
+
+	fn := trackedHandlerWithResponseFn(l, func(ctx context.Context, _ string) (uint64, error) {
+		info, _ := InvocationFromContext(ctx)
+		return info.Seq, nil
+	})
+	first, _ := fn(context.Background(), "event")
+	second, _ := fn(context.Background(), "event")
+
+	assert.Equal(t, uint64(1), first)
+	assert.Equal(t, uint64(2), second)
+}
+
+func TestStats(t *testing.T) {
+	l := startLifecycle()
+	_ = l.begin(context.Background())
+
+	assert.Equal(t, uint64(1), Stats().Invocations)
+}
+
+func TestHandlerFn_doesNotResetStats(t *testing.T) {
+	l := startLifecycle()
+	_ = l.begin(context.Background())
+
+	h := &mockHandler[string]{}
+	h.On("Handle", mock.MatchedBy(IsColdStart), "event").Return(nil)
+	fn := HandlerFn[string](h, nil)
+	_ = fn(context.Background(), "event")
+
+	h.AssertExpectations(t)
+	assert.Equal(t, uint64(1), Stats().Invocations)
+}
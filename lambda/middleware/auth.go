@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ellogroup/ello-golang-ctx/v2/logctx"
+
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/jwt"
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/response"
+)
+
+const (
+	defaultAuthUnauthorizedMsg = "Request unauthorized"
+	defaultAuthForbiddenMsg    = "Request forbidden"
+	defaultAuthFailedMsg       = "Request authentication failed"
+)
+
+var (
+	// ErrUnauthorized is returned by the auth middleware for a request without a valid bearer token, for response
+	// types other than API Gateway v1 and v2.
+	ErrUnauthorized = errors.New("middleware: missing or invalid bearer token")
+	// ErrForbidden is returned by the auth middleware for a request whose bearer token is missing a required scope, for
+	// response types other than API Gateway v1 and v2.
+	ErrForbidden = errors.New("middleware: bearer token missing required scope")
+)
+
+// TokenVerifier interface should be implemented for verifiers of bearer tokens, usually a *jwt.Verifier. Fake it in
+// tests of handlers behind the auth middleware.
+type TokenVerifier interface {
+	// Verify verifies token, returning its claims.
+	Verify(ctx context.Context, token string) (jwt.Claims, error)
+}
+
+type authOptions struct {
+	scopes      []string
+	routeScopes map[string][]string
+}
+
+// AuthOption configures NewAuthWithResponse.
+type AuthOption func(*authOptions)
+
+// WithAuthScopes requires the bearer token of every request to have scopes.
+func WithAuthScopes(scopes ...string) AuthOption {
+	return func(o *authOptions) {
+		o.scopes = scopes
+	}
+}
+
+// WithAuthRouteScopes requires the bearer token of requests to a single API Gateway route to have scopes, in addition
+// to the scopes of WithAuthScopes. route is matched against the RouteKey of API Gateway v2 requests and
+// "<HTTP method> <resource>" (e.g. "GET /customers/{id}") of API Gateway v1 requests. Call it once per route.
+func WithAuthRouteScopes(route string, scopes ...string) AuthOption {
+	return func(o *authOptions) {
+		routeScopes := make(map[string][]string, len(o.routeScopes)+1)
+		for k, v := range o.routeScopes {
+			routeScopes[k] = v
+		}
+		routeScopes[route] = scopes
+		o.routeScopes = routeScopes
+	}
+}
+
+type authWithResponse[E, R any] struct {
+	logger   *slog.Logger
+	verifier TokenVerifier
+	opts     authOptions
+}
+
+// NewAuthWithResponse returns an implementation of WithResponse for the auth middleware.
+//
+// The auth middleware verifies the bearer token of the Authorization header of API Gateway v1 and v2 requests with
+// verifier and checks it has the required scopes (see WithAuthScopes and WithAuthRouteScopes). The claims of the token
+// are added to the context for the handler to retrieve with jwt.FromContext, and the subject to the logctx of the
+// request as "subject".
+//
+// A request without a valid bearer token gets a response.ErrorCodeUnauthorized (401) response, and one missing a
+// required scope a response.ErrorCodeForbidden (403) response. If the keys of the verifier are unavailable the request
+// gets a response.ErrorCodeInternalError (500) response. For other response types ErrUnauthorized, ErrForbidden or the
+// error of the verifier is returned instead.
+func NewAuthWithResponse[E, R any](logger *slog.Logger, verifier TokenVerifier, options ...AuthOption) WithResponse[E, R] {
+	var opts authOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	return &authWithResponse[E, R]{
+		logger:   logger,
+		verifier: verifier,
+		opts:     opts,
+	}
+}
+
+func (a authWithResponse[E, R]) Wrap(next func(context.Context, E) (R, error)) func(context.Context, E) (R, error) {
+	return func(ctx context.Context, event E) (R, error) {
+		token, ok := bearerToken(event)
+		if !ok {
+			a.logger.LogAttrs(ctx, slog.LevelInfo, defaultAuthUnauthorizedMsg, slog.String("reason", "missing bearer token"))
+			return authErrorResponse[R](response.ErrorCodeUnauthorized, ErrUnauthorized)
+		}
+
+		claims, err := a.verifier.Verify(ctx, token)
+		if errors.Is(err, jwt.ErrJWKSUnavailable) {
+			a.logger.LogAttrs(ctx, slog.LevelError, defaultAuthFailedMsg, errorAttr(err))
+			return authErrorResponse[R](response.ErrorCodeInternalError, err)
+		}
+		if err != nil {
+			a.logger.LogAttrs(ctx, slog.LevelInfo, defaultAuthUnauthorizedMsg, slog.String("reason", err.Error()))
+			return authErrorResponse[R](response.ErrorCodeUnauthorized, ErrUnauthorized)
+		}
+
+		ctx = logctx.Add(ctx, logctx.String("subject", claims.Subject))
+		if err := claims.RequireScopes(slices.Concat(a.opts.scopes, a.opts.routeScopes[routeKey(event)])...); err != nil {
+			a.logger.LogAttrs(ctx, slog.LevelInfo, defaultAuthForbiddenMsg, slog.String("reason", err.Error()))
+			return authErrorResponse[R](response.ErrorCodeForbidden, ErrForbidden)
+		}
+
+		return next(jwt.NewContext(ctx, claims), event)
+	}
+}
+
+// authErrorResponse returns a code response for API Gateway response types, or err for others.
+func authErrorResponse[R any](code response.ErrorCode, err error) (R, error) {
+	if res, ok := errorCodeResponse[R](code); ok {
+		return res, nil
+	}
+	var zero R
+	return zero, err
+}
+
+// bearerToken returns the bearer token of the Authorization header of API Gateway v1 and v2 requests.
+func bearerToken(event any) (string, bool) {
+	switch event.(type) {
+	case events.APIGatewayProxyRequest, events.APIGatewayV2HTTPRequest:
+	default:
+		return "", false
+	}
+	scheme, token, found := strings.Cut(strings.TrimSpace(requestHeader(event, "Authorization")), " ")
+	token = strings.TrimSpace(token)
+	return token, found && strings.EqualFold(scheme, "Bearer") && token != ""
+}
@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ellogroup/ello-golang-ctx/v2/logctx"
+
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/jwt"
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/response"
+)
+
+// fakeVerifier accepts "valid" with claims, and fails any other token with err.
+type fakeVerifier struct {
+	claims jwt.Claims
+	err    error
+}
+
+func (v fakeVerifier) Verify(_ context.Context, token string) (jwt.Claims, error) {
+	if token != "valid" {
+		return jwt.Claims{}, v.err
+	}
+	return v.claims, nil
+}
+
+func Test_authWithResponse_Wrap(t *testing.T) {
+	verifier := fakeVerifier{
+		claims: jwt.Claims{Subject: "user-123", Scopes: []string{"orders:read"}},
+		err:    jwt.ErrExpired,
+	}
+	tests := []struct {
+		name       string
+		verifier   TokenVerifier
+		options    []AuthOption
+		headers    map[string]string
+		wantStatus int
+		wantCalled bool
+	}{
+		{
+			name:       "valid bearer token, handler called",
+			verifier:   verifier,
+			options:    []AuthOption{WithAuthScopes("orders:read")},
+			headers:    map[string]string{"Authorization": "Bearer valid"},
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+		},
+		{
+			name:       "lower case header and scheme, handler called",
+			verifier:   verifier,
+			headers:    map[string]string{"authorization": "bearer valid"},
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+		},
+		{
+			name:       "no authorization header, unauthorized",
+			verifier:   verifier,
+			headers:    map[string]string{},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "basic authorization, unauthorized",
+			verifier:   verifier,
+			headers:    map[string]string{"Authorization": "Basic dXNlcjpwYXNz"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "empty bearer token, unauthorized",
+			verifier:   verifier,
+			headers:    map[string]string{"Authorization": "Bearer "},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid bearer token, unauthorized",
+			verifier:   verifier,
+			headers:    map[string]string{"Authorization": "Bearer expired"},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing scope, forbidden",
+			verifier:   verifier,
+			options:    []AuthOption{WithAuthScopes("orders:write")},
+			headers:    map[string]string{"Authorization": "Bearer valid"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "missing route scope, forbidden",
+			verifier:   verifier,
+			options:    []AuthOption{WithAuthScopes("orders:read"), WithAuthRouteScopes("GET /orders", "orders:list")},
+			headers:    map[string]string{"Authorization": "Bearer valid"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "scope of other route, handler called",
+			verifier:   verifier,
+			options:    []AuthOption{WithAuthRouteScopes("DELETE /orders", "orders:delete")},
+			headers:    map[string]string{"Authorization": "Bearer valid"},
+			wantStatus: http.StatusOK,
+			wantCalled: true,
+		},
+		{
+			name:       "jwks unavailable, internal error",
+			verifier:   fakeVerifier{err: fmt.Errorf("%w: connection refused", jwt.ErrJWKSUnavailable)},
+			headers:    map[string]string{"Authorization": "Bearer other"},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				called = true
+				claims, ok := jwt.FromContext(ctx)
+				assert.True(t, ok)
+				assert.Equal(t, "user-123", claims.Subject)
+				assert.Equal(t, &logctx.LogCtx{logctx.String("subject", "user-123")}, logctx.Get(ctx))
+				return response.New(http.StatusOK, "ok"), nil
+			}
+
+			m := NewAuthWithResponse[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](
+				slog.New(slog.DiscardHandler), tt.verifier, tt.options...)
+			got, err := m.Wrap(handler)(context.Background(), events.APIGatewayProxyRequest{
+				Resource:       "/orders",
+				Headers:        tt.headers,
+				RequestContext: events.APIGatewayProxyRequestContext{HTTPMethod: http.MethodGet},
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, got.StatusCode)
+			assert.Equal(t, tt.wantCalled, called)
+		})
+	}
+}
+
+func Test_authWithResponse_Wrap_v2(t *testing.T) {
+	m := NewAuthWithResponse[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse](
+		slog.New(slog.DiscardHandler), fakeVerifier{err: jwt.ErrInvalidSignature})
+	handler := func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusOK}, nil
+	}
+
+	got, err := m.Wrap(handler)(context.Background(), events.APIGatewayV2HTTPRequest{
+		Headers: map[string]string{"authorization": "Bearer forged"},
+	})
+
+	assert.NoError(t, err)
+	want := response.NewErrorCode(response.ErrorCodeUnauthorized)
+	assert.Equal(t, events.APIGatewayV2HTTPResponse{StatusCode: want.StatusCode, Headers: want.Headers, Body: want.Body}, got)
+
+	got, err = m.Wrap(handler)(context.Background(), events.APIGatewayV2HTTPRequest{
+		Headers: map[string]string{"authorization": "Bearer valid"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, got.StatusCode)
+}
+
+func Test_authWithResponse_Wrap_otherResponse(t *testing.T) {
+	errVerifier := errors.New("verifier error")
+	tests := []struct {
+		name     string
+		verifier TokenVerifier
+		headers  map[string]string
+		wantErr  error
+	}{
+		{
+			name:     "invalid bearer token, ErrUnauthorized",
+			verifier: fakeVerifier{err: jwt.ErrExpired},
+			headers:  map[string]string{"Authorization": "Bearer expired"},
+			wantErr:  ErrUnauthorized,
+		},
+		{
+			name:     "missing scope, ErrForbidden",
+			verifier: fakeVerifier{},
+			headers:  map[string]string{"Authorization": "Bearer valid"},
+			wantErr:  ErrForbidden,
+		},
+		{
+			name:     "jwks unavailable, verifier error",
+			verifier: fakeVerifier{err: fmt.Errorf("%w: %w", jwt.ErrJWKSUnavailable, errVerifier)},
+			headers:  map[string]string{"Authorization": "Bearer other"},
+			wantErr:  errVerifier,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewAuthWithResponse[events.APIGatewayProxyRequest, string](
+				slog.New(slog.DiscardHandler), tt.verifier, WithAuthScopes("orders:read"))
+			_, err := m.Wrap(func(context.Context, events.APIGatewayProxyRequest) (string, error) {
+				return "ok", nil
+			})(context.Background(), events.APIGatewayProxyRequest{Headers: tt.headers})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
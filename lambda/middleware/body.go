@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/response"
+)
+
+const (
+	defaultBodyRejectedMsg  = "Request body rejected"
+	defaultBodyMaxSize      = 1 << 20
+	defaultBodyMaxJSONDepth = 32
+	bodyField               = "body"
+)
+
+var (
+	// ErrBodyTooLarge is returned by the body middleware for a request with a body over the maximum size, for response
+	// types other than API Gateway v1 and v2.
+	ErrBodyTooLarge = errors.New("middleware: request body too large")
+	// ErrUnsupportedMediaType is returned by the body middleware for a request with a body of a Content-Type not
+	// allowed for its method, for response types other than API Gateway v1 and v2.
+	ErrUnsupportedMediaType = errors.New("middleware: unsupported media type")
+	// ErrInvalidBody is returned by the body middleware for a request with a body that is not valid base64, not
+	// well-formed JSON or nested too deep, for response types other than API Gateway v1 and v2.
+	ErrInvalidBody = errors.New("middleware: invalid request body")
+)
+
+type bodyOptions struct {
+	maxSize      int
+	routeMaxSize map[string]int
+	contentTypes map[string][]string
+	maxJSONDepth int
+}
+
+// BodyOption configures NewBodyWithResponse.
+type BodyOption func(*bodyOptions)
+
+// WithBodyMaxSize sets the maximum size of request bodies in bytes, after base64 decoding. Defaults to 1 MiB.
+func WithBodyMaxSize(size int) BodyOption {
+	return func(o *bodyOptions) {
+		o.maxSize = size
+	}
+}
+
+// WithBodyRouteMaxSize sets the maximum size of request bodies of a single API Gateway route, e.g. a route accepting
+// uploads. route is matched against the RouteKey of API Gateway v2 requests and "<HTTP method> <resource>" (e.g.
+// "POST /documents") of API Gateway v1 requests. Call it once per route.
+func WithBodyRouteMaxSize(route string, size int) BodyOption {
+	return func(o *bodyOptions) {
+		sizes := maps.Clone(o.routeMaxSize)
+		if sizes == nil {
+			sizes = map[string]int{}
+		}
+		sizes[route] = size
+		o.routeMaxSize = sizes
+	}
+}
+
+// WithBodyContentTypes sets the Content-Types allowed for request bodies of method, each either a media type
+// ("application/json") or a wildcard subtype ("image/*"). Parameters such as charset are ignored. Request bodies of
+// methods without Content-Types are not checked. Defaults to application/json for POST, PUT and PATCH. Call it once
+// per method.
+func WithBodyContentTypes(method string, contentTypes ...string) BodyOption {
+	return func(o *bodyOptions) {
+		types := maps.Clone(o.contentTypes)
+		if types == nil {
+			types = map[string][]string{}
+		}
+		types[strings.ToUpper(method)] = contentTypes
+		o.contentTypes = types
+	}
+}
+
+// WithBodyMaxJSONDepth sets the maximum nesting depth of JSON request bodies, or no maximum if depth is 0. Defaults
+// to 32.
+func WithBodyMaxJSONDepth(depth int) BodyOption {
+	return func(o *bodyOptions) {
+		o.maxJSONDepth = depth
+	}
+}
+
+type bodyWithResponse[E, R any] struct {
+	logger *slog.Logger
+	opts   bodyOptions
+}
+
+// NewBodyWithResponse returns an implementation of WithResponse for the body middleware.
+//
+// The body middleware checks the bodies of API Gateway v1 and v2, Lambda Function URL and ALB requests before the
+// handler is called. A request with a body over the maximum size (see WithBodyMaxSize and WithBodyRouteMaxSize) gets a
+// response.ErrorCodePayloadTooLarge (413) response, and one with a body of a Content-Type not allowed for its method
+// (see WithBodyContentTypes) a response.ErrorCodeUnsupportedMedia (415) response. A JSON body (application/json or
+// a +json media type) that is not well-formed or nested deeper than the maximum depth (see WithBodyMaxJSONDepth) gets
+// a response.ErrorCodeValidationFailed (400) response with an ErrorField for the body.
+//
+// For response types other than API Gateway v1 and v2, ErrBodyTooLarge, ErrUnsupportedMediaType or ErrInvalidBody is
+// returned instead.
+func NewBodyWithResponse[E, R any](logger *slog.Logger, options ...BodyOption) WithResponse[E, R] {
+	opts := bodyOptions{
+		maxSize: defaultBodyMaxSize,
+		contentTypes: map[string][]string{
+			http.MethodPost:  {"application/json"},
+			http.MethodPut:   {"application/json"},
+			http.MethodPatch: {"application/json"},
+		},
+		maxJSONDepth: defaultBodyMaxJSONDepth,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+	return &bodyWithResponse[E, R]{
+		logger: logger,
+		opts:   opts,
+	}
+}
+
+func (b bodyWithResponse[E, R]) Wrap(next func(context.Context, E) (R, error)) func(context.Context, E) (R, error) {
+	return func(ctx context.Context, event E) (R, error) {
+		body, isBase64 := requestBody(event)
+		if body == "" {
+			return next(ctx, event)
+		}
+
+		if isBase64 {
+			decoded, err := base64.StdEncoding.DecodeString(body)
+			if err != nil {
+				return b.reject(ctx, ErrInvalidBody, response.ErrorCodeValidationFailed,
+					response.WithErrorFields(response.NewErrorField(bodyField, response.FieldErrorCodeInvalidFormat, "must be valid base64")))
+			}
+			body = string(decoded)
+		}
+
+		maxSize := b.opts.maxSize
+		if size, ok := b.opts.routeMaxSize[routeKey(event)]; ok {
+			maxSize = size
+		}
+		if len(body) > maxSize {
+			return b.reject(ctx, ErrBodyTooLarge, response.ErrorCodePayloadTooLarge)
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(requestHeader(event, "Content-Type"))
+		if allowed, ok := b.opts.contentTypes[strings.ToUpper(requestMethod(event))]; ok && !matchMediaType(allowed, mediaType) {
+			return b.reject(ctx, ErrUnsupportedMediaType, response.ErrorCodeUnsupportedMedia)
+		}
+
+		if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+			if !json.Valid([]byte(body)) {
+				return b.reject(ctx, ErrInvalidBody, response.ErrorCodeValidationFailed,
+					response.WithErrorFields(response.NewErrorField(bodyField, response.FieldErrorCodeInvalidFormat, "must be well-formed JSON")))
+			}
+			if b.opts.maxJSONDepth > 0 && jsonDepth(body) > b.opts.maxJSONDepth {
+				return b.reject(ctx, ErrInvalidBody, response.ErrorCodeValidationFailed,
+					response.WithErrorFields(response.NewErrorField(bodyField, response.FieldErrorCodeTooDeep,
+						fmt.Sprintf("must not be nested more than %d levels deep", b.opts.maxJSONDepth))))
+			}
+		}
+
+		return next(ctx, event)
+	}
+}
+
+func (b bodyWithResponse[E, R]) reject(ctx context.Context, err error, code response.ErrorCode, opts ...response.ErrorCodeOption) (R, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, defaultBodyRejectedMsg, slog.String("code", code.String()))
+	res, ok := errorCodeResponse[R](code, opts...)
+	if !ok {
+		return res, err
+	}
+	return res, nil
+}
+
+// matchMediaType returns whether mediaType matches any of allowed, either exactly or by a wildcard subtype.
+func matchMediaType(allowed []string, mediaType string) bool {
+	if mediaType == "" {
+		return false
+	}
+	for _, a := range allowed {
+		a = strings.ToLower(a)
+		if a == mediaType || a == "*/*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonDepth returns the maximum nesting depth of objects and arrays of well-formed JSON.
+func jsonDepth(s string) int {
+	depth, maxDepth := 0, 0
+	inString, escaped := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			switch c {
+			case '\\':
+				escaped = true
+			case '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+			maxDepth = max(maxDepth, depth)
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	return maxDepth
+}
+
+// requestBody returns the body of API Gateway v1 and v2, Lambda Function URL and ALB requests, and whether it is
+// base64 encoded.
+func requestBody(event any) (string, bool) {
+	switch e := event.(type) {
+	case events.APIGatewayProxyRequest:
+		return e.Body, e.IsBase64Encoded
+	case events.APIGatewayV2HTTPRequest:
+		return e.Body, e.IsBase64Encoded
+	case events.LambdaFunctionURLRequest:
+		return e.Body, e.IsBase64Encoded
+	case events.ALBTargetGroupRequest:
+		return e.Body, e.IsBase64Encoded
+	}
+	return "", false
+}
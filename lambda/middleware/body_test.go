@@ -0,0 +1,199 @@
+package middleware
+
+import (
+	"context"
+	"encoding/base64"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/response"
+)
+
+func Test_bodyWithResponse_Wrap(t *testing.T) {
+	jsonHeaders := map[string]string{"content-type": "application/json; charset=utf-8"}
+	tooDeep := strings.Repeat("[", 4) + strings.Repeat("]", 4)
+
+	tests := []struct {
+		name       string
+		options    []BodyOption
+		event      events.APIGatewayProxyRequest
+		wantCalled bool
+		want       events.APIGatewayProxyResponse
+	}{
+		{
+			name:       "no body, handler called",
+			event:      events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost},
+			wantCalled: true,
+			want:       events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		{
+			name:       "json body, handler called",
+			event:      events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: jsonHeaders, Body: `{"name":"[{"}`},
+			wantCalled: true,
+			want:       events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		{
+			name:    "body over max size, payload too large",
+			options: []BodyOption{WithBodyMaxSize(4)},
+			event:   events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: jsonHeaders, Body: `{"a":1}`},
+			want:    response.NewErrorCode(response.ErrorCodePayloadTooLarge),
+		},
+		{
+			name:    "base64 body, decoded size checked",
+			options: []BodyOption{WithBodyMaxSize(7)},
+			event: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPost, Headers: jsonHeaders,
+				Body: base64.StdEncoding.EncodeToString([]byte(`{"a":1}`)), IsBase64Encoded: true,
+			},
+			wantCalled: true,
+			want:       events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		{
+			name:    "body over route max size, payload too large",
+			options: []BodyOption{WithBodyRouteMaxSize("POST /orders", 4)},
+			event: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPost, Resource: "/orders", Headers: jsonHeaders, Body: `{"a":1}`,
+				RequestContext: events.APIGatewayProxyRequestContext{HTTPMethod: http.MethodPost},
+			},
+			want: response.NewErrorCode(response.ErrorCodePayloadTooLarge),
+		},
+		{
+			name:    "body under route max size, handler called",
+			options: []BodyOption{WithBodyMaxSize(4), WithBodyRouteMaxSize("POST /documents", 1024)},
+			event: events.APIGatewayProxyRequest{
+				HTTPMethod: http.MethodPost, Resource: "/documents", Headers: jsonHeaders, Body: `{"a":1}`,
+				RequestContext: events.APIGatewayProxyRequestContext{HTTPMethod: http.MethodPost},
+			},
+			wantCalled: true,
+			want:       events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		{
+			name:  "content type not allowed, unsupported media type",
+			event: events.APIGatewayProxyRequest{HTTPMethod: http.MethodPut, Headers: map[string]string{"Content-Type": "text/plain"}, Body: "hello"},
+			want:  response.NewErrorCode(response.ErrorCodeUnsupportedMedia),
+		},
+		{
+			name:  "no content type, unsupported media type",
+			event: events.APIGatewayProxyRequest{HTTPMethod: http.MethodPatch, Body: `{"a":1}`},
+			want:  response.NewErrorCode(response.ErrorCodeUnsupportedMedia),
+		},
+		{
+			name:       "method without content types, not checked",
+			event:      events.APIGatewayProxyRequest{HTTPMethod: http.MethodDelete, Headers: map[string]string{"Content-Type": "text/plain"}, Body: "hello"},
+			wantCalled: true,
+			want:       events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		{
+			name:       "wildcard content type, handler called",
+			options:    []BodyOption{WithBodyContentTypes(http.MethodPost, "application/json", "image/*")},
+			event:      events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: map[string]string{"Content-Type": "image/png"}, Body: "png"},
+			wantCalled: true,
+			want:       events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		{
+			name:  "malformed json, validation failed",
+			event: events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: jsonHeaders, Body: `{"a":`},
+			want: response.NewErrorCode(response.ErrorCodeValidationFailed, response.WithErrorFields(
+				response.NewErrorField("body", response.FieldErrorCodeInvalidFormat, "must be well-formed JSON"))),
+		},
+		{
+			name:    "malformed +json, validation failed",
+			options: []BodyOption{WithBodyContentTypes(http.MethodPost, "application/*")},
+			event:   events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: map[string]string{"Content-Type": "application/problem+json"}, Body: `{`},
+			want: response.NewErrorCode(response.ErrorCodeValidationFailed, response.WithErrorFields(
+				response.NewErrorField("body", response.FieldErrorCodeInvalidFormat, "must be well-formed JSON"))),
+		},
+		{
+			name:    "json nested too deep, validation failed",
+			options: []BodyOption{WithBodyMaxJSONDepth(3)},
+			event:   events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: jsonHeaders, Body: tooDeep},
+			want: response.NewErrorCode(response.ErrorCodeValidationFailed, response.WithErrorFields(
+				response.NewErrorField("body", response.FieldErrorCodeTooDeep, "must not be nested more than 3 levels deep"))),
+		},
+		{
+			name:       "json nested without max depth, handler called",
+			options:    []BodyOption{WithBodyMaxJSONDepth(0)},
+			event:      events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: jsonHeaders, Body: strings.Repeat("[", 100) + strings.Repeat("]", 100)},
+			wantCalled: true,
+			want:       events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+		},
+		{
+			name:  "invalid base64, validation failed",
+			event: events.APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Headers: jsonHeaders, Body: "not base64!", IsBase64Encoded: true},
+			want: response.NewErrorCode(response.ErrorCodeValidationFailed, response.WithErrorFields(
+				response.NewErrorField("body", response.FieldErrorCodeInvalidFormat, "must be valid base64"))),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				called = true
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+			}
+
+			m := NewBodyWithResponse[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](slog.New(slog.DiscardHandler), tt.options...)
+			got, err := m.Wrap(handler)(context.Background(), tt.event)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCalled, called)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_bodyWithResponse_Wrap_v2(t *testing.T) {
+	m := NewBodyWithResponse[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse](slog.New(slog.DiscardHandler))
+
+	got, err := m.Wrap(func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusOK}, nil
+	})(context.Background(), events.APIGatewayV2HTTPRequest{
+		Headers:        map[string]string{"content-type": "text/xml"},
+		Body:           "<order/>",
+		RequestContext: events.APIGatewayV2HTTPRequestContext{HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: http.MethodPost}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnsupportedMediaType, got.StatusCode)
+}
+
+func Test_bodyWithResponse_Wrap_otherResponse(t *testing.T) {
+	m := NewBodyWithResponse[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](slog.New(slog.DiscardHandler),
+		WithBodyMaxSize(4))
+	handler := func(context.Context, events.ALBTargetGroupRequest) (events.ALBTargetGroupResponse, error) {
+		return events.ALBTargetGroupResponse{StatusCode: http.StatusOK}, nil
+	}
+	headers := map[string]string{"content-type": "application/json"}
+
+	_, err := m.Wrap(handler)(context.Background(), events.ALBTargetGroupRequest{HTTPMethod: http.MethodPost, Headers: headers, Body: `{"a":1}`})
+	assert.ErrorIs(t, err, ErrBodyTooLarge)
+
+	_, err = m.Wrap(handler)(context.Background(), events.ALBTargetGroupRequest{HTTPMethod: http.MethodPost, Body: "a"})
+	assert.ErrorIs(t, err, ErrUnsupportedMediaType)
+
+	_, err = m.Wrap(handler)(context.Background(), events.ALBTargetGroupRequest{HTTPMethod: http.MethodPost, Headers: headers, Body: "{"})
+	assert.ErrorIs(t, err, ErrInvalidBody)
+}
+
+func Test_jsonDepth(t *testing.T) {
+	tests := []struct {
+		json string
+		want int
+	}{
+		{json: `1`, want: 0},
+		{json: `{}`, want: 1},
+		{json: `{"a":[1,{"b":[]}]}`, want: 4},
+		{json: `{"a":"[[[{{{\"]]]"}`, want: 1},
+		{json: `[[],[[]]]`, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			assert.Equal(t, tt.want, jsonDepth(tt.json))
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package middleware
+
+import (
+	"context"
+)
+
+// Predicate [E any] reports whether a conditional middleware in a Chain/ChainWithResponse applies to an event.
+type Predicate[E any] func(ctx context.Context, event E) bool
+
+type chainEntry[M any, E any] struct {
+	name       string
+	middleware M
+	predicate  Predicate[E]
+	skip       bool
+}
+
+// applies reports whether the entry's middleware applies to an event.
+func (c chainEntry[M, E]) applies(ctx context.Context, event E) bool {
+	if c.predicate == nil {
+		return true
+	}
+	return c.predicate(ctx, event) != c.skip
+}
+
+// Chain [E any] builds a chain of NoResponse middleware for handlers of event type E. Middleware are applied in the
+// order they are added, so the first one added is the outermost. A Chain is itself NoResponse middleware, and Then
+// wraps a handler func with it.
+//
+// A Chain is not safe for concurrent modification - build it once at startup, before request traffic begins.
+type Chain[E any] struct {
+	entries []chainEntry[NoResponse[E], E]
+}
+
+// NewChain returns a Chain of middlewares.
+func NewChain[E any](middlewares ...NoResponse[E]) *Chain[E] {
+	return (&Chain[E]{}).Use(middlewares...)
+}
+
+// Use adds middlewares to the end of the chain.
+func (c *Chain[E]) Use(middlewares ...NoResponse[E]) *Chain[E] {
+	for _, m := range middlewares {
+		c.entries = append(c.entries, chainEntry[NoResponse[E], E]{middleware: m})
+	}
+	return c
+}
+
+// UseNamed adds a middleware to the end of the chain under name, so it can later be replaced with Replace or removed
+// with Remove.
+func (c *Chain[E]) UseNamed(name string, m NoResponse[E]) *Chain[E] {
+	c.entries = append(c.entries, chainEntry[NoResponse[E], E]{name: name, middleware: m})
+	return c
+}
+
+// UseIf adds middlewares to the end of the chain that are only applied to events for which predicate returns true.
+func (c *Chain[E]) UseIf(predicate Predicate[E], middlewares ...NoResponse[E]) *Chain[E] {
+	for _, m := range middlewares {
+		c.entries = append(c.entries, chainEntry[NoResponse[E], E]{middleware: m, predicate: predicate})
+	}
+	return c
+}
+
+// Skip adds middlewares to the end of the chain that are applied to every event except those for which predicate
+// returns true (e.g. skipping authentication for a health check).
+func (c *Chain[E]) Skip(predicate Predicate[E], middlewares ...NoResponse[E]) *Chain[E] {
+	for _, m := range middlewares {
+		c.entries = append(c.entries, chainEntry[NoResponse[E], E]{middleware: m, predicate: predicate, skip: true})
+	}
+	return c
+}
+
+// Replace replaces the middleware added under name with m, keeping its position in the chain. It does nothing if no
+// middleware was added under name.
+func (c *Chain[E]) Replace(name string, m NoResponse[E]) *Chain[E] {
+	for i := range c.entries {
+		if c.entries[i].name == name {
+			c.entries[i].middleware = m
+		}
+	}
+	return c
+}
+
+// Remove removes the middleware added under name. It does nothing if no middleware was added under name.
+func (c *Chain[E]) Remove(name string) *Chain[E] {
+	entries := c.entries[:0]
+	for _, e := range c.entries {
+		if e.name != name {
+			entries = append(entries, e)
+		}
+	}
+	c.entries = entries
+	return c
+}
+
+// Middlewares returns the chain as a slice of middleware, for lambda.Start.
+func (c *Chain[E]) Middlewares() []NoResponse[E] {
+	return []NoResponse[E]{c}
+}
+
+// Wrap implements NoResponse, wrapping next in every middleware of the chain.
+func (c *Chain[E]) Wrap(next func(context.Context, E) error) func(context.Context, E) error {
+	handlerFn := next
+	for i := len(c.entries) - 1; i >= 0; i-- {
+		entry := c.entries[i]
+		wrapped, unwrapped := entry.middleware.Wrap(handlerFn), handlerFn
+		if entry.predicate == nil {
+			handlerFn = wrapped
+			continue
+		}
+		handlerFn = func(ctx context.Context, event E) error {
+			if entry.applies(ctx, event) {
+				return wrapped(ctx, event)
+			}
+			return unwrapped(ctx, event)
+		}
+	}
+	return handlerFn
+}
+
+// Then returns handler wrapped in every middleware of the chain.
+func (c *Chain[E]) Then(handler func(context.Context, E) error) func(context.Context, E) error {
+	return c.Wrap(handler)
+}
+
+// ChainWithResponse [E, R any] builds a chain of WithResponse middleware for handlers of event type E that return a
+// response type R. Middleware are applied in the order they are added, so the first one added is the outermost. A
+// ChainWithResponse is itself WithResponse middleware, and Then wraps a handler func with it. NoResponse middleware
+// can be added with LiftNoResponse.
+//
+// A ChainWithResponse is not safe for concurrent modification - build it once at startup, before request traffic
+// begins.
+type ChainWithResponse[E, R any] struct {
+	entries []chainEntry[WithResponse[E, R], E]
+}
+
+// NewChainWithResponse returns a ChainWithResponse of middlewares.
+func NewChainWithResponse[E, R any](middlewares ...WithResponse[E, R]) *ChainWithResponse[E, R] {
+	return (&ChainWithResponse[E, R]{}).Use(middlewares...)
+}
+
+// Use adds middlewares to the end of the chain.
+func (c *ChainWithResponse[E, R]) Use(middlewares ...WithResponse[E, R]) *ChainWithResponse[E, R] {
+	for _, m := range middlewares {
+		c.entries = append(c.entries, chainEntry[WithResponse[E, R], E]{middleware: m})
+	}
+	return c
+}
+
+// UseNamed adds a middleware to the end of the chain under name, so it can later be replaced with Replace or removed
+// with Remove.
+func (c *ChainWithResponse[E, R]) UseNamed(name string, m WithResponse[E, R]) *ChainWithResponse[E, R] {
+	c.entries = append(c.entries, chainEntry[WithResponse[E, R], E]{name: name, middleware: m})
+	return c
+}
+
+// UseIf adds middlewares to the end of the chain that are only applied to events for which predicate returns true.
+func (c *ChainWithResponse[E, R]) UseIf(predicate Predicate[E], middlewares ...WithResponse[E, R]) *ChainWithResponse[E, R] {
+	for _, m := range middlewares {
+		c.entries = append(c.entries, chainEntry[WithResponse[E, R], E]{middleware: m, predicate: predicate})
+	}
+	return c
+}
+
+// Skip adds middlewares to the end of the chain that are applied to every event except those for which predicate
+// returns true (e.g. skipping authentication for a health check).
+func (c *ChainWithResponse[E, R]) Skip(predicate Predicate[E], middlewares ...WithResponse[E, R]) *ChainWithResponse[E, R] {
+	for _, m := range middlewares {
+		c.entries = append(c.entries, chainEntry[WithResponse[E, R], E]{middleware: m, predicate: predicate, skip: true})
+	}
+	return c
+}
+
+// Replace replaces the middleware added under name with m, keeping its position in the chain. It does nothing if no
+// middleware was added under name.
+func (c *ChainWithResponse[E, R]) Replace(name string, m WithResponse[E, R]) *ChainWithResponse[E, R] {
+	for i := range c.entries {
+		if c.entries[i].name == name {
+			c.entries[i].middleware = m
+		}
+	}
+	return c
+}
+
+// Remove removes the middleware added under name. It does nothing if no middleware was added under name.
+func (c *ChainWithResponse[E, R]) Remove(name string) *ChainWithResponse[E, R] {
+	entries := c.entries[:0]
+	for _, e := range c.entries {
+		if e.name != name {
+			entries = append(entries, e)
+		}
+	}
+	c.entries = entries
+	return c
+}
+
+// Middlewares returns the chain as a slice of middleware, for lambda.StartWithResponse.
+func (c *ChainWithResponse[E, R]) Middlewares() []WithResponse[E, R] {
+	return []WithResponse[E, R]{c}
+}
+
+// Wrap implements WithResponse, wrapping next in every middleware of the chain.
+func (c *ChainWithResponse[E, R]) Wrap(next func(context.Context, E) (R, error)) func(context.Context, E) (R, error) {
+	handlerFn := next
+	for i := len(c.entries) - 1; i >= 0; i-- {
+		entry := c.entries[i]
+		wrapped, unwrapped := entry.middleware.Wrap(handlerFn), handlerFn
+		if entry.predicate == nil {
+			handlerFn = wrapped
+			continue
+		}
+		handlerFn = func(ctx context.Context, event E) (R, error) {
+			if entry.applies(ctx, event) {
+				return wrapped(ctx, event)
+			}
+			return unwrapped(ctx, event)
+		}
+	}
+	return handlerFn
+}
+
+// Then returns handler wrapped in every middleware of the chain.
+func (c *ChainWithResponse[E, R]) Then(handler func(context.Context, E) (R, error)) func(context.Context, E) (R, error) {
+	return c.Wrap(handler)
+}
+
+type liftedNoResponse[E, R any] struct {
+	middleware NoResponse[E]
+}
+
+// LiftNoResponse adapts NoResponse middleware into WithResponse middleware, so the same middleware can be used in
+// both types of chain. The response of the next function is passed through unchanged.
+func LiftNoResponse[E, R any](m NoResponse[E]) WithResponse[E, R] {
+	return &liftedNoResponse[E, R]{middleware: m}
+}
+
+// LiftNoResponses adapts a slice of NoResponse middleware with LiftNoResponse.
+func LiftNoResponses[E, R any](middlewares []NoResponse[E]) []WithResponse[E, R] {
+	lifted := make([]WithResponse[E, R], 0, len(middlewares))
+	for _, m := range middlewares {
+		lifted = append(lifted, LiftNoResponse[E, R](m))
+	}
+	return lifted
+}
+
+func (l liftedNoResponse[E, R]) Wrap(next func(context.Context, E) (R, error)) func(context.Context, E) (R, error) {
+	return func(ctx context.Context, event E) (R, error) {
+		var response R
+		err := l.middleware.Wrap(func(ctx context.Context, event E) error {
+			var err error
+			response, err = next(ctx, event)
+			return err
+		})(ctx, event)
+		return response, err
+	}
+}
@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type appendNoResponse struct {
+	id string
+}
+
+func (a appendNoResponse) Wrap(next func(context.Context, []string) error) func(context.Context, []string) error {
+	return func(ctx context.Context, event []string) error {
+		return next(ctx, append(slices.Clone(event), a.id))
+	}
+}
+
+type appendWithResponse struct {
+	id string
+}
+
+func (a appendWithResponse) Wrap(next func(context.Context, []string) ([]string, error)) func(context.Context, []string) ([]string, error) {
+	return func(ctx context.Context, event []string) ([]string, error) {
+		resp, err := next(ctx, append(slices.Clone(event), a.id))
+		return append(resp, a.id), err
+	}
+}
+
+func containsEvent(id string) Predicate[[]string] {
+	return func(_ context.Context, event []string) bool {
+		return slices.Contains(event, id)
+	}
+}
+
+func TestChain_Then(t *testing.T) {
+	type testCase struct {
+		name      string
+		chain     func() *Chain[[]string]
+		event     []string
+		wantEvent []string
+	}
+	tests := []testCase{
+		{
+			name:      "empty chain, event passed to handler unchanged",
+			chain:     func() *Chain[[]string] { return NewChain[[]string]() },
+			event:     []string{"event"},
+			wantEvent: []string{"event"},
+		},
+		{
+			name: "Use, middleware applied in order added",
+			chain: func() *Chain[[]string] {
+				return NewChain[[]string](appendNoResponse{"m1"}).Use(appendNoResponse{"m2"}, appendNoResponse{"m3"})
+			},
+			event:     []string{"event"},
+			wantEvent: []string{"event", "m1", "m2", "m3"},
+		},
+		{
+			name: "UseIf, predicate true, middleware applied",
+			chain: func() *Chain[[]string] {
+				return NewChain[[]string]().UseIf(containsEvent("match"), appendNoResponse{"m1"})
+			},
+			event:     []string{"match"},
+			wantEvent: []string{"match", "m1"},
+		},
+		{
+			name: "UseIf, predicate false, middleware not applied",
+			chain: func() *Chain[[]string] {
+				return NewChain[[]string]().UseIf(containsEvent("match"), appendNoResponse{"m1"})
+			},
+			event:     []string{"other"},
+			wantEvent: []string{"other"},
+		},
+		{
+			name: "Skip, predicate true, middleware not applied",
+			chain: func() *Chain[[]string] {
+				return NewChain[[]string]().Skip(containsEvent("health"), appendNoResponse{"auth"})
+			},
+			event:     []string{"health"},
+			wantEvent: []string{"health"},
+		},
+		{
+			name: "Skip, predicate false, middleware applied",
+			chain: func() *Chain[[]string] {
+				return NewChain[[]string]().Skip(containsEvent("health"), appendNoResponse{"auth"})
+			},
+			event:     []string{"orders"},
+			wantEvent: []string{"orders", "auth"},
+		},
+		{
+			name: "conditional middleware sees event from previous middleware",
+			chain: func() *Chain[[]string] {
+				return NewChain[[]string](appendNoResponse{"m1"}).UseIf(containsEvent("m1"), appendNoResponse{"m2"})
+			},
+			event:     []string{"event"},
+			wantEvent: []string{"event", "m1", "m2"},
+		},
+		{
+			name: "Replace, named middleware replaced in place",
+			chain: func() *Chain[[]string] {
+				return NewChain[[]string](appendNoResponse{"m1"}).
+					UseNamed("auth", appendNoResponse{"auth"}).
+					Use(appendNoResponse{"m3"}).
+					Replace("auth", appendNoResponse{"fake-auth"})
+			},
+			event:     []string{"event"},
+			wantEvent: []string{"event", "m1", "fake-auth", "m3"},
+		},
+		{
+			name: "Replace, unknown name, chain unchanged",
+			chain: func() *Chain[[]string] {
+				return NewChain[[]string](appendNoResponse{"m1"}).Replace("auth", appendNoResponse{"fake-auth"})
+			},
+			event:     []string{"event"},
+			wantEvent: []string{"event", "m1"},
+		},
+		{
+			name: "Remove, named middleware removed",
+			chain: func() *Chain[[]string] {
+				return NewChain[[]string](appendNoResponse{"m1"}).
+					UseNamed("auth", appendNoResponse{"auth"}).
+					Use(appendNoResponse{"m3"}).
+					Remove("auth")
+			},
+			event:     []string{"event"},
+			wantEvent: []string{"event", "m1", "m3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotEvent []string
+			fn := tt.chain().Then(func(_ context.Context, event []string) error {
+				gotEvent = event
+				return nil
+			})
+			assert.NoError(t, fn(context.Background(), tt.event))
+			assert.Equal(t, tt.wantEvent, gotEvent)
+		})
+	}
+}
+
+func TestChain_Middlewares(t *testing.T) {
+	chain := NewChain[[]string](appendNoResponse{"m1"}, appendNoResponse{"m2"})
+
+	var gotEvent []string
+	handlerFn := func(_ context.Context, event []string) error {
+		gotEvent = event
+		return nil
+	}
+	for _, m := range slices.Backward(chain.Middlewares()) {
+		handlerFn = m.Wrap(handlerFn)
+	}
+	_ = handlerFn(context.Background(), []string{"event"})
+
+	assert.Equal(t, []string{"event", "m1", "m2"}, gotEvent)
+}
+
+func TestChainWithResponse_Then(t *testing.T) {
+	type testCase struct {
+		name         string
+		chain        func() *ChainWithResponse[[]string, []string]
+		event        []string
+		wantEvent    []string
+		wantResponse []string
+	}
+	tests := []testCase{
+		{
+			name: "Use, middleware applied in order added",
+			chain: func() *ChainWithResponse[[]string, []string] {
+				return NewChainWithResponse[[]string, []string](appendWithResponse{"m1"}, appendWithResponse{"m2"})
+			},
+			event:        []string{"event"},
+			wantEvent:    []string{"event", "m1", "m2"},
+			wantResponse: []string{"response", "m2", "m1"},
+		},
+		{
+			name: "UseIf and Skip, only matching middleware applied",
+			chain: func() *ChainWithResponse[[]string, []string] {
+				return NewChainWithResponse[[]string, []string]().
+					UseIf(containsEvent("event"), appendWithResponse{"if"}).
+					Skip(containsEvent("event"), appendWithResponse{"skip"})
+			},
+			event:        []string{"event"},
+			wantEvent:    []string{"event", "if"},
+			wantResponse: []string{"response", "if"},
+		},
+		{
+			name: "Replace and Remove named middleware",
+			chain: func() *ChainWithResponse[[]string, []string] {
+				return NewChainWithResponse[[]string, []string]().
+					UseNamed("a", appendWithResponse{"a"}).
+					UseNamed("b", appendWithResponse{"b"}).
+					Replace("a", appendWithResponse{"fake-a"}).
+					Remove("b")
+			},
+			event:        []string{"event"},
+			wantEvent:    []string{"event", "fake-a"},
+			wantResponse: []string{"response", "fake-a"},
+		},
+		{
+			name: "lifted NoResponse middleware, event changed and response passed through",
+			chain: func() *ChainWithResponse[[]string, []string] {
+				return NewChainWithResponse(LiftNoResponses[[]string, []string]([]NoResponse[[]string]{appendNoResponse{"no-response"}})...).
+					Use(appendWithResponse{"with-response"})
+			},
+			event:        []string{"event"},
+			wantEvent:    []string{"event", "no-response", "with-response"},
+			wantResponse: []string{"response", "with-response"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotEvent []string
+			fn := tt.chain().Then(func(_ context.Context, event []string) ([]string, error) {
+				gotEvent = event
+				return []string{"response"}, nil
+			})
+			gotResponse, err := fn(context.Background(), tt.event)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantEvent, gotEvent)
+			assert.Equal(t, tt.wantResponse, gotResponse)
+		})
+	}
+}
+
+func TestLiftNoResponse(t *testing.T) {
+	errHandler := errors.New("error")
+
+	m := LiftNoResponse[[]string, string](appendNoResponse{"m1"})
+	fn := m.Wrap(func(_ context.Context, event []string) (string, error) {
+		assert.Equal(t, []string{"event", "m1"}, event)
+		return "response", errHandler
+	})
+	gotResponse, gotErr := fn(context.Background(), []string{"event"})
+
+	assert.Equal(t, "response", gotResponse)
+	assert.ErrorIs(t, gotErr, errHandler)
+}
@@ -2,50 +2,82 @@ package middleware
 
 import (
 	"context"
+	"log/slog"
+	"strconv"
+	"strings"
 
+	"github.com/aws/aws-lambda-go/cfn"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambdacontext"
 
+	"github.com/ellogroup/ello-golang-clock/clock"
 	"github.com/ellogroup/ello-golang-ctx/v2/logctx"
+
+	"github.com/ellogroup/ello-golang-aws/v2/internal/cognitoredact"
+	"github.com/ellogroup/ello-golang-aws/v2/internal/invocation"
 )
 
-type contextNoResponse[E any] struct{}
+// Loggable interface can be implemented by event types to add fields of their own to the context of each request, e.g.
+// cognito.Event adds the user pool id, trigger source and a hash of the username.
+type Loggable interface {
+	// LogFields returns the fields to add to the context. Values are added as strings.
+	LogFields() []slog.Attr
+}
+
+type contextNoResponse[E any] struct {
+	clock clock.Clock
+}
 
 // NewContext returns an implementation of NoResponse for the context middleware.
 //
 // The context middleware adds additional information to the context of each request using the
 // github.com/ellogroup/ello-golang-ctx/logctx package. This includes at the very least a request id.
+//
+// For handlers started with lambda.Start/lambda.StartWithResponse the context also includes whether the invocation was
+// a cold start, the function version, the remaining time in milliseconds and the invocation sequence number.
+//
+// For EventBridge events the context also includes the id, source and detail-type of the event, for Kinesis events
+// the shard id and the first and last sequence numbers of the batch, for CloudFormation custom resource events the
+// stack id, logical resource id and request type, and for Cognito user pool trigger events the user pool id, trigger
+// source and a hash of the username. Events implementing Loggable, such as cognito.Event, add their own log fields.
 func NewContext[E any]() NoResponse[E] {
-	return &contextNoResponse[E]{}
+	return &contextNoResponse[E]{clock: clock.NewSystem()}
 }
 
-func (contextNoResponse[E]) Wrap(next func(context.Context, E) error) func(context.Context, E) error {
+func (c contextNoResponse[E]) Wrap(next func(context.Context, E) error) func(context.Context, E) error {
 	return func(ctx context.Context, event E) error {
 		// Get context from event
-		_, ctx = contextFromEvent(ctx, event)
+		_, ctx = contextFromEvent(ctx, c.clock, event)
 
 		// return response
 		return next(ctx, event)
 	}
 }
 
-type contextWithResponse[E, R any] struct{}
+type contextWithResponse[E, R any] struct {
+	clock clock.Clock
+}
 
 // NewContextWithResponse returns an implementation of WithResponse for the context middleware.
 //
 // The context middleware adds additional information to the context of each request using the
 // github.com/ellogroup/ello-golang-ctx/logctx package. This includes at the very least a request id.
 //
+// For handlers started with lambda.Start/lambda.StartWithResponse the context also includes whether the invocation was
+// a cold start, the function version, the remaining time in milliseconds and the invocation sequence number.
+//
 // For API Gateway v1 requests the context also includes the method, domain and path of the request. The response is also
-// updated to include the request id within the header `x-request-id`.
+// updated to include the request id within the header `x-request-id`. For API Gateway WebSocket events the context
+// also includes the connection id and route key. For Cognito user pool trigger events the context also includes the
+// user pool id, trigger source and a hash of the username.
 func NewContextWithResponse[E, R any]() WithResponse[E, R] {
-	return &contextWithResponse[E, R]{}
+	return &contextWithResponse[E, R]{clock: clock.NewSystem()}
 }
 
-func (contextWithResponse[E, R]) Wrap(next func(context.Context, E) (R, error)) func(context.Context, E) (R, error) {
+func (c contextWithResponse[E, R]) Wrap(next func(context.Context, E) (R, error)) func(context.Context, E) (R, error) {
 	return func(ctx context.Context, event E) (R, error) {
 		// Get context from event
-		requestID, ctx := contextFromEvent(ctx, event)
+		requestID, ctx := contextFromEvent(ctx, c.clock, event)
 
 		// Get response
 		response, err := next(ctx, event)
@@ -58,7 +90,7 @@ func (contextWithResponse[E, R]) Wrap(next func(context.Context, E) (R, error))
 	}
 }
 
-func contextFromEvent[E any](ctx context.Context, event E) (string, context.Context) {
+func contextFromEvent[E any](ctx context.Context, clk clock.Clock, event E) (string, context.Context) {
 	// Extract request ids
 	requestID, lambdaRequestID := "", ""
 	if lambdaCtx, ok := lambdacontext.FromContext(ctx); ok {
@@ -82,6 +114,71 @@ func contextFromEvent[E any](ctx context.Context, event E) (string, context.Cont
 		)
 	}
 
+	if wsEvent, ok := any(event).(events.APIGatewayWebsocketProxyRequest); ok {
+		// APIGatewayWebsocketProxyRequest (API Gateway WebSocket)
+		additionalCtx = append(additionalCtx,
+			logctx.String("connection_id", wsEvent.RequestContext.ConnectionID),
+			logctx.String("route_key", wsEvent.RequestContext.RouteKey),
+		)
+	}
+
+	if ebEvent, ok := any(event).(events.EventBridgeEvent); ok {
+		// EventBridgeEvent
+		additionalCtx = append(additionalCtx,
+			logctx.String("event_id", ebEvent.ID),
+			logctx.String("event_source", ebEvent.Source),
+			logctx.String("event_detail_type", ebEvent.DetailType),
+		)
+	}
+
+	if kinesisEvent, ok := any(event).(events.KinesisEvent); ok && len(kinesisEvent.Records) > 0 {
+		// KinesisEvent
+		first, last := kinesisEvent.Records[0], kinesisEvent.Records[len(kinesisEvent.Records)-1]
+		shardID, _, _ := strings.Cut(first.EventID, ":")
+		additionalCtx = append(additionalCtx,
+			logctx.String("shard_id", shardID),
+			logctx.String("first_sequence_number", first.Kinesis.SequenceNumber),
+			logctx.String("last_sequence_number", last.Kinesis.SequenceNumber),
+		)
+	}
+
+	if cfnEvent, ok := any(event).(cfn.Event); ok {
+		// CloudFormation custom resource event
+		additionalCtx = append(additionalCtx,
+			logctx.String("stack_id", cfnEvent.StackID),
+			logctx.String("logical_resource_id", cfnEvent.LogicalResourceID),
+			logctx.String("cfn_request_type", string(cfnEvent.RequestType)),
+		)
+	}
+
+	if header, ok := cognitoredact.Header(any(event)); ok {
+		// Cognito user pool trigger event
+		for _, attr := range cognitoredact.LogFields(header) {
+			additionalCtx = append(additionalCtx, logctx.String(attr.Key, attr.Value.String()))
+		}
+	}
+
+	if loggable, ok := any(event).(Loggable); ok {
+		// Event with its own log fields, e.g. cognito.Event
+		for _, attr := range loggable.LogFields() {
+			additionalCtx = append(additionalCtx, logctx.String(attr.Key, attr.Value.String()))
+		}
+	}
+
+	// Invocation specific context
+	if info, ok := invocation.FromContext(ctx); ok {
+		additionalCtx = append(additionalCtx,
+			logctx.String("cold_start", strconv.FormatBool(info.ColdStart)),
+			logctx.String("function_version", info.FunctionVersion),
+			logctx.String("invocation_seq", strconv.FormatUint(info.Seq, 10)),
+		)
+		if deadline, ok := ctx.Deadline(); ok {
+			additionalCtx = append(additionalCtx,
+				logctx.String("remaining_ms", strconv.FormatInt(clk.Until(deadline).Milliseconds(), 10)),
+			)
+		}
+	}
+
 	// Set context
 	ctx = logctx.Add(
 		ctx,
@@ -102,10 +199,11 @@ func contextFromEvent[E any](ctx context.Context, event E) (string, context.Cont
 func transformResponse[R any](response R, requestID string) R {
 	if apigwV1Response, ok := any(response).(events.APIGatewayProxyResponse); ok {
 		// APIGatewayProxyResponse (API Gateway V1)
-		if apigwV1Response.Headers != nil {
-			// Add request id to response headers
-			apigwV1Response.Headers["x-request-id"] = requestID
+		if apigwV1Response.Headers == nil {
+			apigwV1Response.Headers = map[string]string{}
 		}
+		// Add request id to response headers
+		apigwV1Response.Headers["x-request-id"] = requestID
 		if transformed, ok := any(apigwV1Response).(R); ok {
 			response = transformed
 		}
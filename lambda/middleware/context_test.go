@@ -3,15 +3,30 @@ package middleware
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-lambda-go/cfn"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambdacontext"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/ellogroup/ello-golang-clock/clock"
 	"github.com/ellogroup/ello-golang-ctx/v2/logctx"
+
+	"github.com/ellogroup/ello-golang-aws/v2/internal/invocation"
 )
 
+type loggableEvent struct {
+	tenant  string
+	retries int
+}
+
+func (e loggableEvent) LogFields() []slog.Attr {
+	return []slog.Attr{slog.String("tenant", e.tenant), slog.Int("retries", e.retries)}
+}
+
 func TestContext_Wrap(t *testing.T) {
 	type args[E any] struct {
 		ctx   context.Context
@@ -29,7 +44,7 @@ func TestContext_Wrap(t *testing.T) {
 	tests := []testCase[string]{
 		{
 			name: "lambda context, request id added to context, handler returns nil, returns nil",
-			c:    contextNoResponse[string]{},
+			c:    contextNoResponse[string]{clock: clock.NewSystem()},
 			args: args[string]{
 				ctx: lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
 					AwsRequestID: "lambda-request-id-123",
@@ -46,7 +61,7 @@ func TestContext_Wrap(t *testing.T) {
 		},
 		{
 			name: "lambda context, request id added to context, handler returns error, returns error",
-			c:    contextNoResponse[string]{},
+			c:    contextNoResponse[string]{clock: clock.NewSystem()},
 			args: args[string]{
 				ctx: lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
 					AwsRequestID: "lambda-request-id-123",
@@ -97,7 +112,7 @@ func TestContextWithResponse_Wrap(t *testing.T) {
 	tests := []testCase[string, any]{
 		{
 			name: "lambda context, request id added to context, handler returns string, returns string",
-			c:    contextWithResponse[string, any]{},
+			c:    contextWithResponse[string, any]{clock: clock.NewSystem()},
 			args: args[string]{
 				ctx: lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
 					AwsRequestID: "lambda-request-id-123",
@@ -116,7 +131,7 @@ func TestContextWithResponse_Wrap(t *testing.T) {
 		},
 		{
 			name: "lambda context, request id added to context, handler returns apigw response, returns transformed response",
-			c:    contextWithResponse[string, any]{},
+			c:    contextWithResponse[string, any]{clock: clock.NewSystem()},
 			args: args[string]{
 				ctx: lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
 					AwsRequestID: "lambda-request-id-123",
@@ -139,7 +154,7 @@ func TestContextWithResponse_Wrap(t *testing.T) {
 		},
 		{
 			name: "lambda context, request id added to context, handler returns error, returns error",
-			c:    contextWithResponse[string, any]{},
+			c:    contextWithResponse[string, any]{clock: clock.NewSystem()},
 			args: args[string]{
 				ctx: lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
 					AwsRequestID: "lambda-request-id-123",
@@ -231,16 +246,154 @@ func Test_contextFromEvent(t *testing.T) {
 				logctx.String("request_path", "/test/path"),
 			},
 		},
+		{
+			name: "eventbridge event. lambda context, request id and event details added to context, handler returns request id and context",
+			args: args[any]{
+				ctx: lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "lambda-request-id-123"}),
+				event: events.EventBridgeEvent{
+					ID:         "event-id-123",
+					Source:     "com.example.orders",
+					DetailType: "OrderPlaced",
+				},
+			},
+			wantReqID: "lambda-request-id-123",
+			wantCtx: &logctx.LogCtx{
+				logctx.String("request_id", "lambda-request-id-123"),
+				logctx.String("lambda_request_id", "lambda-request-id-123"),
+				logctx.String("event_id", "event-id-123"),
+				logctx.String("event_source", "com.example.orders"),
+				logctx.String("event_detail_type", "OrderPlaced"),
+			},
+		},
+		{
+			name: "kinesis event. lambda context, request id and shard details added to context, handler returns request id and context",
+			args: args[any]{
+				ctx: lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "lambda-request-id-123"}),
+				event: events.KinesisEvent{Records: []events.KinesisEventRecord{
+					{EventID: "shardId-000000000001:100", Kinesis: events.KinesisRecord{SequenceNumber: "100"}},
+					{EventID: "shardId-000000000001:200", Kinesis: events.KinesisRecord{SequenceNumber: "200"}},
+				}},
+			},
+			wantReqID: "lambda-request-id-123",
+			wantCtx: &logctx.LogCtx{
+				logctx.String("request_id", "lambda-request-id-123"),
+				logctx.String("lambda_request_id", "lambda-request-id-123"),
+				logctx.String("shard_id", "shardId-000000000001"),
+				logctx.String("first_sequence_number", "100"),
+				logctx.String("last_sequence_number", "200"),
+			},
+		},
+		{
+			name: "cfn event. lambda context, request id and resource details added to context, handler returns request id and context",
+			args: args[any]{
+				ctx: lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "lambda-request-id-123"}),
+				event: cfn.Event{
+					RequestType:       cfn.RequestUpdate,
+					StackID:           "arn:aws:cloudformation:eu-west-2:123456789012:stack/app/1",
+					LogicalResourceID: "BucketPolicy",
+				},
+			},
+			wantReqID: "lambda-request-id-123",
+			wantCtx: &logctx.LogCtx{
+				logctx.String("request_id", "lambda-request-id-123"),
+				logctx.String("lambda_request_id", "lambda-request-id-123"),
+				logctx.String("stack_id", "arn:aws:cloudformation:eu-west-2:123456789012:stack/app/1"),
+				logctx.String("logical_resource_id", "BucketPolicy"),
+				logctx.String("cfn_request_type", "Update"),
+			},
+		},
+		{
+			name: "cognito event. lambda context, request id and user pool details added to context, handler returns request id and context",
+			args: args[any]{
+				ctx: lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "lambda-request-id-123"}),
+				event: events.CognitoEventUserPoolsPreSignup{
+					CognitoEventUserPoolsHeader: events.CognitoEventUserPoolsHeader{
+						TriggerSource: "PreSignUp_SignUp",
+						UserPoolID:    "eu-west-2_abc",
+						UserName:      "admin",
+					},
+				},
+			},
+			wantReqID: "lambda-request-id-123",
+			wantCtx: &logctx.LogCtx{
+				logctx.String("request_id", "lambda-request-id-123"),
+				logctx.String("lambda_request_id", "lambda-request-id-123"),
+				logctx.String("user_pool_id", "eu-west-2_abc"),
+				logctx.String("trigger_source", "PreSignUp_SignUp"),
+				logctx.String("username_hash", "8c6976e5b5410415"),
+			},
+		},
+		{
+			name: "loggable event. lambda context, request id and log fields of the event added to context, handler returns request id and context",
+			args: args[any]{
+				ctx:   lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "lambda-request-id-123"}),
+				event: loggableEvent{tenant: "acme", retries: 2},
+			},
+			wantReqID: "lambda-request-id-123",
+			wantCtx: &logctx.LogCtx{
+				logctx.String("request_id", "lambda-request-id-123"),
+				logctx.String("lambda_request_id", "lambda-request-id-123"),
+				logctx.String("tenant", "acme"),
+				logctx.String("retries", "2"),
+			},
+		},
+		{
+			name: "websocket event. lambda context, request id, connection id and route key added to context, handler returns request id and context",
+			args: args[any]{
+				ctx: lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "lambda-request-id-123"}),
+				event: events.APIGatewayWebsocketProxyRequest{
+					RequestContext: events.APIGatewayWebsocketProxyRequestContext{
+						ConnectionID: "L0SM9cOFvHcCIhw=",
+						RouteKey:     "sendMessage",
+					},
+				},
+			},
+			wantReqID: "lambda-request-id-123",
+			wantCtx: &logctx.LogCtx{
+				logctx.String("request_id", "lambda-request-id-123"),
+				logctx.String("lambda_request_id", "lambda-request-id-123"),
+				logctx.String("connection_id", "L0SM9cOFvHcCIhw="),
+				logctx.String("route_key", "sendMessage"),
+			},
+		},
+		{
+			name: "string event. invocation context, invocation details added to context, handler returns request id and context",
+			args: args[any]{
+				ctx: invocation.NewContext(
+					lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "lambda-request-id-123"}),
+					invocation.Info{ColdStart: true, Seq: 1, FunctionVersion: "$LATEST"},
+				),
+				event: "test-event",
+			},
+			wantReqID: "lambda-request-id-123",
+			wantCtx: &logctx.LogCtx{
+				logctx.String("request_id", "lambda-request-id-123"),
+				logctx.String("lambda_request_id", "lambda-request-id-123"),
+				logctx.String("cold_start", "true"),
+				logctx.String("function_version", "$LATEST"),
+				logctx.String("invocation_seq", "1"),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotReqID, gotCtx := contextFromEvent(tt.args.ctx, tt.args.event)
+			gotReqID, gotCtx := contextFromEvent(tt.args.ctx, clock.NewSystem(), tt.args.event)
 			assert.Equalf(t, tt.wantReqID, gotReqID, "contextFromEvent(%v, %v)", tt.args.ctx, tt.args.event)
 			assert.Equalf(t, tt.wantCtx, logctx.Get(gotCtx), "contextFromEvent(%v, %v)", tt.args.ctx, tt.args.event)
 		})
 	}
 }
 
+func Test_contextFromEvent_remainingMs(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithDeadline(invocation.NewContext(context.Background(), invocation.Info{Seq: 2}), now.Add(3*time.Second))
+	defer cancel()
+
+	_, gotCtx := contextFromEvent(ctx, clock.NewFixed(now), "test-event")
+
+	assert.Contains(t, *logctx.Get(gotCtx), logctx.String("remaining_ms", "3000"))
+}
+
 func Test_transformResponse(t *testing.T) {
 	type args[R any] struct {
 		response  R
@@ -268,6 +421,14 @@ func Test_transformResponse(t *testing.T) {
 			},
 			want: events.APIGatewayProxyResponse{Headers: map[string]string{"x-request-id": "test-request-id"}},
 		},
+		{
+			name: "apigw response with nil headers, returns apigw response with request id header",
+			args: args[any]{
+				response:  events.APIGatewayProxyResponse{StatusCode: 204},
+				requestID: "test-request-id",
+			},
+			want: events.APIGatewayProxyResponse{StatusCode: 204, Headers: map[string]string{"x-request-id": "test-request-id"}},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,261 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const (
+	corsHeaderAllowOrigin      = "Access-Control-Allow-Origin"
+	corsHeaderAllowMethods     = "Access-Control-Allow-Methods"
+	corsHeaderAllowHeaders     = "Access-Control-Allow-Headers"
+	corsHeaderAllowCredentials = "Access-Control-Allow-Credentials"
+	corsHeaderExposeHeaders    = "Access-Control-Expose-Headers"
+	corsHeaderMaxAge           = "Access-Control-Max-Age"
+	corsHeaderRequestMethod    = "Access-Control-Request-Method"
+)
+
+var (
+	defaultCORSMethods = []string{
+		http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+	}
+	defaultCORSHeaders = []string{"Authorization", "Content-Type"}
+)
+
+type corsOptions struct {
+	anyOrigin      bool
+	origins        []string
+	originPatterns []*regexp.Regexp
+	methods        []string
+	headers        []string
+	exposedHeaders []string
+	credentials    bool
+	maxAge         time.Duration
+}
+
+// CORSOption configures NewCORSWithResponse.
+type CORSOption func(*corsOptions)
+
+// WithCORSOrigins allows requests from origins, each either an exact origin ("https://app.example.com"), a wildcard
+// subdomain ("https://*.example.com", not matching "https://example.com" itself) or "*" for any origin. Call it once,
+// with every origin.
+func WithCORSOrigins(origins ...string) CORSOption {
+	return func(o *corsOptions) {
+		o.anyOrigin = slices.Contains(origins, "*")
+		o.origins = origins
+	}
+}
+
+// WithCORSOriginPatterns allows requests from origins matching any of patterns, which should be anchored with ^ and $.
+func WithCORSOriginPatterns(patterns ...*regexp.Regexp) CORSOption {
+	return func(o *corsOptions) {
+		o.originPatterns = patterns
+	}
+}
+
+// WithCORSMethods sets the methods allowed in preflight responses. Defaults to GET, HEAD, POST, PUT, PATCH and DELETE.
+func WithCORSMethods(methods ...string) CORSOption {
+	return func(o *corsOptions) {
+		o.methods = methods
+	}
+}
+
+// WithCORSHeaders sets the request headers allowed in preflight responses. Defaults to Authorization and Content-Type.
+func WithCORSHeaders(headers ...string) CORSOption {
+	return func(o *corsOptions) {
+		o.headers = headers
+	}
+}
+
+// WithCORSExposedHeaders sets the response headers, other than the CORS-safelisted ones, browsers expose to scripts.
+func WithCORSExposedHeaders(headers ...string) CORSOption {
+	return func(o *corsOptions) {
+		o.exposedHeaders = headers
+	}
+}
+
+// WithCORSCredentials allows requests with credentials (cookies or an Authorization header). It can't be combined with
+// any origin ("*"), which would let every site make requests with the credentials of a user: NewCORSWithResponse
+// panics.
+func WithCORSCredentials() CORSOption {
+	return func(o *corsOptions) {
+		o.credentials = true
+	}
+}
+
+// WithCORSMaxAge sets how long browsers cache preflight responses, in whole seconds. Defaults to the browser default.
+func WithCORSMaxAge(d time.Duration) CORSOption {
+	return func(o *corsOptions) {
+		o.maxAge = d
+	}
+}
+
+type corsWithResponse[E, R any] struct {
+	opts corsOptions
+}
+
+// NewCORSWithResponse returns an implementation of WithResponse for the CORS middleware.
+//
+// The CORS middleware adds Access-Control-* headers to the responses of API Gateway v1 and v2 and Lambda Function URL
+// requests from allowed origins (see WithCORSOrigins and WithCORSOriginPatterns), and a Vary: Origin header to every
+// response, with or without an Origin header, unless any origin is allowed. Preflight requests (OPTIONS requests with
+// an Access-Control-Request-Method header) get a 204 response without calling the handler, with CORS headers only if
+// the origin and method are allowed.
+//
+// Put it first in the chain, so responses of other middleware, such as the response.NewErrorCode responses of the auth
+// and timeout middleware, get the headers too.
+//
+// It panics if credentials are allowed for any origin (see WithCORSCredentials).
+func NewCORSWithResponse[E, R any](options ...CORSOption) WithResponse[E, R] {
+	opts := corsOptions{methods: defaultCORSMethods, headers: defaultCORSHeaders}
+	for _, option := range options {
+		option(&opts)
+	}
+	if opts.anyOrigin && opts.credentials {
+		panic("middleware: CORS credentials can not be allowed for any origin \"*\", list the allowed origins instead")
+	}
+	return &corsWithResponse[E, R]{opts: opts}
+}
+
+func (c corsWithResponse[E, R]) Wrap(next func(context.Context, E) (R, error)) func(context.Context, E) (R, error) {
+	return func(ctx context.Context, event E) (R, error) {
+		origin := requestHeader(event, "Origin")
+		allowed := origin != "" && c.allowed(origin)
+
+		if origin != "" && requestMethod(event) == http.MethodOptions && requestHeader(event, corsHeaderRequestMethod) != "" {
+			if res, ok := preflightResponse[R](); ok {
+				return c.preflightHeaders(res, origin, allowed && slices.Contains(c.opts.methods, requestHeader(event, corsHeaderRequestMethod))), nil
+			}
+		}
+
+		res, err := next(ctx, event)
+		if err != nil {
+			return res, err
+		}
+		return updateResponseHeaders(res, func(headers map[string]string) {
+			c.vary(headers)
+			if !allowed {
+				return
+			}
+			c.allowOrigin(headers, origin)
+			if len(c.opts.exposedHeaders) > 0 {
+				headers[corsHeaderExposeHeaders] = strings.Join(c.opts.exposedHeaders, ", ")
+			}
+		}), nil
+	}
+}
+
+func (c corsWithResponse[E, R]) preflightHeaders(res R, origin string, allowed bool) R {
+	return updateResponseHeaders(res, func(headers map[string]string) {
+		c.vary(headers)
+		if !allowed {
+			return
+		}
+		c.allowOrigin(headers, origin)
+		headers[corsHeaderAllowMethods] = strings.Join(c.opts.methods, ", ")
+		if len(c.opts.headers) > 0 {
+			headers[corsHeaderAllowHeaders] = strings.Join(c.opts.headers, ", ")
+		}
+		if c.opts.maxAge > 0 {
+			headers[corsHeaderMaxAge] = strconv.FormatInt(int64(c.opts.maxAge.Seconds()), 10)
+		}
+	})
+}
+
+// allowed returns whether requests from origin are allowed.
+func (c corsWithResponse[E, R]) allowed(origin string) bool {
+	if c.opts.anyOrigin {
+		return true
+	}
+	for _, allowed := range c.opts.origins {
+		if strings.EqualFold(allowed, origin) || matchWildcardOrigin(allowed, origin) {
+			return true
+		}
+	}
+	return slices.ContainsFunc(c.opts.originPatterns, func(pattern *regexp.Regexp) bool {
+		return pattern.MatchString(origin)
+	})
+}
+
+func (c corsWithResponse[E, R]) allowOrigin(headers map[string]string, origin string) {
+	if c.opts.anyOrigin {
+		headers[corsHeaderAllowOrigin] = "*"
+	} else {
+		headers[corsHeaderAllowOrigin] = origin
+	}
+	if c.opts.credentials {
+		headers[corsHeaderAllowCredentials] = "true"
+	}
+}
+
+// vary adds Origin to the Vary header, unless the response is the same for every origin, so caches don't serve a
+// response without CORS headers, or with those of another origin, to a request from an allowed origin.
+func (c corsWithResponse[E, R]) vary(headers map[string]string) {
+	if c.opts.anyOrigin {
+		return
+	}
+	for k, v := range headers {
+		if !strings.EqualFold(k, "Vary") {
+			continue
+		}
+		for _, field := range strings.Split(v, ",") {
+			if field = strings.TrimSpace(field); field == "*" || strings.EqualFold(field, "Origin") {
+				return
+			}
+		}
+		headers[k] = v + ", Origin"
+		return
+	}
+	headers["Vary"] = "Origin"
+}
+
+// matchWildcardOrigin returns whether origin is a subdomain of a wildcard origin, e.g. "https://*.example.com".
+func matchWildcardOrigin(wildcard, origin string) bool {
+	prefix, suffix, ok := strings.Cut(strings.ToLower(wildcard), "*")
+	if !ok || !strings.HasSuffix(prefix, "://") || !strings.HasPrefix(suffix, ".") {
+		return false
+	}
+	origin = strings.ToLower(origin)
+	if len(origin) <= len(prefix)+len(suffix) || !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+	return !strings.ContainsAny(origin[len(prefix):len(origin)-len(suffix)], "/:@")
+}
+
+// requestMethod returns the HTTP method of API Gateway v1 and v2, Lambda Function URL and ALB requests.
+func requestMethod(event any) string {
+	switch e := event.(type) {
+	case events.APIGatewayProxyRequest:
+		return e.HTTPMethod
+	case events.APIGatewayV2HTTPRequest:
+		return e.RequestContext.HTTP.Method
+	case events.LambdaFunctionURLRequest:
+		return e.RequestContext.HTTP.Method
+	case events.ALBTargetGroupRequest:
+		return e.HTTPMethod
+	}
+	return ""
+}
+
+// preflightResponse returns an empty 204 response for API Gateway v1 and v2 and Lambda Function URL response types.
+func preflightResponse[R any]() (R, bool) {
+	var res R
+	var converted any
+	switch any(res).(type) {
+	case events.APIGatewayProxyResponse:
+		converted = events.APIGatewayProxyResponse{StatusCode: http.StatusNoContent}
+	case events.APIGatewayV2HTTPResponse:
+		converted = events.APIGatewayV2HTTPResponse{StatusCode: http.StatusNoContent}
+	case events.LambdaFunctionURLResponse:
+		converted = events.LambdaFunctionURLResponse{StatusCode: http.StatusNoContent}
+	}
+	res, ok := converted.(R)
+	return res, ok
+}
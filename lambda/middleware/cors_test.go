@@ -0,0 +1,254 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/response"
+)
+
+func Test_corsWithResponse_Wrap(t *testing.T) {
+	origins := WithCORSOrigins("https://app.example.com", "https://*.example.org")
+	tests := []struct {
+		name        string
+		options     []CORSOption
+		method      string
+		headers     map[string]string
+		handler     events.APIGatewayProxyResponse
+		wantCalled  bool
+		wantStatus  int
+		wantHeaders map[string]string
+	}{
+		{
+			name:        "no origin, vary only",
+			options:     []CORSOption{origins},
+			method:      http.MethodGet,
+			headers:     map[string]string{},
+			handler:     events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Headers: map[string]string{"Content-Type": "text/plain"}},
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Content-Type": "text/plain", "Vary": "Origin"},
+		},
+		{
+			name:        "no origin, any origin, response unchanged",
+			options:     []CORSOption{WithCORSOrigins("*")},
+			method:      http.MethodGet,
+			headers:     map[string]string{},
+			handler:     events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Headers: map[string]string{"Content-Type": "text/plain"}},
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Content-Type": "text/plain"},
+		},
+		{
+			name:        "no origin, options with request method, not a preflight",
+			options:     []CORSOption{origins},
+			method:      http.MethodOptions,
+			headers:     map[string]string{"Access-Control-Request-Method": http.MethodGet},
+			handler:     events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Vary": "Origin"},
+		},
+		{
+			name:       "exact origin, allowed",
+			options:    []CORSOption{origins, WithCORSExposedHeaders("X-Request-Id")},
+			method:     http.MethodGet,
+			headers:    map[string]string{"origin": "https://app.example.com"},
+			handler:    events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+			wantCalled: true,
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":   "https://app.example.com",
+				"Access-Control-Expose-Headers": "X-Request-Id",
+				"Vary":                          "Origin",
+			},
+		},
+		{
+			name:        "wildcard subdomain, allowed",
+			options:     []CORSOption{origins},
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "https://admin.eu.example.org"},
+			handler:     events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Headers: map[string]string{"vary": "Accept-Encoding"}},
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "https://admin.eu.example.org", "vary": "Accept-Encoding, Origin"},
+		},
+		{
+			name:        "wildcard domain itself, not allowed",
+			options:     []CORSOption{origins},
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "https://example.org"},
+			handler:     events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Vary": "Origin"},
+		},
+		{
+			name:        "lookalike domain, not allowed",
+			options:     []CORSOption{origins},
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "https://evil.com/.example.org"},
+			handler:     events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Vary": "Origin"},
+		},
+		{
+			name:        "pattern, allowed",
+			options:     []CORSOption{WithCORSOriginPatterns(regexp.MustCompile(`^http://localhost:\d+$`))},
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "http://localhost:3000"},
+			handler:     events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "http://localhost:3000", "Vary": "Origin"},
+		},
+		{
+			name:        "any origin, star without vary",
+			options:     []CORSOption{WithCORSOrigins("*")},
+			method:      http.MethodGet,
+			headers:     map[string]string{"Origin": "https://anywhere.com"},
+			handler:     events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "*"},
+		},
+		{
+			name:       "credentials, origin echoed",
+			options:    []CORSOption{origins, WithCORSCredentials()},
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "https://app.example.com"},
+			handler:    events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+			wantCalled: true,
+			wantStatus: http.StatusOK,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name:       "error code response, headers added",
+			options:    []CORSOption{origins},
+			method:     http.MethodGet,
+			headers:    map[string]string{"Origin": "https://app.example.com"},
+			handler:    response.NewErrorCode(response.ErrorCodeUnauthorized),
+			wantCalled: true,
+			wantStatus: http.StatusUnauthorized,
+			wantHeaders: map[string]string{
+				"Content-Type":                "application/json",
+				"Access-Control-Allow-Origin": "https://app.example.com",
+				"Vary":                        "Origin",
+			},
+		},
+		{
+			name:       "preflight, short-circuited",
+			options:    []CORSOption{origins, WithCORSMethods(http.MethodGet, http.MethodPost), WithCORSHeaders("Content-Type", "X-Api-Key"), WithCORSMaxAge(time.Hour)},
+			method:     http.MethodOptions,
+			headers:    map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": http.MethodPost},
+			wantStatus: http.StatusNoContent,
+			wantHeaders: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Content-Type, X-Api-Key",
+				"Access-Control-Max-Age":       "3600",
+				"Vary":                         "Origin",
+			},
+		},
+		{
+			name:        "preflight of method not allowed, no cors headers",
+			options:     []CORSOption{origins},
+			method:      http.MethodOptions,
+			headers:     map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": "PURGE"},
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{"Vary": "Origin"},
+		},
+		{
+			name:        "preflight of origin not allowed, no cors headers",
+			options:     []CORSOption{origins},
+			method:      http.MethodOptions,
+			headers:     map[string]string{"Origin": "https://evil.com", "Access-Control-Request-Method": http.MethodGet},
+			wantStatus:  http.StatusNoContent,
+			wantHeaders: map[string]string{"Vary": "Origin"},
+		},
+		{
+			name:        "options without request method, not a preflight",
+			options:     []CORSOption{origins},
+			method:      http.MethodOptions,
+			headers:     map[string]string{"Origin": "https://app.example.com"},
+			handler:     events.APIGatewayProxyResponse{StatusCode: http.StatusOK},
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"Access-Control-Allow-Origin": "https://app.example.com", "Vary": "Origin"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				called = true
+				return tt.handler, nil
+			}
+
+			m := NewCORSWithResponse[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](tt.options...)
+			got, err := m.Wrap(handler)(context.Background(), events.APIGatewayProxyRequest{HTTPMethod: tt.method, Headers: tt.headers})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCalled, called)
+			assert.Equal(t, tt.wantStatus, got.StatusCode)
+			assert.Equal(t, tt.wantHeaders, got.Headers)
+		})
+	}
+}
+
+func TestNewCORSWithResponse_anyOriginWithCredentials(t *testing.T) {
+	assert.Panics(t, func() {
+		NewCORSWithResponse[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](WithCORSOrigins("*"), WithCORSCredentials())
+	})
+}
+
+func Test_corsWithResponse_Wrap_v2(t *testing.T) {
+	m := NewCORSWithResponse[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse](WithCORSOrigins("https://app.example.com"))
+	handler := func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusOK}, nil
+	}
+	event := events.APIGatewayV2HTTPRequest{Headers: map[string]string{"origin": "https://app.example.com"}}
+
+	got, err := m.Wrap(handler)(context.Background(), event)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"Access-Control-Allow-Origin": "https://app.example.com", "Vary": "Origin"}, got.Headers)
+
+	event.RequestContext.HTTP.Method = http.MethodOptions
+	event.Headers["access-control-request-method"] = http.MethodGet
+	got, err = m.Wrap(handler)(context.Background(), event)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, got.StatusCode)
+	assert.Equal(t, "GET, HEAD, POST, PUT, PATCH, DELETE", got.Headers["Access-Control-Allow-Methods"])
+	assert.Equal(t, "Authorization, Content-Type", got.Headers["Access-Control-Allow-Headers"])
+}
+
+func Test_corsWithResponse_Wrap_functionURL(t *testing.T) {
+	m := NewCORSWithResponse[events.LambdaFunctionURLRequest, events.LambdaFunctionURLResponse](WithCORSOrigins("https://app.example.com"))
+	errHandler := errors.New("error")
+
+	got, err := m.Wrap(func(context.Context, events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+		return events.LambdaFunctionURLResponse{StatusCode: http.StatusCreated}, nil
+	})(context.Background(), events.LambdaFunctionURLRequest{Headers: map[string]string{"origin": "https://app.example.com"}})
+	assert.NoError(t, err)
+	assert.Equal(t, events.LambdaFunctionURLResponse{
+		StatusCode: http.StatusCreated,
+		Headers:    map[string]string{"Access-Control-Allow-Origin": "https://app.example.com", "Vary": "Origin"},
+	}, got)
+
+	_, err = m.Wrap(func(context.Context, events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+		return events.LambdaFunctionURLResponse{}, errHandler
+	})(context.Background(), events.LambdaFunctionURLRequest{Headers: map[string]string{"origin": "https://app.example.com"}})
+	assert.ErrorIs(t, err, errHandler)
+}
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -22,6 +24,14 @@ type eventLoggerOptions struct {
 	eventStartedLevel   slog.Level
 	eventCompletedLevel slog.Level
 	sanitizer           Sanitizer
+	logError            bool
+	logResponse         bool
+	levelFromOutcome    bool
+	sampleRate          *float64
+	routeSampleRates    map[string]float64
+	alwaysLogOnError    bool
+	debugSampleRate     float64
+	levelEnv            string
 }
 
 var defaultEventLoggerOptions = eventLoggerOptions{
@@ -105,6 +115,33 @@ func WithEventLoggerSanitizer(s Sanitizer) EventLoggerOption {
 	}
 }
 
+// WithEventLoggerError adds the error returned by the handler to the event-completed record, as
+// an "error" group containing its message, its type and the messages of every error it wraps.
+func WithEventLoggerError() EventLoggerOption {
+	return func(opts *eventLoggerOptions) {
+		opts.logError = true
+	}
+}
+
+// WithEventLoggerResponse adds the response returned by the handler to the event-completed record
+// of NewEventLoggerWithResponse. The response is passed through the same Sanitizer as the event,
+// so by default sensitive headers and the body of known HTTP response types are redacted.
+func WithEventLoggerResponse() EventLoggerOption {
+	return func(opts *eventLoggerOptions) {
+		opts.logResponse = true
+	}
+}
+
+// WithEventLoggerLevelFromOutcome raises the level of the event-completed record when the event
+// did not succeed: to slog.LevelError when the handler returns an error or an HTTP response has a
+// 5xx status code, and to slog.LevelWarn when an HTTP response has a 4xx status code. The level
+// is never lowered below the one set with WithEventLoggerEventCompletedLevel.
+func WithEventLoggerLevelFromOutcome() EventLoggerOption {
+	return func(opts *eventLoggerOptions) {
+		opts.levelFromOutcome = true
+	}
+}
+
 func sanitizeEvent(opts *eventLoggerOptions, event any) any {
 	if opts.sanitizer != nil {
 		return opts.sanitizer.Sanitize(event)
@@ -116,6 +153,7 @@ type eventLoggerNoResponse[E any] struct {
 	clock  clock.Clock
 	logger *slog.Logger
 	opts   *eventLoggerOptions
+	random func() float64
 }
 
 // NewEventLogger returns an implementation of NoResponse middleware.
@@ -132,19 +170,29 @@ func NewEventLogger[E any](logger *slog.Logger, options ...EventLoggerOption) No
 	for _, option := range options {
 		option(l.opts)
 	}
+	l.logger = loggerWithLevelFromEnv(logger, l.opts.levelEnv)
 	return l
 }
 
 func (l eventLoggerNoResponse[E]) Wrap(next func(context.Context, E) error) func(context.Context, E) error {
 	return func(ctx context.Context, event E) error {
 		// Log when the event starts
-		start := l.clock.Now()
-		l.logger.LogAttrs(ctx, l.opts.eventStartedLevel, l.opts.eventStartedMsg, slog.Any("event", sanitizeEvent(l.opts, event)))
+		ctx, inv := startLoggedEvent(ctx, l.clock, l.logger, l.opts, l.random, event)
 
 		err := next(ctx, event)
 
 		// Log when the event completes
-		l.logger.LogAttrs(ctx, l.opts.eventCompletedLevel, l.opts.eventCompletedMsg, slog.Duration("duration", l.clock.Since(start)))
+		if inv.completing(ctx, 0, err) {
+			attr := []slog.Attr{
+				slog.Duration("duration", l.clock.Since(inv.start)),
+			}
+
+			if err != nil && l.opts.logError {
+				attr = append(attr, errorAttr(err))
+			}
+
+			inv.complete(ctx, completedLevel(l.opts, 0, err), attr...)
+		}
 
 		// Return response
 		return err
@@ -155,6 +203,7 @@ type eventLoggerWithResponse[E, R any] struct {
 	clock  clock.Clock
 	logger *slog.Logger
 	opts   *eventLoggerOptions
+	random func() float64
 }
 
 // NewEventLoggerWithResponse returns an implementation of WithResponse middleware.
@@ -162,7 +211,8 @@ type eventLoggerWithResponse[E, R any] struct {
 // The event logger middleware logs the event start and end. The event start log record contains the event and the event
 // end log record contains the duration of the event.
 //
-// For API Gateway v1 requests the log record also contains the status code of the response.
+// For API Gateway v1/v2, ALB and Lambda Function URL responses the log record also contains the status code of the
+// response.
 func NewEventLoggerWithResponse[E, R any](logger *slog.Logger, options ...EventLoggerOption) WithResponse[E, R] {
 	opts := defaultEventLoggerOptions
 	l := &eventLoggerWithResponse[E, R]{
@@ -173,30 +223,105 @@ func NewEventLoggerWithResponse[E, R any](logger *slog.Logger, options ...EventL
 	for _, option := range options {
 		option(l.opts)
 	}
+	l.logger = loggerWithLevelFromEnv(logger, l.opts.levelEnv)
 	return l
 }
 
 func (l eventLoggerWithResponse[E, R]) Wrap(next func(context.Context, E) (R, error)) func(context.Context, E) (R, error) {
 	return func(ctx context.Context, event E) (R, error) {
 		// Log when the event starts
-		start := l.clock.Now()
-		l.logger.LogAttrs(ctx, l.opts.eventStartedLevel, l.opts.eventStartedMsg, slog.Any("event", sanitizeEvent(l.opts, event)))
+		ctx, inv := startLoggedEvent(ctx, l.clock, l.logger, l.opts, l.random, event)
 
 		response, err := next(ctx, event)
 
 		// Log when the event completes
-		attr := []slog.Attr{
-			slog.Duration("duration", l.clock.Since(start)),
-		}
+		statusCode, hasStatusCode := responseStatusCode(response)
+		if inv.completing(ctx, statusCode, err) {
+			attr := []slog.Attr{
+				slog.Duration("duration", l.clock.Since(inv.start)),
+			}
 
-		if apigwV1Response, ok := any(response).(events.APIGatewayProxyResponse); ok {
-			// APIGatewayProxyResponse (API Gateway V1)
-			attr = append(attr, slog.Int("status_code", apigwV1Response.StatusCode))
-		}
+			if hasStatusCode {
+				attr = append(attr, slog.Int("status_code", statusCode))
+			}
+
+			if err != nil && l.opts.logError {
+				attr = append(attr, errorAttr(err))
+			}
 
-		l.logger.LogAttrs(ctx, l.opts.eventCompletedLevel, l.opts.eventCompletedMsg, attr...)
+			if l.opts.logResponse {
+				attr = append(attr, slog.Any("response", sanitizeEvent(l.opts, response)))
+			}
+
+			inv.complete(ctx, completedLevel(l.opts, statusCode, err), attr...)
+		}
 
 		// Return response
 		return response, err
 	}
 }
+
+// responseStatusCode returns the status code of known HTTP response types.
+func responseStatusCode(response any) (int, bool) {
+	switch r := response.(type) {
+	case events.APIGatewayProxyResponse:
+		// APIGatewayProxyResponse (API Gateway V1)
+		return r.StatusCode, true
+	case events.APIGatewayV2HTTPResponse:
+		// APIGatewayV2HTTPResponse (API Gateway V2)
+		return r.StatusCode, true
+	case events.ALBTargetGroupResponse:
+		return r.StatusCode, true
+	case events.LambdaFunctionURLResponse:
+		return r.StatusCode, true
+	}
+	return 0, false
+}
+
+// completedLevel returns the level of the event-completed record, raised according to the outcome
+// of the event when WithEventLoggerLevelFromOutcome is set. statusCode is 0 for non-HTTP responses.
+func completedLevel(opts *eventLoggerOptions, statusCode int, err error) slog.Level {
+	level := opts.eventCompletedLevel
+	if !opts.levelFromOutcome {
+		return level
+	}
+
+	outcome := level
+	switch {
+	case err != nil || statusCode >= 500:
+		outcome = slog.LevelError
+	case statusCode >= 400:
+		outcome = slog.LevelWarn
+	}
+	return max(level, outcome)
+}
+
+// errorAttr returns an "error" group with the message and type of err, and the messages of every
+// error wrapped by it (depth first, including errors joined with errors.Join).
+func errorAttr(err error) slog.Attr {
+	return slog.Group("error",
+		slog.String("message", err.Error()),
+		slog.String("type", fmt.Sprintf("%T", err)),
+		slog.Any("chain", errorChain(err)),
+	)
+}
+
+func errorChain(err error) []string {
+	var chain []string
+	var walk func(error)
+	walk = func(e error) {
+		wrapped := []error{errors.Unwrap(e)}
+		if joined, ok := e.(interface{ Unwrap() []error }); ok {
+			wrapped = joined.Unwrap()
+		}
+		for _, w := range wrapped {
+			if w == nil {
+				continue
+			}
+			chain = append(chain, w.Error())
+			walk(w)
+		}
+	}
+	walk(err)
+	return chain
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"testing"
@@ -13,6 +14,8 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/ellogroup/ello-golang-clock/clock"
+
+	"github.com/ellogroup/ello-golang-aws/v2/internal/invocation"
 )
 
 type mockSlogHandler struct {
@@ -481,3 +484,221 @@ func TestTypedSanitizerFunc(t *testing.T) {
 		assert.Equal(t, "not an APIGatewayProxyRequest", got)
 	})
 }
+
+func Test_eventLoggerNoResponse_Wrap_logsError(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+	errCause := errors.New("connection refused")
+	errHandler := fmt.Errorf("fetch customer: %w", errCause)
+
+	mHandler := new(mockSlogHandler)
+	mHandler.On("Enabled", mock.Anything, mock.Anything).Return(true)
+	mHandler.On("Handle", mock.Anything, mock.MatchedBy(matchRecord(
+		defaultEventStartedMsg, slog.LevelInfo, []slog.Attr{slog.Any("event", "test")},
+	))).Return(nil)
+	mHandler.On("Handle", mock.Anything, mock.MatchedBy(matchRecord(
+		defaultEventCompletedMsg, slog.LevelError, []slog.Attr{
+			slog.Duration("duration", time.Duration(0)),
+			slog.Group("error",
+				slog.String("message", "fetch customer: connection refused"),
+				slog.String("type", "*fmt.wrapError"),
+				slog.Any("chain", []string{"connection refused"}),
+			),
+		},
+	))).Return(nil)
+
+	opts := defaultEventLoggerOptions
+	WithEventLoggerError()(&opts)
+	WithEventLoggerLevelFromOutcome()(&opts)
+
+	sut := &eventLoggerNoResponse[string]{
+		clock:  clock.NewFixed(now),
+		logger: slog.New(mHandler),
+		opts:   &opts,
+	}
+	fn := sut.Wrap(func(_ context.Context, _ string) error {
+		return errHandler
+	})
+	assert.ErrorIs(t, fn(context.Background(), "test"), errCause)
+
+	mHandler.AssertExpectations(t)
+}
+
+func Test_eventLoggerWithResponse_Wrap_outcome(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	type testCase struct {
+		name        string
+		options     []EventLoggerOption
+		handlerResp any
+		handlerErr  error
+		wantLevel   slog.Level
+		wantAttrs   []slog.Attr
+	}
+	tests := []testCase{
+		{
+			name:        "api gateway v2 response, logs status code",
+			handlerResp: events.APIGatewayV2HTTPResponse{StatusCode: http.StatusCreated},
+			wantLevel:   slog.LevelInfo,
+			wantAttrs: []slog.Attr{
+				slog.Duration("duration", time.Duration(0)),
+				slog.Int("status_code", http.StatusCreated),
+			},
+		},
+		{
+			name:        "alb response, logs status code",
+			handlerResp: events.ALBTargetGroupResponse{StatusCode: http.StatusOK},
+			wantLevel:   slog.LevelInfo,
+			wantAttrs: []slog.Attr{
+				slog.Duration("duration", time.Duration(0)),
+				slog.Int("status_code", http.StatusOK),
+			},
+		},
+		{
+			name:        "function url response, logs status code",
+			handlerResp: events.LambdaFunctionURLResponse{StatusCode: http.StatusOK},
+			wantLevel:   slog.LevelInfo,
+			wantAttrs: []slog.Attr{
+				slog.Duration("duration", time.Duration(0)),
+				slog.Int("status_code", http.StatusOK),
+			},
+		},
+		{
+			name:        "4xx response without level from outcome, level unchanged",
+			handlerResp: events.APIGatewayProxyResponse{StatusCode: http.StatusNotFound},
+			wantLevel:   slog.LevelInfo,
+			wantAttrs: []slog.Attr{
+				slog.Duration("duration", time.Duration(0)),
+				slog.Int("status_code", http.StatusNotFound),
+			},
+		},
+		{
+			name:        "4xx response with level from outcome, logs at warn",
+			options:     []EventLoggerOption{WithEventLoggerLevelFromOutcome()},
+			handlerResp: events.APIGatewayProxyResponse{StatusCode: http.StatusNotFound},
+			wantLevel:   slog.LevelWarn,
+			wantAttrs: []slog.Attr{
+				slog.Duration("duration", time.Duration(0)),
+				slog.Int("status_code", http.StatusNotFound),
+			},
+		},
+		{
+			name:        "5xx response with level from outcome, logs at error",
+			options:     []EventLoggerOption{WithEventLoggerLevelFromOutcome()},
+			handlerResp: events.APIGatewayV2HTTPResponse{StatusCode: http.StatusBadGateway},
+			wantLevel:   slog.LevelError,
+			wantAttrs: []slog.Attr{
+				slog.Duration("duration", time.Duration(0)),
+				slog.Int("status_code", http.StatusBadGateway),
+			},
+		},
+		{
+			name: "level from outcome never lowers the configured completed level",
+			options: []EventLoggerOption{
+				WithEventLoggerLevelFromOutcome(),
+				WithEventLoggerEventCompletedLevel(slog.LevelError),
+			},
+			handlerResp: events.APIGatewayProxyResponse{StatusCode: http.StatusBadRequest},
+			wantLevel:   slog.LevelError,
+			wantAttrs: []slog.Attr{
+				slog.Duration("duration", time.Duration(0)),
+				slog.Int("status_code", http.StatusBadRequest),
+			},
+		},
+		{
+			name:       "handler error with error logging and level from outcome, logs error at error",
+			options:    []EventLoggerOption{WithEventLoggerError(), WithEventLoggerLevelFromOutcome()},
+			handlerErr: errors.Join(errors.New("first"), errors.New("second")),
+			wantLevel:  slog.LevelError,
+			wantAttrs: []slog.Attr{
+				slog.Duration("duration", time.Duration(0)),
+				slog.Group("error",
+					slog.String("message", "first\nsecond"),
+					slog.String("type", "*errors.joinError"),
+					slog.Any("chain", []string{"first", "second"}),
+				),
+			},
+		},
+		{
+			name:    "response logging, logs sanitized response",
+			options: []EventLoggerOption{WithEventLoggerResponse()},
+			handlerResp: events.APIGatewayProxyResponse{
+				StatusCode: http.StatusOK,
+				Headers:    map[string]string{"Set-Cookie": "session=abc", "Content-Type": "application/json"},
+				Body:       `{"email":"jane@example.com"}`,
+			},
+			wantLevel: slog.LevelInfo,
+			wantAttrs: []slog.Attr{
+				slog.Duration("duration", time.Duration(0)),
+				slog.Int("status_code", http.StatusOK),
+				slog.Any("response", events.APIGatewayProxyResponse{
+					StatusCode: http.StatusOK,
+					Headers:    map[string]string{"Set-Cookie": redactedValue, "Content-Type": "application/json"},
+					Body:       redactedValue,
+				}),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mHandler := new(mockSlogHandler)
+			mHandler.On("Enabled", mock.Anything, mock.Anything).Return(true)
+			mHandler.On("Handle", mock.Anything, mock.MatchedBy(matchRecord(
+				defaultEventStartedMsg, slog.LevelInfo, []slog.Attr{slog.Any("event", "test-event")},
+			))).Return(nil)
+			mHandler.On("Handle", mock.Anything, mock.MatchedBy(matchRecord(
+				defaultEventCompletedMsg, tt.wantLevel, tt.wantAttrs,
+			))).Return(nil)
+
+			opts := defaultEventLoggerOptions
+			for _, option := range tt.options {
+				option(&opts)
+			}
+
+			sut := &eventLoggerWithResponse[string, any]{
+				clock:  clock.NewFixed(now),
+				logger: slog.New(mHandler),
+				opts:   &opts,
+			}
+			fn := sut.Wrap(func(_ context.Context, _ string) (any, error) {
+				return tt.handlerResp, tt.handlerErr
+			})
+			gotResp, _ := fn(context.Background(), "test-event")
+			assert.Equal(t, tt.handlerResp, gotResp)
+
+			mHandler.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_eventLoggerNoResponse_Wrap_logsInvocation(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	mHandler := new(mockSlogHandler)
+	mHandler.On("Enabled", mock.Anything, mock.Anything).Return(true)
+	mHandler.On("Handle", mock.Anything, mock.MatchedBy(matchRecord(
+		defaultEventStartedMsg, slog.LevelInfo, []slog.Attr{slog.Any("event", "test")},
+	))).Return(nil)
+	mHandler.On("Handle", mock.Anything, mock.MatchedBy(matchRecord(
+		defaultEventCompletedMsg, slog.LevelInfo, []slog.Attr{
+			slog.Duration("duration", time.Duration(0)),
+			slog.Bool("cold_start", true),
+			slog.String("function_version", "3"),
+			slog.Uint64("invocation_seq", 1),
+			slog.Int64("remaining_ms", 3000),
+		},
+	))).Return(nil)
+
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(3*time.Second))
+	defer cancel()
+	ctx = invocation.NewContext(ctx, invocation.Info{ColdStart: true, Seq: 1, FunctionVersion: "3"})
+
+	sut := &eventLoggerNoResponse[string]{
+		clock:  clock.NewFixed(now),
+		logger: slog.New(mHandler),
+		opts:   &defaultEventLoggerOptions,
+	}
+	fn := sut.Wrap(func(_ context.Context, _ string) error { return nil })
+	_ = fn(ctx, "test")
+
+	mHandler.AssertExpectations(t)
+}
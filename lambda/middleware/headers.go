@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// requestHeader returns the value of the header name of API Gateway v1 and v2, Lambda Function URL and ALB requests,
+// matched case-insensitively. The first value of multi-value headers is used when there is no single-value header.
+func requestHeader(event any, name string) string {
+	var headers map[string]string
+	var multiValueHeaders map[string][]string
+	switch e := event.(type) {
+	case events.APIGatewayProxyRequest:
+		headers, multiValueHeaders = e.Headers, e.MultiValueHeaders
+	case events.APIGatewayV2HTTPRequest:
+		headers = e.Headers
+	case events.LambdaFunctionURLRequest:
+		headers = e.Headers
+	case events.ALBTargetGroupRequest:
+		headers, multiValueHeaders = e.Headers, e.MultiValueHeaders
+	}
+	if v, ok := headers[name]; ok {
+		return v
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	for k, v := range multiValueHeaders {
+		if strings.EqualFold(k, name) && len(v) > 0 {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// withResponseHeaders returns a copy of API Gateway v1 and v2 and Lambda Function URL responses with headers added.
+// Other responses are returned unchanged.
+func withResponseHeaders[R any](res R, headers map[string]string) R {
+	return updateResponseHeaders(res, func(h map[string]string) {
+		maps.Copy(h, headers)
+	})
+}
+
+// updateResponseHeaders returns a copy of API Gateway v1 and v2 and Lambda Function URL responses with headers updated
+// by fn, which is passed a copy of the headers of the response, never nil. Other responses are returned unchanged.
+func updateResponseHeaders[R any](res R, fn func(map[string]string)) R {
+	var converted any
+	switch r := any(res).(type) {
+	case events.APIGatewayProxyResponse:
+		r.Headers = copyHeaders(r.Headers, fn)
+		converted = r
+	case events.APIGatewayV2HTTPResponse:
+		r.Headers = copyHeaders(r.Headers, fn)
+		converted = r
+	case events.LambdaFunctionURLResponse:
+		r.Headers = copyHeaders(r.Headers, fn)
+		converted = r
+	default:
+		return res
+	}
+	if r, ok := converted.(R); ok {
+		return r
+	}
+	return res
+}
+
+func copyHeaders(headers map[string]string, fn func(map[string]string)) map[string]string {
+	copied := make(map[string]string, len(headers)+4)
+	maps.Copy(copied, headers)
+	fn(copied)
+	return copied
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/aws/aws-lambda-go/cfn"
 	"github.com/aws/aws-lambda-go/events"
 )
 
@@ -63,6 +64,82 @@ func CommonSNS(logger *slog.Logger) SNS {
 	return Common[events.SNSEvent](logger)
 }
 
+// DynamoDB is a slice of WithResponse middleware for handlers of events.DynamoDBEvent that return
+// events.DynamoDBEventResponse.
+type DynamoDB []WithResponse[events.DynamoDBEvent, events.DynamoDBEventResponse]
+
+// CommonDynamoDB returns a slice of common middleware for handlers of events.DynamoDBEvent that return
+// events.DynamoDBEventResponse
+func CommonDynamoDB(logger *slog.Logger) DynamoDB {
+	return CommonWithResponse[events.DynamoDBEvent, events.DynamoDBEventResponse](logger)
+}
+
+// EventBridge is a slice of NoResponse middleware for handlers of events.EventBridgeEvent.
+type EventBridge []NoResponse[events.EventBridgeEvent]
+
+// CommonEventBridge returns a slice of common middleware for handlers of events.EventBridgeEvent
+func CommonEventBridge(logger *slog.Logger) EventBridge {
+	return Common[events.EventBridgeEvent](logger)
+}
+
+// Kinesis is a slice of WithResponse middleware for handlers of events.KinesisEvent that return
+// events.KinesisEventResponse.
+type Kinesis []WithResponse[events.KinesisEvent, events.KinesisEventResponse]
+
+// CommonKinesis returns a slice of common middleware for handlers of events.KinesisEvent that return
+// events.KinesisEventResponse
+func CommonKinesis(logger *slog.Logger) Kinesis {
+	return CommonWithResponse[events.KinesisEvent, events.KinesisEventResponse](logger)
+}
+
+// CloudFormation is a slice of NoResponse middleware for handlers of cfn.Event.
+type CloudFormation []NoResponse[cfn.Event]
+
+// CommonCloudFormation returns a slice of common middleware for handlers of cfn.Event
+func CommonCloudFormation(logger *slog.Logger) CloudFormation {
+	return Common[cfn.Event](logger)
+}
+
+// WebSocket is a slice of WithResponse middleware for handlers of events.APIGatewayWebsocketProxyRequest that return
+// events.APIGatewayProxyResponse.
+type WebSocket []WithResponse[events.APIGatewayWebsocketProxyRequest, events.APIGatewayProxyResponse]
+
+// CommonWebSocket returns a slice of common middleware for handlers of events.APIGatewayWebsocketProxyRequest that
+// return events.APIGatewayProxyResponse
+func CommonWebSocket(logger *slog.Logger) WebSocket {
+	return CommonWithResponse[events.APIGatewayWebsocketProxyRequest, events.APIGatewayProxyResponse](logger)
+}
+
+// TokenAuthorizer is a slice of WithResponse middleware for handlers of events.APIGatewayCustomAuthorizerRequest that
+// return events.APIGatewayCustomAuthorizerResponse.
+type TokenAuthorizer []WithResponse[events.APIGatewayCustomAuthorizerRequest, events.APIGatewayCustomAuthorizerResponse]
+
+// CommonTokenAuthorizer returns a slice of common middleware for handlers of events.APIGatewayCustomAuthorizerRequest
+// that return events.APIGatewayCustomAuthorizerResponse
+func CommonTokenAuthorizer(logger *slog.Logger) TokenAuthorizer {
+	return CommonWithResponse[events.APIGatewayCustomAuthorizerRequest, events.APIGatewayCustomAuthorizerResponse](logger)
+}
+
+// RequestAuthorizer is a slice of WithResponse middleware for handlers of
+// events.APIGatewayCustomAuthorizerRequestTypeRequest that return events.APIGatewayCustomAuthorizerResponse.
+type RequestAuthorizer []WithResponse[events.APIGatewayCustomAuthorizerRequestTypeRequest, events.APIGatewayCustomAuthorizerResponse]
+
+// CommonRequestAuthorizer returns a slice of common middleware for handlers of
+// events.APIGatewayCustomAuthorizerRequestTypeRequest that return events.APIGatewayCustomAuthorizerResponse
+func CommonRequestAuthorizer(logger *slog.Logger) RequestAuthorizer {
+	return CommonWithResponse[events.APIGatewayCustomAuthorizerRequestTypeRequest, events.APIGatewayCustomAuthorizerResponse](logger)
+}
+
+// HTTPAuthorizer is a slice of WithResponse middleware for handlers of events.APIGatewayV2CustomAuthorizerV2Request
+// that return events.APIGatewayV2CustomAuthorizerSimpleResponse.
+type HTTPAuthorizer []WithResponse[events.APIGatewayV2CustomAuthorizerV2Request, events.APIGatewayV2CustomAuthorizerSimpleResponse]
+
+// CommonHTTPAuthorizer returns a slice of common middleware for handlers of events.APIGatewayV2CustomAuthorizerV2Request
+// that return events.APIGatewayV2CustomAuthorizerSimpleResponse
+func CommonHTTPAuthorizer(logger *slog.Logger) HTTPAuthorizer {
+	return CommonWithResponse[events.APIGatewayV2CustomAuthorizerV2Request, events.APIGatewayV2CustomAuthorizerSimpleResponse](logger)
+}
+
 // SQS is a slice of NoResponse middleware for handlers of events.SQSEvent.
 type SQS []NoResponse[events.SQSEvent]
 
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"strconv"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/jwt"
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/ratelimit"
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/response"
+)
+
+const (
+	defaultRateLimitedMsg     = "Request rate limited"
+	defaultRateLimitFailedMsg = "Rate limit store failed"
+	rateLimitHeaderLimit      = "RateLimit-Limit"
+	rateLimitHeaderRemaining  = "RateLimit-Remaining"
+	rateLimitHeaderReset      = "RateLimit-Reset"
+	apiKeyHeader              = "x-api-key"
+)
+
+// ErrRateLimited is returned by the rate limit middleware for a request over the limit, for response types other than
+// API Gateway v1 and v2.
+var ErrRateLimited = errors.New("middleware: rate limit exceeded")
+
+// RateLimitKeyFunc returns the key a request is rate limited by, or an empty string for a request that should not be
+// rate limited.
+type RateLimitKeyFunc func(ctx context.Context, event any) string
+
+// RateLimitKeySourceIP rate limits API Gateway v1 and v2 requests by the source IP of the client.
+func RateLimitKeySourceIP(_ context.Context, event any) string {
+	switch e := event.(type) {
+	case events.APIGatewayProxyRequest:
+		return e.RequestContext.Identity.SourceIP
+	case events.APIGatewayV2HTTPRequest:
+		return e.RequestContext.HTTP.SourceIP
+	}
+	return ""
+}
+
+// RateLimitKeyAPIKey rate limits API Gateway v1 and v2 requests by their API key, from the request context of API
+// Gateway v1 requests or else the x-api-key header.
+func RateLimitKeyAPIKey(_ context.Context, event any) string {
+	if e, ok := event.(events.APIGatewayProxyRequest); ok && e.RequestContext.Identity.APIKey != "" {
+		return e.RequestContext.Identity.APIKey
+	}
+	return requestHeader(event, apiKeyHeader)
+}
+
+// RateLimitKeySubject rate limits requests by the subject of the claims added to the context by the auth middleware,
+// so it must come after the auth middleware in the chain.
+func RateLimitKeySubject(ctx context.Context, _ any) string {
+	claims, _ := jwt.FromContext(ctx)
+	return claims.Subject
+}
+
+type rateLimitOptions struct {
+	key        RateLimitKeyFunc
+	routeLimit map[string]ratelimit.Limit
+}
+
+// RateLimitOption configures NewRateLimitWithResponse.
+type RateLimitOption func(*rateLimitOptions)
+
+// WithRateLimitKey sets the key requests are rate limited by. Defaults to RateLimitKeySourceIP.
+func WithRateLimitKey(fn RateLimitKeyFunc) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		o.key = fn
+	}
+}
+
+// WithRateLimitRoute sets the limit for a single API Gateway route, with buckets separate from other routes. route is
+// matched against the RouteKey of API Gateway v2 requests and "<HTTP method> <resource>" (e.g. "GET /customers/{id}")
+// of API Gateway v1 requests. Call it once per route.
+func WithRateLimitRoute(route string, limit ratelimit.Limit) RateLimitOption {
+	return func(o *rateLimitOptions) {
+		limits := make(map[string]ratelimit.Limit, len(o.routeLimit)+1)
+		for k, v := range o.routeLimit {
+			limits[k] = v
+		}
+		limits[route] = limit
+		o.routeLimit = limits
+	}
+}
+
+type rateLimitWithResponse[E, R any] struct {
+	logger *slog.Logger
+	store  ratelimit.Store
+	limit  ratelimit.Limit
+	opts   rateLimitOptions
+}
+
+// NewRateLimitWithResponse returns an implementation of WithResponse for the rate limit middleware.
+//
+// The rate limit middleware takes a token from the bucket of the key of each request (see WithRateLimitKey) in store,
+// according to limit, or the limit of its route (see WithRateLimitRoute). Requests without a key are not limited.
+//
+// A request over the limit gets a response.ErrorCodeRateLimited (429) response with a Retry-After header, without
+// calling the handler, and RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers are added to every API
+// Gateway v1 and v2 response. For other response types ErrRateLimited is returned instead. If store fails the error is
+// logged and the request is allowed.
+func NewRateLimitWithResponse[E, R any](logger *slog.Logger, store ratelimit.Store, limit ratelimit.Limit, options ...RateLimitOption) WithResponse[E, R] {
+	opts := rateLimitOptions{key: RateLimitKeySourceIP}
+	for _, option := range options {
+		option(&opts)
+	}
+	return &rateLimitWithResponse[E, R]{
+		logger: logger,
+		store:  store,
+		limit:  limit,
+		opts:   opts,
+	}
+}
+
+func (l rateLimitWithResponse[E, R]) Wrap(next func(context.Context, E) (R, error)) func(context.Context, E) (R, error) {
+	return func(ctx context.Context, event E) (R, error) {
+		key := l.opts.key(ctx, event)
+		if key == "" {
+			return next(ctx, event)
+		}
+
+		limit := l.limit
+		if route := routeKey(event); route != "" {
+			if routeLimit, ok := l.opts.routeLimit[route]; ok {
+				limit, key = routeLimit, route+" "+key
+			}
+		}
+
+		result, err := l.store.Take(ctx, key, limit)
+		if err != nil {
+			l.logger.LogAttrs(ctx, slog.LevelError, defaultRateLimitFailedMsg, errorAttr(err))
+			return next(ctx, event)
+		}
+
+		if !result.Allowed {
+			l.logger.LogAttrs(ctx, slog.LevelInfo, defaultRateLimitedMsg, slog.Duration("retry_after", result.RetryAfter))
+			res, ok := errorCodeResponse[R](response.ErrorCodeRateLimited, response.WithRetryAfter(result.RetryAfter))
+			if !ok {
+				return res, ErrRateLimited
+			}
+			return withResponseHeaders(res, rateLimitHeaders(result)), nil
+		}
+
+		res, err := next(ctx, event)
+		return withResponseHeaders(res, rateLimitHeaders(result)), err
+	}
+}
+
+func rateLimitHeaders(result ratelimit.Result) map[string]string {
+	return map[string]string{
+		rateLimitHeaderLimit:     strconv.Itoa(result.Limit),
+		rateLimitHeaderRemaining: strconv.Itoa(result.Remaining),
+		rateLimitHeaderReset:     strconv.FormatInt(int64(math.Ceil(result.Reset.Seconds())), 10),
+	}
+}
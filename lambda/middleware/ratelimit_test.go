@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/jwt"
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/ratelimit"
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/response"
+)
+
+// fakeRateLimitStore returns result, or err, recording the keys and limits taken.
+type fakeRateLimitStore struct {
+	result ratelimit.Result
+	err    error
+	keys   []string
+	limits []ratelimit.Limit
+}
+
+func (s *fakeRateLimitStore) Take(_ context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	s.keys = append(s.keys, key)
+	s.limits = append(s.limits, limit)
+	return s.result, s.err
+}
+
+func Test_rateLimitWithResponse_Wrap(t *testing.T) {
+	allowed := ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, Reset: 5500 * time.Millisecond}
+	limited := ratelimit.Result{Limit: 10, Reset: time.Minute, RetryAfter: 5500 * time.Millisecond}
+	event := events.APIGatewayProxyRequest{
+		Resource: "/orders",
+		Headers:  map[string]string{"X-Api-Key": "header-key"},
+		RequestContext: events.APIGatewayProxyRequestContext{
+			HTTPMethod: http.MethodGet,
+			Identity:   events.APIGatewayRequestIdentity{SourceIP: "203.0.113.1"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		store       *fakeRateLimitStore
+		options     []RateLimitOption
+		wantKeys    []string
+		wantLimits  []ratelimit.Limit
+		wantCalled  bool
+		wantStatus  int
+		wantHeaders map[string]string
+	}{
+		{
+			name:        "within limit by source ip, handler called with rate limit headers",
+			ctx:         context.Background(),
+			store:       &fakeRateLimitStore{result: allowed},
+			wantKeys:    []string{"203.0.113.1"},
+			wantLimits:  []ratelimit.Limit{ratelimit.PerMinute(10)},
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"X-Handler": "true", "RateLimit-Limit": "10", "RateLimit-Remaining": "9", "RateLimit-Reset": "6"},
+		},
+		{
+			name:       "over limit, rate limited response with retry after",
+			ctx:        context.Background(),
+			store:      &fakeRateLimitStore{result: limited},
+			wantKeys:   []string{"203.0.113.1"},
+			wantLimits: []ratelimit.Limit{ratelimit.PerMinute(10)},
+			wantStatus: http.StatusTooManyRequests,
+			wantHeaders: map[string]string{
+				"Content-Type": "application/json", "Retry-After": "6",
+				"RateLimit-Limit": "10", "RateLimit-Remaining": "0", "RateLimit-Reset": "60",
+			},
+		},
+		{
+			name:        "by api key header, handler called",
+			ctx:         context.Background(),
+			store:       &fakeRateLimitStore{result: allowed},
+			options:     []RateLimitOption{WithRateLimitKey(RateLimitKeyAPIKey)},
+			wantKeys:    []string{"header-key"},
+			wantLimits:  []ratelimit.Limit{ratelimit.PerMinute(10)},
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"X-Handler": "true", "RateLimit-Limit": "10", "RateLimit-Remaining": "9", "RateLimit-Reset": "6"},
+		},
+		{
+			name:        "by subject with route limit, route limit and key used",
+			ctx:         jwt.NewContext(context.Background(), jwt.Claims{Subject: "user-123"}),
+			store:       &fakeRateLimitStore{result: allowed},
+			options:     []RateLimitOption{WithRateLimitKey(RateLimitKeySubject), WithRateLimitRoute("GET /orders", ratelimit.PerSecond(1))},
+			wantKeys:    []string{"GET /orders user-123"},
+			wantLimits:  []ratelimit.Limit{ratelimit.PerSecond(1)},
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"X-Handler": "true", "RateLimit-Limit": "10", "RateLimit-Remaining": "9", "RateLimit-Reset": "6"},
+		},
+		{
+			name:        "no key, not limited",
+			ctx:         context.Background(),
+			store:       &fakeRateLimitStore{result: limited},
+			options:     []RateLimitOption{WithRateLimitKey(RateLimitKeySubject)},
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"X-Handler": "true"},
+		},
+		{
+			name:        "store error, not limited",
+			ctx:         context.Background(),
+			store:       &fakeRateLimitStore{err: errors.New("store unavailable")},
+			wantKeys:    []string{"203.0.113.1"},
+			wantLimits:  []ratelimit.Limit{ratelimit.PerMinute(10)},
+			wantCalled:  true,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"X-Handler": "true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				called = true
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Headers: map[string]string{"X-Handler": "true"}}, nil
+			}
+
+			m := NewRateLimitWithResponse[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](
+				slog.New(slog.DiscardHandler), tt.store, ratelimit.PerMinute(10), tt.options...)
+			got, err := m.Wrap(handler)(tt.ctx, event)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCalled, called)
+			assert.Equal(t, tt.wantStatus, got.StatusCode)
+			assert.Equal(t, tt.wantHeaders, got.Headers)
+			assert.Equal(t, tt.wantKeys, tt.store.keys)
+			assert.Equal(t, tt.wantLimits, tt.store.limits)
+		})
+	}
+}
+
+func Test_rateLimitWithResponse_Wrap_v2(t *testing.T) {
+	store := &fakeRateLimitStore{result: ratelimit.Result{Limit: 1, Reset: time.Second, RetryAfter: time.Second}}
+	m := NewRateLimitWithResponse[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse](
+		slog.New(slog.DiscardHandler), store, ratelimit.PerSecond(1))
+
+	got, err := m.Wrap(func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusOK}, nil
+	})(context.Background(), events.APIGatewayV2HTTPRequest{
+		RequestContext: events.APIGatewayV2HTTPRequestContext{HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{SourceIP: "203.0.113.1"}},
+	})
+
+	assert.NoError(t, err)
+	want := response.NewErrorCode(response.ErrorCodeRateLimited)
+	assert.Equal(t, events.APIGatewayV2HTTPResponse{
+		StatusCode: want.StatusCode,
+		Body:       want.Body,
+		Headers: map[string]string{
+			"Content-Type": "application/json", "Retry-After": "1",
+			"RateLimit-Limit": "1", "RateLimit-Remaining": "0", "RateLimit-Reset": "1",
+		},
+	}, got)
+	assert.Equal(t, []string{"203.0.113.1"}, store.keys)
+}
+
+func Test_rateLimitWithResponse_Wrap_otherResponse(t *testing.T) {
+	store := &fakeRateLimitStore{result: ratelimit.Result{Limit: 1, RetryAfter: time.Second}}
+	m := NewRateLimitWithResponse[events.APIGatewayProxyRequest, string](
+		slog.New(slog.DiscardHandler), store, ratelimit.PerSecond(1))
+
+	_, err := m.Wrap(func(context.Context, events.APIGatewayProxyRequest) (string, error) {
+		return "ok", nil
+	})(context.Background(), events.APIGatewayProxyRequest{
+		RequestContext: events.APIGatewayProxyRequestContext{Identity: events.APIGatewayRequestIdentity{SourceIP: "203.0.113.1"}},
+	})
+
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
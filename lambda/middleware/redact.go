@@ -3,7 +3,10 @@ package middleware
 import (
 	"strings"
 
+	"github.com/aws/aws-lambda-go/cfn"
 	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ellogroup/ello-golang-aws/v2/internal/cognitoredact"
 )
 
 const redactedValue = "[REDACTED]"
@@ -11,11 +14,21 @@ const redactedValue = "[REDACTED]"
 var sensitiveHeaders = map[string]struct{}{
 	"authorization": {},
 	"cookie":        {},
+	"set-cookie":    {},
 	"x-api-key":     {},
 }
 
+// Redactable interface can be implemented by event types to redact their own sensitive data before they are logged,
+// e.g. cognito.Event redacts the personal data of Cognito user pool trigger events.
+type Redactable interface {
+	// Redact returns a redacted copy of the event.
+	Redact() any
+}
+
 type redactOptions struct {
 	bodyNotRedacted bool
+	// headers are the lower-cased names of headers redacted in addition to sensitiveHeaders.
+	headers map[string]struct{}
 }
 
 // RedactOption configures a Redactor.
@@ -30,8 +43,21 @@ func WithBodyNotRedacted() RedactOption {
 	}
 }
 
-// Redactor redacts sensitive headers and (by default) the request body from known HTTP Lambda
-// event types. Construct one with NewRedactor when you need non-default options - options are
+// WithRedactedHeaders redacts the headers names too, in addition to Authorization, Cookie, Set-Cookie and X-Api-Key,
+// e.g. the custom headers an API Gateway authorizer uses as identity sources. Names are not case-sensitive.
+func WithRedactedHeaders(names ...string) RedactOption {
+	return func(o *redactOptions) {
+		if o.headers == nil {
+			o.headers = make(map[string]struct{}, len(names))
+		}
+		for _, name := range names {
+			o.headers[strings.ToLower(name)] = struct{}{}
+		}
+	}
+}
+
+// Redactor redacts sensitive headers and (by default) the body from known HTTP Lambda event and
+// response types. Construct one with NewRedactor when you need non-default options - options are
 // applied once at construction, not re-processed on every call to Sanitize. Implements Sanitizer,
 // so a *Redactor can be passed directly to WithEventLoggerSanitizer.
 type Redactor struct {
@@ -47,40 +73,107 @@ func NewRedactor(opts ...RedactOption) *Redactor {
 	return &Redactor{opts: o}
 }
 
-// Sanitize returns a sanitized copy of known HTTP Lambda event types with sensitive headers
-// (Authorization, Cookie, X-Api-Key) and (unless configured otherwise) the request Body replaced
-// with [REDACTED]. Non-HTTP event types are returned unchanged.
+// Sanitize returns a sanitized copy of known HTTP Lambda event and response types with sensitive headers
+// (Authorization, Cookie, Set-Cookie, X-Api-Key and any configured with WithRedactedHeaders), cookies and (unless
+// configured otherwise) the Body replaced with [REDACTED]. The authorization token, identity sources and query string
+// parameters of API Gateway authorizer events are redacted too, and the pre-signed ResponseURL of CloudFormation custom
+// resource events is also redacted, as it allows anyone to respond to the request. The personal data of Cognito user
+// pool trigger events (events.CognitoEventUserPools*) is redacted as by cognito.Redact, and events implementing
+// Redactable, such as cognito.Event, are redacted by their Redact method. Other types are returned unchanged.
 func (r *Redactor) Sanitize(event any) any {
 	switch e := event.(type) {
 	case events.APIGatewayProxyRequest:
-		e.Headers = redactHeaders(e.Headers)
-		e.MultiValueHeaders = redactMultiValueHeaders(e.MultiValueHeaders)
+		e.Headers = redactHeaders(e.Headers, r.opts)
+		e.MultiValueHeaders = redactMultiValueHeaders(e.MultiValueHeaders, r.opts)
 		e.Body = redactBody(e.Body, r.opts)
 		return e
 	case events.APIGatewayV2HTTPRequest:
-		e.Headers = redactHeaders(e.Headers)
+		e.Headers = redactHeaders(e.Headers, r.opts)
 		if len(e.Cookies) > 0 {
 			e.Cookies = []string{redactedValue}
 		}
 		e.Body = redactBody(e.Body, r.opts)
 		return e
 	case events.ALBTargetGroupRequest:
-		e.Headers = redactHeaders(e.Headers)
-		e.MultiValueHeaders = redactMultiValueHeaders(e.MultiValueHeaders)
+		e.Headers = redactHeaders(e.Headers, r.opts)
+		e.MultiValueHeaders = redactMultiValueHeaders(e.MultiValueHeaders, r.opts)
 		e.Body = redactBody(e.Body, r.opts)
 		return e
 	case events.LambdaFunctionURLRequest:
-		e.Headers = redactHeaders(e.Headers)
+		e.Headers = redactHeaders(e.Headers, r.opts)
 		if len(e.Cookies) > 0 {
 			e.Cookies = []string{redactedValue}
 		}
 		e.Body = redactBody(e.Body, r.opts)
 		return e
 	case events.APIGatewayWebsocketProxyRequest:
-		e.Headers = redactHeaders(e.Headers)
-		e.MultiValueHeaders = redactMultiValueHeaders(e.MultiValueHeaders)
+		e.Headers = redactHeaders(e.Headers, r.opts)
+		e.MultiValueHeaders = redactMultiValueHeaders(e.MultiValueHeaders, r.opts)
+		e.Body = redactBody(e.Body, r.opts)
+		return e
+	case events.APIGatewayProxyResponse:
+		e.Headers = redactHeaders(e.Headers, r.opts)
+		e.MultiValueHeaders = redactMultiValueHeaders(e.MultiValueHeaders, r.opts)
+		e.Body = redactBody(e.Body, r.opts)
+		return e
+	case events.APIGatewayV2HTTPResponse:
+		e.Headers = redactHeaders(e.Headers, r.opts)
+		e.MultiValueHeaders = redactMultiValueHeaders(e.MultiValueHeaders, r.opts)
+		if len(e.Cookies) > 0 {
+			e.Cookies = []string{redactedValue}
+		}
 		e.Body = redactBody(e.Body, r.opts)
 		return e
+	case events.ALBTargetGroupResponse:
+		e.Headers = redactHeaders(e.Headers, r.opts)
+		e.MultiValueHeaders = redactMultiValueHeaders(e.MultiValueHeaders, r.opts)
+		e.Body = redactBody(e.Body, r.opts)
+		return e
+	case events.LambdaFunctionURLResponse:
+		e.Headers = redactHeaders(e.Headers, r.opts)
+		if len(e.Cookies) > 0 {
+			e.Cookies = []string{redactedValue}
+		}
+		e.Body = redactBody(e.Body, r.opts)
+		return e
+	case events.APIGatewayCustomAuthorizerRequest:
+		e.AuthorizationToken = redactToken(e.AuthorizationToken)
+		return e
+	case events.APIGatewayCustomAuthorizerRequestTypeRequest:
+		e.Headers = redactHeaders(e.Headers, r.opts)
+		e.MultiValueHeaders = redactMultiValueHeaders(e.MultiValueHeaders, r.opts)
+		e.QueryStringParameters = redactQuery(e.QueryStringParameters)
+		e.MultiValueQueryStringParameters = redactMultiValueQuery(e.MultiValueQueryStringParameters)
+		return e
+	case events.APIGatewayV2CustomAuthorizerV1Request:
+		e.AuthorizationToken = redactToken(e.AuthorizationToken)
+		e.IdentitySource = redactToken(e.IdentitySource)
+		e.Headers = redactHeaders(e.Headers, r.opts)
+		e.QueryStringParameters = redactQuery(e.QueryStringParameters)
+		return e
+	case events.APIGatewayV2CustomAuthorizerV2Request:
+		if len(e.IdentitySource) > 0 {
+			e.IdentitySource = []string{redactedValue}
+		}
+		e.Headers = redactHeaders(e.Headers, r.opts)
+		e.RawQueryString = redactToken(e.RawQueryString)
+		e.QueryStringParameters = redactQuery(e.QueryStringParameters)
+		if len(e.Cookies) > 0 {
+			e.Cookies = []string{redactedValue}
+		}
+		return e
+	case cfn.Event:
+		e.ResponseURL = redactedValue
+		return e
+	case events.CognitoEventUserPoolsPreSignup, events.CognitoEventUserPoolsPostConfirmation,
+		events.CognitoEventUserPoolsPreAuthentication, events.CognitoEventUserPoolsPostAuthentication,
+		events.CognitoEventUserPoolsPreTokenGen, events.CognitoEventUserPoolsPreTokenGenV2_0,
+		events.CognitoEventUserPoolsCustomMessage, events.CognitoEventUserPoolsDefineAuthChallenge,
+		events.CognitoEventUserPoolsCreateAuthChallenge, events.CognitoEventUserPoolsVerifyAuthChallenge,
+		events.CognitoEventUserPoolsMigrateUser:
+		return cognitoredact.Redact(e)
+	case Redactable:
+		return e.Redact()
 	}
 	return event
 }
@@ -89,10 +182,12 @@ func (r *Redactor) Sanitize(event any) any {
 // RedactHTTPEvent so the common case doesn't allocate a new Redactor per call.
 var defaultRedactor = NewRedactor()
 
-// RedactHTTPEvent returns a sanitized copy of known HTTP Lambda event types with sensitive
-// headers (Authorization, Cookie, X-Api-Key) and the request Body replaced with [REDACTED].
-// Non-HTTP event types are returned unchanged. It can be called inside a
-// WithEventLoggerSanitizer function to compose built-in redaction with custom logic.
+// RedactHTTPEvent returns a sanitized copy of known HTTP Lambda event and response types with sensitive headers
+// (Authorization, Cookie, Set-Cookie, X-Api-Key), cookies and the Body replaced with [REDACTED], as well as the
+// authorization token, identity sources and query string parameters of API Gateway authorizer events, the ResponseURL
+// of CloudFormation custom resource events and the personal data of Cognito user pool trigger events and events
+// implementing Redactable. Other types are returned unchanged. It can be called inside a WithEventLoggerSanitizer
+// function to compose built-in redaction with custom logic.
 //
 // This always applies default options. For non-default behaviour (e.g. WithBodyNotRedacted),
 // construct a Redactor with NewRedactor instead - options are applied once at construction,
@@ -108,13 +203,20 @@ func redactBody(body string, o redactOptions) string {
 	return redactedValue
 }
 
-func redactHeaders(headers map[string]string) map[string]string {
+func redactToken(token string) string {
+	if token == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+func redactHeaders(headers map[string]string, o redactOptions) map[string]string {
 	if headers == nil {
 		return nil
 	}
 	out := make(map[string]string, len(headers))
 	for k, v := range headers {
-		if _, sensitive := sensitiveHeaders[strings.ToLower(k)]; sensitive {
+		if isSensitiveHeader(k, o) {
 			out[k] = redactedValue
 		} else {
 			out[k] = v
@@ -123,13 +225,13 @@ func redactHeaders(headers map[string]string) map[string]string {
 	return out
 }
 
-func redactMultiValueHeaders(headers map[string][]string) map[string][]string {
+func redactMultiValueHeaders(headers map[string][]string, o redactOptions) map[string][]string {
 	if headers == nil {
 		return nil
 	}
 	out := make(map[string][]string, len(headers))
 	for k, v := range headers {
-		if _, sensitive := sensitiveHeaders[strings.ToLower(k)]; sensitive {
+		if isSensitiveHeader(k, o) {
 			out[k] = []string{redactedValue}
 		} else {
 			out[k] = v
@@ -137,3 +239,36 @@ func redactMultiValueHeaders(headers map[string][]string) map[string][]string {
 	}
 	return out
 }
+
+func isSensitiveHeader(name string, o redactOptions) bool {
+	name = strings.ToLower(name)
+	if _, sensitive := sensitiveHeaders[name]; sensitive {
+		return true
+	}
+	_, sensitive := o.headers[name]
+	return sensitive
+}
+
+// redactQuery redacts every value of the query string parameters of an authorizer event, any of which can be an
+// identity source.
+func redactQuery(query map[string]string) map[string]string {
+	if query == nil {
+		return nil
+	}
+	out := make(map[string]string, len(query))
+	for k := range query {
+		out[k] = redactedValue
+	}
+	return out
+}
+
+func redactMultiValueQuery(query map[string][]string) map[string][]string {
+	if query == nil {
+		return nil
+	}
+	out := make(map[string][]string, len(query))
+	for k := range query {
+		out[k] = []string{redactedValue}
+	}
+	return out
+}
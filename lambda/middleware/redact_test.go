@@ -3,10 +3,20 @@ package middleware
 import (
 	"testing"
 
+	"github.com/aws/aws-lambda-go/cfn"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/stretchr/testify/assert"
 )
 
+type redactableEvent struct {
+	secret string
+}
+
+func (e redactableEvent) Redact() any {
+	e.secret = redactedValue
+	return e
+}
+
 func TestRedactHTTPEvent(t *testing.T) {
 	type testCase struct {
 		name  string
@@ -180,6 +190,169 @@ func TestRedactHTTPEvent(t *testing.T) {
 			event: events.SQSEvent{Records: []events.SQSMessage{{MessageId: "123"}}},
 			want:  events.SQSEvent{Records: []events.SQSMessage{{MessageId: "123"}}},
 		},
+		{
+			name: "APIGatewayProxyResponse, set-cookie header and body redacted",
+			event: events.APIGatewayProxyResponse{
+				StatusCode:        200,
+				Headers:           map[string]string{"Set-Cookie": "session=abc", "Content-Type": "application/json"},
+				MultiValueHeaders: map[string][]string{"Set-Cookie": {"session=abc", "other=xyz"}},
+				Body:              `{"email":"jane@example.com"}`,
+			},
+			want: events.APIGatewayProxyResponse{
+				StatusCode:        200,
+				Headers:           map[string]string{"Set-Cookie": redactedValue, "Content-Type": "application/json"},
+				MultiValueHeaders: map[string][]string{"Set-Cookie": {redactedValue}},
+				Body:              redactedValue,
+			},
+		},
+		{
+			name: "APIGatewayV2HTTPResponse, set-cookie header, cookies, and body redacted",
+			event: events.APIGatewayV2HTTPResponse{
+				StatusCode: 200,
+				Headers:    map[string]string{"Set-Cookie": "session=abc"},
+				Cookies:    []string{"session=abc"},
+				Body:       `{"email":"jane@example.com"}`,
+			},
+			want: events.APIGatewayV2HTTPResponse{
+				StatusCode: 200,
+				Headers:    map[string]string{"Set-Cookie": redactedValue},
+				Cookies:    []string{redactedValue},
+				Body:       redactedValue,
+			},
+		},
+		{
+			name: "ALBTargetGroupResponse, set-cookie header and body redacted",
+			event: events.ALBTargetGroupResponse{
+				StatusCode: 200,
+				Headers:    map[string]string{"Set-Cookie": "session=abc"},
+				Body:       `{"email":"jane@example.com"}`,
+			},
+			want: events.ALBTargetGroupResponse{
+				StatusCode: 200,
+				Headers:    map[string]string{"Set-Cookie": redactedValue},
+				Body:       redactedValue,
+			},
+		},
+		{
+			name: "LambdaFunctionURLResponse, cookies and body redacted",
+			event: events.LambdaFunctionURLResponse{
+				StatusCode: 200,
+				Cookies:    []string{"session=abc"},
+				Body:       `{"email":"jane@example.com"}`,
+			},
+			want: events.LambdaFunctionURLResponse{
+				StatusCode: 200,
+				Cookies:    []string{redactedValue},
+				Body:       redactedValue,
+			},
+		},
+		{
+			name: "APIGatewayCustomAuthorizerRequest, authorization token redacted",
+			event: events.APIGatewayCustomAuthorizerRequest{
+				Type:               "TOKEN",
+				AuthorizationToken: "Bearer secret",
+				MethodArn:          "arn:aws:execute-api:eu-west-2:123456789012:abc123/prod/GET/customers",
+			},
+			want: events.APIGatewayCustomAuthorizerRequest{
+				Type:               "TOKEN",
+				AuthorizationToken: redactedValue,
+				MethodArn:          "arn:aws:execute-api:eu-west-2:123456789012:abc123/prod/GET/customers",
+			},
+		},
+		{
+			name: "APIGatewayCustomAuthorizerRequestTypeRequest, sensitive headers and query string redacted",
+			event: events.APIGatewayCustomAuthorizerRequestTypeRequest{
+				Type:                            "REQUEST",
+				Headers:                         map[string]string{"Authorization": "Bearer secret", "Accept": "*/*"},
+				QueryStringParameters:           map[string]string{"token": "secret"},
+				MultiValueQueryStringParameters: map[string][]string{"token": {"secret"}},
+			},
+			want: events.APIGatewayCustomAuthorizerRequestTypeRequest{
+				Type:                            "REQUEST",
+				Headers:                         map[string]string{"Authorization": redactedValue, "Accept": "*/*"},
+				QueryStringParameters:           map[string]string{"token": redactedValue},
+				MultiValueQueryStringParameters: map[string][]string{"token": {redactedValue}},
+			},
+		},
+		{
+			name: "APIGatewayV2CustomAuthorizerV1Request, token, identity source, headers and query string redacted",
+			event: events.APIGatewayV2CustomAuthorizerV1Request{
+				AuthorizationToken:    "Bearer secret",
+				IdentitySource:        "Bearer secret",
+				Headers:               map[string]string{"authorization": "Bearer secret"},
+				QueryStringParameters: map[string]string{"token": "secret"},
+			},
+			want: events.APIGatewayV2CustomAuthorizerV1Request{
+				AuthorizationToken:    redactedValue,
+				IdentitySource:        redactedValue,
+				Headers:               map[string]string{"authorization": redactedValue},
+				QueryStringParameters: map[string]string{"token": redactedValue},
+			},
+		},
+		{
+			name: "APIGatewayV2CustomAuthorizerV2Request, identity source, headers, cookies and query string redacted",
+			event: events.APIGatewayV2CustomAuthorizerV2Request{
+				IdentitySource:        []string{"Bearer secret"},
+				Headers:               map[string]string{"authorization": "Bearer secret"},
+				Cookies:               []string{"session=abc"},
+				RawQueryString:        "token=secret",
+				QueryStringParameters: map[string]string{"token": "secret"},
+			},
+			want: events.APIGatewayV2CustomAuthorizerV2Request{
+				IdentitySource:        []string{redactedValue},
+				Headers:               map[string]string{"authorization": redactedValue},
+				Cookies:               []string{redactedValue},
+				RawQueryString:        redactedValue,
+				QueryStringParameters: map[string]string{"token": redactedValue},
+			},
+		},
+		{
+			name: "cfn.Event, response url redacted",
+			event: cfn.Event{
+				RequestType: cfn.RequestCreate,
+				ResponseURL: "https://cloudformation-custom-resource-response.s3.amazonaws.com/?X-Amz-Signature=abc",
+			},
+			want: cfn.Event{
+				RequestType: cfn.RequestCreate,
+				ResponseURL: redactedValue,
+			},
+		},
+		{
+			name: "CognitoEventUserPoolsMigrateUser, username, user attributes and password redacted",
+			event: events.CognitoEventUserPoolsMigrateUser{
+				CognitoEventUserPoolsHeader: events.CognitoEventUserPoolsHeader{
+					TriggerSource: "UserMigration_Authentication",
+					UserName:      "jane@example.com",
+				},
+				CognitoEventUserPoolsMigrateUserRequest: events.CognitoEventUserPoolsMigrateUserRequest{
+					Password: "hunter2",
+				},
+				CognitoEventUserPoolsMigrateUserResponse: events.CognitoEventUserPoolsMigrateUserResponse{
+					UserAttributes: map[string]string{"email": "jane@example.com", "email_verified": "true"},
+				},
+			},
+			want: map[string]any{
+				"version":       "",
+				"triggerSource": "UserMigration_Authentication",
+				"region":        "",
+				"userPoolId":    "",
+				"callerContext": map[string]any{"awsSdkVersion": "", "clientId": ""},
+				"userName":      redactedValue,
+				"request":       map[string]any{"password": redactedValue, "validationData": nil, "clientMetadata": nil},
+				"response": map[string]any{
+					"userAttributes":         map[string]any{"email": redactedValue, "email_verified": "true"},
+					"finalUserStatus":        "",
+					"messageAction":          "",
+					"desiredDeliveryMediums": nil,
+					"forceAliasCreation":     false,
+				},
+			},
+		},
+		{
+			name:  "Redactable, redacted by its Redact method",
+			event: redactableEvent{secret: "hunter2"},
+			want:  redactableEvent{secret: redactedValue},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -216,6 +389,18 @@ func TestNewRedactor_Sanitize(t *testing.T) {
 		assert.Equal(t, want, NewRedactor(WithBodyNotRedacted()).Sanitize(event))
 	})
 
+	t.Run("WithRedactedHeaders redacts identity source headers of authorizer events", func(t *testing.T) {
+		authorizerEvent := events.APIGatewayCustomAuthorizerRequestTypeRequest{
+			Headers:           map[string]string{"X-Tenant-Token": "secret", "Accept": "*/*"},
+			MultiValueHeaders: map[string][]string{"X-Tenant-Token": {"secret"}},
+		}
+		want := events.APIGatewayCustomAuthorizerRequestTypeRequest{
+			Headers:           map[string]string{"X-Tenant-Token": redactedValue, "Accept": "*/*"},
+			MultiValueHeaders: map[string][]string{"X-Tenant-Token": {redactedValue}},
+		}
+		assert.Equal(t, want, NewRedactor(WithRedactedHeaders("x-tenant-token")).Sanitize(authorizerEvent))
+	})
+
 	t.Run("options are applied once at construction, not per Sanitize call", func(t *testing.T) {
 		redactor := NewRedactor(WithBodyNotRedacted())
 		for range 3 {
@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+
+	"github.com/ellogroup/ello-golang-aws/v2/internal/invocation"
+)
+
+// WithEventLoggerSampleRate logs only a fraction of events, where rate is between 0 (none) and 1
+// (all, the default). The sampling decision is made once per invocation, so the event-started and
+// event-completed records of an event are either both logged or both dropped.
+func WithEventLoggerSampleRate(rate float64) EventLoggerOption {
+	return func(opts *eventLoggerOptions) {
+		opts.sampleRate = &rate
+	}
+}
+
+// WithEventLoggerRouteSampleRate sets the sample rate for a single API Gateway route, overriding
+// WithEventLoggerSampleRate. route is matched against the RouteKey of API Gateway v2 requests and
+// "<HTTP method> <resource>" (e.g. "GET /customers/{id}") of API Gateway v1 requests. Call it once
+// per route.
+func WithEventLoggerRouteSampleRate(route string, rate float64) EventLoggerOption {
+	return func(opts *eventLoggerOptions) {
+		rates := make(map[string]float64, len(opts.routeSampleRates)+1)
+		for k, v := range opts.routeSampleRates {
+			rates[k] = v
+		}
+		rates[route] = rate
+		opts.routeSampleRates = rates
+	}
+}
+
+// WithEventLoggerAlwaysLogOnError logs events that were not sampled when they fail. The
+// event-started record is buffered and only emitted, along with the event-completed record, if the
+// handler returns an error or an HTTP response with a 5xx status code.
+func WithEventLoggerAlwaysLogOnError() EventLoggerOption {
+	return func(opts *eventLoggerOptions) {
+		opts.alwaysLogOnError = true
+	}
+}
+
+// WithEventLoggerDebugSampleRate switches the logger to slog.LevelDebug for a fraction of
+// invocations, where rate is between 0 (none, the default) and 1 (all). Debug sampled invocations are
+// always logged, their event-completed record contains "debug_sampled", and the debug logger is
+// available to the rest of the chain through LoggerFromContext.
+func WithEventLoggerDebugSampleRate(rate float64) EventLoggerOption {
+	return func(opts *eventLoggerOptions) {
+		opts.debugSampleRate = rate
+	}
+}
+
+// WithEventLoggerLevelFromEnv sets the minimum level of the logger from the environment variable
+// name (e.g. "LOG_LEVEL"), parsed with slog.Level.UnmarshalText ("DEBUG", "info", "WARN+2"...).
+// The variable is read when the middleware is constructed, so a change is picked up by the next
+// cold start without a redeploy. An unset or invalid value leaves the logger unchanged.
+func WithEventLoggerLevelFromEnv(name string) EventLoggerOption {
+	return func(opts *eventLoggerOptions) {
+		opts.levelEnv = name
+	}
+}
+
+type loggerContextKey struct{}
+
+// LoggerFromContext returns the logger the event logger middleware switched to for a debug sampled
+// invocation (see WithEventLoggerDebugSampleRate), or fallback when the invocation was not debug
+// sampled.
+func LoggerFromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return fallback
+}
+
+// levelHandler overrides the minimum level of the wrapped handler, including lowering it.
+type levelHandler struct {
+	handler slog.Handler
+	level   slog.Leveler
+}
+
+func (h *levelHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.handler.Handle(ctx, r)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{handler: h.handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{handler: h.handler.WithGroup(name), level: h.level}
+}
+
+func loggerWithLevelFromEnv(logger *slog.Logger, name string) *slog.Logger {
+	if name == "" {
+		return logger
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(os.Getenv(name))); err != nil {
+		return logger
+	}
+	return slog.New(&levelHandler{handler: logger.Handler(), level: level})
+}
+
+// loggedEvent holds the logging state of a single event between its start and completion.
+type loggedEvent struct {
+	clock   clock.Clock
+	logger  *slog.Logger
+	opts    *eventLoggerOptions
+	start   time.Time
+	sampled bool
+	debug   bool
+	started *slog.Record
+}
+
+// startLoggedEvent makes the sampling decisions for an event and logs (or buffers) the
+// event-started record. random returns a number in [0, 1) and defaults to math/rand/v2.
+func startLoggedEvent(ctx context.Context, clk clock.Clock, logger *slog.Logger, opts *eventLoggerOptions, random func() float64, event any) (context.Context, *loggedEvent) {
+	if random == nil {
+		random = rand.Float64 // #nosec G404 -- log sampling is not security sensitive
+	}
+
+	inv := &loggedEvent{
+		clock:   clk,
+		logger:  logger,
+		opts:    opts,
+		start:   clk.Now(),
+		sampled: true,
+	}
+	if opts.debugSampleRate > 0 && random() < opts.debugSampleRate {
+		inv.debug = true
+		inv.logger = slog.New(&levelHandler{handler: logger.Handler(), level: slog.LevelDebug})
+		ctx = context.WithValue(ctx, loggerContextKey{}, inv.logger)
+	} else if rate := sampleRate(opts, event); rate < 1 {
+		inv.sampled = random() < rate
+	}
+
+	switch {
+	case inv.sampled:
+		inv.logger.LogAttrs(ctx, opts.eventStartedLevel, opts.eventStartedMsg, slog.Any("event", sanitizeEvent(opts, event)))
+	case opts.alwaysLogOnError:
+		r := slog.NewRecord(inv.start, opts.eventStartedLevel, opts.eventStartedMsg, 0)
+		r.AddAttrs(slog.Any("event", sanitizeEvent(opts, event)))
+		inv.started = &r
+	}
+
+	return ctx, inv
+}
+
+// completing reports whether the event-completed record should be logged for an event with the
+// given outcome, emitting the buffered event-started record first if the event was not sampled
+// but failed. statusCode is 0 for non-HTTP responses.
+func (e *loggedEvent) completing(ctx context.Context, statusCode int, err error) bool {
+	if e.sampled {
+		return true
+	}
+	if e.started == nil || (err == nil && statusCode < 500) {
+		return false
+	}
+	if h := e.logger.Handler(); h.Enabled(ctx, e.started.Level) {
+		_ = h.Handle(ctx, *e.started)
+	}
+	return true
+}
+
+// complete logs the event-completed record, adding details of the invocation for handlers started with
+// lambda.Start/lambda.StartWithResponse.
+func (e *loggedEvent) complete(ctx context.Context, level slog.Level, attr ...slog.Attr) {
+	if info, ok := invocation.FromContext(ctx); ok {
+		attr = append(attr,
+			slog.Bool("cold_start", info.ColdStart),
+			slog.String("function_version", info.FunctionVersion),
+			slog.Uint64("invocation_seq", info.Seq),
+		)
+		if deadline, ok := ctx.Deadline(); ok {
+			attr = append(attr, slog.Int64("remaining_ms", deadline.Sub(e.clock.Now()).Milliseconds()))
+		}
+	}
+	if e.debug {
+		attr = append(attr, slog.Bool("debug_sampled", true))
+	}
+	e.logger.LogAttrs(ctx, level, e.opts.eventCompletedMsg, attr...)
+}
+
+func sampleRate(opts *eventLoggerOptions, event any) float64 {
+	if len(opts.routeSampleRates) > 0 {
+		if rate, ok := opts.routeSampleRates[routeKey(event)]; ok {
+			return rate
+		}
+	}
+	if opts.sampleRate != nil {
+		return *opts.sampleRate
+	}
+	return 1
+}
+
+// routeKey returns the API Gateway route of an event, or an empty string for other event types.
+func routeKey(event any) string {
+	switch e := event.(type) {
+	case events.APIGatewayProxyRequest:
+		return e.RequestContext.HTTPMethod + " " + e.Resource
+	case events.APIGatewayV2HTTPRequest:
+		return e.RouteKey
+	}
+	return ""
+}
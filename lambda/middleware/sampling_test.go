@@ -0,0 +1,283 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+)
+
+func fixedRandom(values ...float64) func() float64 {
+	return func() float64 {
+		v := values[0]
+		if len(values) > 1 {
+			values = values[1:]
+		}
+		return v
+	}
+}
+
+func Test_eventLoggerNoResponse_Wrap_sampling(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	type testCase struct {
+		name          string
+		options       []EventLoggerOption
+		random        func() float64
+		handlerErr    error
+		wantStarted   bool
+		wantCompleted bool
+	}
+	tests := []testCase{
+		{
+			name:          "no sample rate, logs every event",
+			random:        fixedRandom(0.99),
+			wantStarted:   true,
+			wantCompleted: true,
+		},
+		{
+			name:          "sampled, logs event",
+			options:       []EventLoggerOption{WithEventLoggerSampleRate(0.1)},
+			random:        fixedRandom(0.05),
+			wantStarted:   true,
+			wantCompleted: true,
+		},
+		{
+			name:    "not sampled, logs nothing",
+			options: []EventLoggerOption{WithEventLoggerSampleRate(0.1)},
+			random:  fixedRandom(0.5),
+		},
+		{
+			name:       "not sampled, handler error without always log on error, logs nothing",
+			options:    []EventLoggerOption{WithEventLoggerSampleRate(0.1)},
+			random:     fixedRandom(0.5),
+			handlerErr: errors.New("error"),
+		},
+		{
+			name:    "not sampled, always log on error, handler succeeds, logs nothing",
+			options: []EventLoggerOption{WithEventLoggerSampleRate(0.1), WithEventLoggerAlwaysLogOnError()},
+			random:  fixedRandom(0.5),
+		},
+		{
+			name:          "not sampled, always log on error, handler error, logs buffered start and completion",
+			options:       []EventLoggerOption{WithEventLoggerSampleRate(0.1), WithEventLoggerAlwaysLogOnError()},
+			random:        fixedRandom(0.5),
+			handlerErr:    errors.New("error"),
+			wantStarted:   true,
+			wantCompleted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mHandler := new(mockSlogHandler)
+			mHandler.On("Enabled", mock.Anything, mock.Anything).Return(true).Maybe()
+			if tt.wantStarted {
+				mHandler.On("Handle", mock.Anything, mock.MatchedBy(matchRecord(
+					defaultEventStartedMsg, slog.LevelInfo, []slog.Attr{slog.Any("event", "test")},
+				))).Return(nil).Once()
+			}
+			if tt.wantCompleted {
+				mHandler.On("Handle", mock.Anything, mock.MatchedBy(matchRecord(
+					defaultEventCompletedMsg, slog.LevelInfo, []slog.Attr{slog.Duration("duration", time.Duration(0))},
+				))).Return(nil).Once()
+			}
+
+			opts := defaultEventLoggerOptions
+			for _, option := range tt.options {
+				option(&opts)
+			}
+
+			sut := &eventLoggerNoResponse[string]{
+				clock:  clock.NewFixed(now),
+				logger: slog.New(mHandler),
+				opts:   &opts,
+				random: tt.random,
+			}
+			fn := sut.Wrap(func(_ context.Context, _ string) error { return tt.handlerErr })
+			_ = fn(context.Background(), "test")
+
+			mHandler.AssertExpectations(t)
+			if !tt.wantStarted && !tt.wantCompleted {
+				mHandler.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func Test_eventLoggerWithResponse_Wrap_alwaysLogOnError5xx(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	mHandler := new(mockSlogHandler)
+	mHandler.On("Enabled", mock.Anything, mock.Anything).Return(true).Maybe()
+	mHandler.On("Handle", mock.Anything, mock.MatchedBy(matchRecord(
+		defaultEventStartedMsg, slog.LevelInfo, []slog.Attr{slog.Any("event", "test")},
+	))).Return(nil).Once()
+	mHandler.On("Handle", mock.Anything, mock.MatchedBy(matchRecord(
+		defaultEventCompletedMsg, slog.LevelInfo, []slog.Attr{
+			slog.Duration("duration", time.Duration(0)),
+			slog.Int("status_code", http.StatusInternalServerError),
+		},
+	))).Return(nil).Once()
+
+	opts := defaultEventLoggerOptions
+	WithEventLoggerSampleRate(0)(&opts)
+	WithEventLoggerAlwaysLogOnError()(&opts)
+
+	sut := &eventLoggerWithResponse[string, events.APIGatewayProxyResponse]{
+		clock:  clock.NewFixed(now),
+		logger: slog.New(mHandler),
+		opts:   &opts,
+		random: fixedRandom(0.5),
+	}
+	fn := sut.Wrap(func(_ context.Context, _ string) (events.APIGatewayProxyResponse, error) {
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}, nil
+	})
+	_, _ = fn(context.Background(), "test")
+
+	mHandler.AssertExpectations(t)
+}
+
+func Test_eventLoggerWithResponse_Wrap_routeSampleRate(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	type testCase struct {
+		name       string
+		event      events.APIGatewayProxyRequest
+		wantLogged bool
+	}
+	tests := []testCase{
+		{
+			name: "route with sample rate 0, not logged",
+			event: events.APIGatewayProxyRequest{
+				Resource:       "/health",
+				RequestContext: events.APIGatewayProxyRequestContext{HTTPMethod: http.MethodGet},
+			},
+			wantLogged: false,
+		},
+		{
+			name: "other route falls back to sample rate 1, logged",
+			event: events.APIGatewayProxyRequest{
+				Resource:       "/customers/{id}",
+				RequestContext: events.APIGatewayProxyRequestContext{HTTPMethod: http.MethodGet},
+			},
+			wantLogged: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mHandler := new(mockSlogHandler)
+			mHandler.On("Enabled", mock.Anything, mock.Anything).Return(true).Maybe()
+			mHandler.On("Handle", mock.Anything, mock.Anything).Return(nil)
+
+			opts := defaultEventLoggerOptions
+			WithEventLoggerRouteSampleRate("GET /health", 0)(&opts)
+
+			sut := &eventLoggerWithResponse[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse]{
+				clock:  clock.NewFixed(now),
+				logger: slog.New(mHandler),
+				opts:   &opts,
+				random: fixedRandom(0.5),
+			}
+			fn := sut.Wrap(func(_ context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+			})
+			_, _ = fn(context.Background(), tt.event)
+
+			if tt.wantLogged {
+				mHandler.AssertNumberOfCalls(t, "Handle", 2)
+			} else {
+				mHandler.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func Test_eventLoggerNoResponse_Wrap_debugSampleRate(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	// The underlying handler only accepts Info and above.
+	mHandler := new(mockSlogHandler)
+	mHandler.On("Enabled", mock.Anything, mock.Anything).Return(false).Maybe()
+	mHandler.On("Handle", mock.Anything, mock.MatchedBy(matchRecord(
+		defaultEventStartedMsg, slog.LevelDebug, []slog.Attr{slog.Any("event", "test")},
+	))).Return(nil).Once()
+	mHandler.On("Handle", mock.Anything, mock.MatchedBy(matchRecord(
+		defaultEventCompletedMsg, slog.LevelDebug, []slog.Attr{
+			slog.Duration("duration", time.Duration(0)),
+			slog.Bool("debug_sampled", true),
+		},
+	))).Return(nil).Once()
+	mHandler.On("Handle", mock.Anything, mock.MatchedBy(matchRecord(
+		"handler debug", slog.LevelDebug, nil,
+	))).Return(nil).Once()
+
+	opts := defaultEventLoggerOptions
+	WithEventLoggerEventStartedLevel(slog.LevelDebug)(&opts)
+	WithEventLoggerEventCompletedLevel(slog.LevelDebug)(&opts)
+	WithEventLoggerSampleRate(0)(&opts)
+	WithEventLoggerDebugSampleRate(0.1)(&opts)
+
+	logger := slog.New(mHandler)
+	sut := &eventLoggerNoResponse[string]{
+		clock:  clock.NewFixed(now),
+		logger: logger,
+		opts:   &opts,
+		random: fixedRandom(0.05),
+	}
+	fn := sut.Wrap(func(ctx context.Context, _ string) error {
+		LoggerFromContext(ctx, logger).DebugContext(ctx, "handler debug")
+		return nil
+	})
+	_ = fn(context.Background(), "test")
+
+	mHandler.AssertExpectations(t)
+}
+
+func TestLoggerFromContext(t *testing.T) {
+	fallback := slog.New(new(mockSlogHandler))
+
+	assert.Same(t, fallback, LoggerFromContext(context.Background(), fallback))
+
+	debug := slog.New(new(mockSlogHandler))
+	ctx := context.WithValue(context.Background(), loggerContextKey{}, debug)
+	assert.Same(t, debug, LoggerFromContext(ctx, fallback))
+}
+
+func Test_loggerWithLevelFromEnv(t *testing.T) {
+	type testCase struct {
+		name          string
+		value         string
+		wantDebug     bool
+		wantInfo      bool
+		wantUnchanged bool
+	}
+	tests := []testCase{
+		{name: "unset, logger unchanged", value: "", wantUnchanged: true},
+		{name: "invalid, logger unchanged", value: "verbose", wantUnchanged: true},
+		{name: "debug, debug enabled", value: "DEBUG", wantDebug: true, wantInfo: true},
+		{name: "warn, info disabled", value: "warn", wantDebug: false, wantInfo: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_LOG_LEVEL", tt.value)
+			logger := slog.New(new(mockSlogHandler))
+
+			got := loggerWithLevelFromEnv(logger, "TEST_LOG_LEVEL")
+
+			if tt.wantUnchanged {
+				assert.Same(t, logger, got)
+				return
+			}
+			assert.Equal(t, tt.wantDebug, got.Enabled(context.Background(), slog.LevelDebug))
+			assert.Equal(t, tt.wantInfo, got.Enabled(context.Background(), slog.LevelInfo))
+		})
+	}
+}
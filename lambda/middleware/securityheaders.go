@@ -0,0 +1,213 @@
+package middleware
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/jwt"
+)
+
+const (
+	securityHeaderHSTS               = "Strict-Transport-Security"
+	securityHeaderContentTypeOptions = "X-Content-Type-Options"
+	securityHeaderCSP                = "Content-Security-Policy"
+	securityHeaderReferrerPolicy     = "Referrer-Policy"
+	securityHeaderCacheControl       = "Cache-Control"
+)
+
+var defaultRemovedHeaders = []string{"Server", "X-Powered-By", "X-AspNet-Version"}
+
+// securityHeader is a header of the policy, set when the response does not set it.
+type securityHeader struct {
+	name  string
+	value string
+}
+
+type securityHeadersOptions struct {
+	headers                   []securityHeader
+	removed                   []string
+	authenticatedCacheControl string
+}
+
+// SecurityHeadersOption configures NewSecurityHeadersWithResponse.
+type SecurityHeadersOption func(*securityHeadersOptions)
+
+// WithSecurityHeader sets the header name of the policy to value, or removes it from the policy if value is empty.
+func WithSecurityHeader(name, value string) SecurityHeadersOption {
+	return func(o *securityHeadersOptions) {
+		headers := slices.DeleteFunc(slices.Clone(o.headers), func(h securityHeader) bool {
+			return strings.EqualFold(h.name, name)
+		})
+		if value != "" {
+			headers = append(headers, securityHeader{name: name, value: value})
+		}
+		o.headers = headers
+	}
+}
+
+// WithSecurityHeadersHSTS sets the Strict-Transport-Security header to maxAge, in whole seconds, including subdomains
+// if includeSubDomains is true. Defaults to 1 year, including subdomains. A maxAge of 0 removes it from the policy.
+func WithSecurityHeadersHSTS(maxAge time.Duration, includeSubDomains bool) SecurityHeadersOption {
+	if maxAge <= 0 {
+		return WithSecurityHeader(securityHeaderHSTS, "")
+	}
+	value := "max-age=" + strconv.FormatInt(int64(maxAge.Seconds()), 10)
+	if includeSubDomains {
+		value += "; includeSubDomains"
+	}
+	return WithSecurityHeader(securityHeaderHSTS, value)
+}
+
+// WithSecurityHeadersCSP sets the Content-Security-Policy header to policy. Defaults to
+// "default-src 'none'; frame-ancestors 'none'", which suits APIs rather than pages.
+func WithSecurityHeadersCSP(policy string) SecurityHeadersOption {
+	return WithSecurityHeader(securityHeaderCSP, policy)
+}
+
+// WithSecurityHeadersReferrerPolicy sets the Referrer-Policy header to policy. Defaults to "no-referrer".
+func WithSecurityHeadersReferrerPolicy(policy string) SecurityHeadersOption {
+	return WithSecurityHeader(securityHeaderReferrerPolicy, policy)
+}
+
+// WithSecurityHeadersAuthenticatedCacheControl sets the Cache-Control header of the responses of authenticated
+// requests to value, or not at all if value is empty. Defaults to "no-store".
+func WithSecurityHeadersAuthenticatedCacheControl(value string) SecurityHeadersOption {
+	return func(o *securityHeadersOptions) {
+		o.authenticatedCacheControl = value
+	}
+}
+
+// WithSecurityHeadersRemoved sets the headers removed from responses, as they leak details of the implementation.
+// Defaults to Server, X-Powered-By and X-AspNet-Version.
+func WithSecurityHeadersRemoved(names ...string) SecurityHeadersOption {
+	return func(o *securityHeadersOptions) {
+		o.removed = names
+	}
+}
+
+type securityHeadersWithResponse[E, R any] struct {
+	opts securityHeadersOptions
+}
+
+// NewSecurityHeadersWithResponse returns an implementation of WithResponse for the security headers middleware.
+//
+// The security headers middleware applies a security header policy to API Gateway v1 and v2, ALB and Lambda Function
+// URL responses. By default it sets Strict-Transport-Security, X-Content-Type-Options: nosniff, a
+// Content-Security-Policy denying everything and Referrer-Policy: no-referrer, and removes headers leaking details of
+// the implementation (see WithSecurityHeadersRemoved). Responses of authenticated requests, with an Authorization
+// header, claims added by the auth middleware or an API Gateway authorizer context, also get Cache-Control: no-store.
+//
+// Headers already set by the handler are kept, so a handler can set its own, e.g. a Content-Security-Policy for a page.
+// Responses without headers get a headers map.
+func NewSecurityHeadersWithResponse[E, R any](options ...SecurityHeadersOption) WithResponse[E, R] {
+	opts := securityHeadersOptions{
+		headers: []securityHeader{
+			{name: securityHeaderHSTS, value: "max-age=31536000; includeSubDomains"},
+			{name: securityHeaderContentTypeOptions, value: "nosniff"},
+			{name: securityHeaderCSP, value: "default-src 'none'; frame-ancestors 'none'"},
+			{name: securityHeaderReferrerPolicy, value: "no-referrer"},
+		},
+		removed:                   defaultRemovedHeaders,
+		authenticatedCacheControl: "no-store",
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+	return &securityHeadersWithResponse[E, R]{opts: opts}
+}
+
+func (s securityHeadersWithResponse[E, R]) Wrap(next func(context.Context, E) (R, error)) func(context.Context, E) (R, error) {
+	return func(ctx context.Context, event E) (R, error) {
+		res, err := next(ctx, event)
+		if err != nil {
+			return res, err
+		}
+
+		headers := s.opts.headers
+		if s.opts.authenticatedCacheControl != "" && authenticatedRequest(ctx, event) {
+			headers = append(slices.Clip(headers), securityHeader{name: securityHeaderCacheControl, value: s.opts.authenticatedCacheControl})
+		}
+
+		var converted any
+		switch r := any(res).(type) {
+		case events.APIGatewayProxyResponse:
+			r.Headers, r.MultiValueHeaders = s.apply(headers, r.Headers, r.MultiValueHeaders)
+			converted = r
+		case events.APIGatewayV2HTTPResponse:
+			r.Headers, r.MultiValueHeaders = s.apply(headers, r.Headers, r.MultiValueHeaders)
+			converted = r
+		case events.ALBTargetGroupResponse:
+			r.Headers, r.MultiValueHeaders = s.apply(headers, r.Headers, r.MultiValueHeaders)
+			converted = r
+		case events.LambdaFunctionURLResponse:
+			r.Headers, _ = s.apply(headers, r.Headers, nil)
+			converted = r
+		default:
+			return res, nil
+		}
+		if r, ok := converted.(R); ok {
+			return r, nil
+		}
+		return res, nil
+	}
+}
+
+// apply returns copies of the headers of a response with the removed headers removed and the headers of the policy
+// the response does not set added. They are added to the multi-value headers when the response uses them, as ALB
+// target groups with multi-value headers enabled ignore single-value headers.
+func (s securityHeadersWithResponse[E, R]) apply(policy []securityHeader, headers map[string]string, multiValueHeaders map[string][]string) (map[string]string, map[string][]string) {
+	headers = maps.Clone(headers)
+	multiValueHeaders = maps.Clone(multiValueHeaders)
+	for _, name := range s.opts.removed {
+		maps.DeleteFunc(headers, func(k, _ string) bool { return strings.EqualFold(k, name) })
+		maps.DeleteFunc(multiValueHeaders, func(k string, _ []string) bool { return strings.EqualFold(k, name) })
+	}
+
+	for _, h := range policy {
+		if hasHeader(headers, h.name) || hasHeader(multiValueHeaders, h.name) {
+			continue
+		}
+		if multiValueHeaders != nil {
+			multiValueHeaders[h.name] = []string{h.value}
+			continue
+		}
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		headers[h.name] = h.value
+	}
+	return headers, multiValueHeaders
+}
+
+func hasHeader[V any](headers map[string]V, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticatedRequest returns whether a request has an Authorization header, claims added by the auth middleware or
+// an API Gateway authorizer context.
+func authenticatedRequest(ctx context.Context, event any) bool {
+	if _, ok := jwt.FromContext(ctx); ok {
+		return true
+	}
+	if requestHeader(event, "Authorization") != "" {
+		return true
+	}
+	switch e := event.(type) {
+	case events.APIGatewayProxyRequest:
+		return len(e.RequestContext.Authorizer) > 0
+	case events.APIGatewayV2HTTPRequest:
+		return e.RequestContext.Authorizer != nil
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/jwt"
+)
+
+func Test_securityHeadersWithResponse_Wrap(t *testing.T) {
+	defaults := map[string]string{
+		"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+		"X-Content-Type-Options":    "nosniff",
+		"Content-Security-Policy":   "default-src 'none'; frame-ancestors 'none'",
+		"Referrer-Policy":           "no-referrer",
+	}
+	with := func(headers map[string]string) map[string]string {
+		merged := map[string]string{}
+		for k, v := range defaults {
+			merged[k] = v
+		}
+		for k, v := range headers {
+			if v == "" {
+				delete(merged, k)
+				continue
+			}
+			merged[k] = v
+		}
+		return merged
+	}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		options     []SecurityHeadersOption
+		event       events.APIGatewayProxyRequest
+		handler     map[string]string
+		wantHeaders map[string]string
+	}{
+		{
+			name:        "response without headers, defaults added",
+			ctx:         context.Background(),
+			wantHeaders: defaults,
+		},
+		{
+			name:        "header set by handler, kept",
+			ctx:         context.Background(),
+			handler:     map[string]string{"content-security-policy": "default-src 'self'"},
+			wantHeaders: with(map[string]string{"Content-Security-Policy": "", "content-security-policy": "default-src 'self'"}),
+		},
+		{
+			name:        "implementation headers, removed",
+			ctx:         context.Background(),
+			handler:     map[string]string{"server": "nginx", "X-Powered-By": "Express", "Content-Type": "application/json"},
+			wantHeaders: with(map[string]string{"Content-Type": "application/json"}),
+		},
+		{
+			name:        "authorization header, no-store added",
+			ctx:         context.Background(),
+			event:       events.APIGatewayProxyRequest{Headers: map[string]string{"authorization": "Bearer token"}},
+			wantHeaders: with(map[string]string{"Cache-Control": "no-store"}),
+		},
+		{
+			name:        "claims in context, no-store added",
+			ctx:         jwt.NewContext(context.Background(), jwt.Claims{Subject: "user-123"}),
+			wantHeaders: with(map[string]string{"Cache-Control": "no-store"}),
+		},
+		{
+			name: "authorizer context, no-store added",
+			ctx:  context.Background(),
+			event: events.APIGatewayProxyRequest{RequestContext: events.APIGatewayProxyRequestContext{
+				Authorizer: map[string]any{"principalId": "user-123"},
+			}},
+			wantHeaders: with(map[string]string{"Cache-Control": "no-store"}),
+		},
+		{
+			name:        "authenticated with cache control set by handler, kept",
+			ctx:         jwt.NewContext(context.Background(), jwt.Claims{Subject: "user-123"}),
+			handler:     map[string]string{"Cache-Control": "private, max-age=60"},
+			wantHeaders: with(map[string]string{"Cache-Control": "private, max-age=60"}),
+		},
+		{
+			name:        "authenticated without authenticated cache control, not added",
+			ctx:         jwt.NewContext(context.Background(), jwt.Claims{Subject: "user-123"}),
+			options:     []SecurityHeadersOption{WithSecurityHeadersAuthenticatedCacheControl("")},
+			wantHeaders: defaults,
+		},
+		{
+			name: "options, policy changed",
+			ctx:  context.Background(),
+			options: []SecurityHeadersOption{
+				WithSecurityHeadersHSTS(0, false),
+				WithSecurityHeadersCSP("default-src 'self'"),
+				WithSecurityHeadersReferrerPolicy("same-origin"),
+				WithSecurityHeader("x-content-type-options", ""),
+				WithSecurityHeader("Permissions-Policy", "geolocation=()"),
+				WithSecurityHeadersRemoved("X-Debug"),
+			},
+			handler: map[string]string{"Server": "nginx", "X-Debug": "true"},
+			wantHeaders: map[string]string{
+				"Server":                  "nginx",
+				"Content-Security-Policy": "default-src 'self'",
+				"Referrer-Policy":         "same-origin",
+				"Permissions-Policy":      "geolocation=()",
+			},
+		},
+		{
+			name:        "hsts without subdomains",
+			ctx:         context.Background(),
+			options:     []SecurityHeadersOption{WithSecurityHeadersHSTS(24*time.Hour, false)},
+			wantHeaders: with(map[string]string{"Strict-Transport-Security": "max-age=86400"}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := func(context.Context, events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+				return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Headers: tt.handler}, nil
+			}
+
+			m := NewSecurityHeadersWithResponse[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse](tt.options...)
+			got, err := m.Wrap(handler)(tt.ctx, tt.event)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, got.StatusCode)
+			assert.Equal(t, tt.wantHeaders, got.Headers)
+		})
+	}
+}
+
+func Test_securityHeadersWithResponse_Wrap_v2(t *testing.T) {
+	m := NewSecurityHeadersWithResponse[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse]()
+
+	got, err := m.Wrap(func(context.Context, events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusOK, Headers: map[string]string{"Server": "nginx"}}, nil
+	})(context.Background(), events.APIGatewayV2HTTPRequest{
+		RequestContext: events.APIGatewayV2HTTPRequestContext{Authorizer: &events.APIGatewayV2HTTPRequestContextAuthorizerDescription{}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+		"X-Content-Type-Options":    "nosniff",
+		"Content-Security-Policy":   "default-src 'none'; frame-ancestors 'none'",
+		"Referrer-Policy":           "no-referrer",
+		"Cache-Control":             "no-store",
+	}, got.Headers)
+}
+
+func Test_securityHeadersWithResponse_Wrap_alb(t *testing.T) {
+	m := NewSecurityHeadersWithResponse[events.ALBTargetGroupRequest, events.ALBTargetGroupResponse](
+		WithSecurityHeadersCSP(""), WithSecurityHeadersReferrerPolicy(""))
+
+	got, err := m.Wrap(func(context.Context, events.ALBTargetGroupRequest) (events.ALBTargetGroupResponse, error) {
+		return events.ALBTargetGroupResponse{
+			StatusCode:        http.StatusOK,
+			MultiValueHeaders: map[string][]string{"Set-Cookie": {"a=1", "b=2"}, "X-Powered-By": {"PHP"}},
+		}, nil
+	})(context.Background(), events.ALBTargetGroupRequest{
+		MultiValueHeaders: map[string][]string{"Authorization": {"Bearer token"}},
+	})
+
+	assert.NoError(t, err)
+	assert.Nil(t, got.Headers)
+	assert.Equal(t, map[string][]string{
+		"Set-Cookie":                {"a=1", "b=2"},
+		"Strict-Transport-Security": {"max-age=31536000; includeSubDomains"},
+		"X-Content-Type-Options":    {"nosniff"},
+		"Cache-Control":             {"no-store"},
+	}, got.MultiValueHeaders)
+}
+
+func Test_securityHeadersWithResponse_Wrap_functionURL(t *testing.T) {
+	m := NewSecurityHeadersWithResponse[events.LambdaFunctionURLRequest, events.LambdaFunctionURLResponse](
+		WithSecurityHeadersHSTS(0, false), WithSecurityHeadersCSP(""), WithSecurityHeadersReferrerPolicy(""))
+	errHandler := errors.New("error")
+
+	got, err := m.Wrap(func(context.Context, events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+		return events.LambdaFunctionURLResponse{StatusCode: http.StatusCreated}, nil
+	})(context.Background(), events.LambdaFunctionURLRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, events.LambdaFunctionURLResponse{
+		StatusCode: http.StatusCreated,
+		Headers:    map[string]string{"X-Content-Type-Options": "nosniff"},
+	}, got)
+
+	_, err = m.Wrap(func(context.Context, events.LambdaFunctionURLRequest) (events.LambdaFunctionURLResponse, error) {
+		return events.LambdaFunctionURLResponse{}, errHandler
+	})(context.Background(), events.LambdaFunctionURLRequest{})
+	assert.ErrorIs(t, err, errHandler)
+}
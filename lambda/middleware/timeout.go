@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/response"
+)
+
+const (
+	defaultTimeoutSafetyMargin = 500 * time.Millisecond
+	defaultTimeoutMsg          = "Request timed out"
+)
+
+// ErrTimeout is returned by the timeout middleware when the handler does not complete before its deadline.
+var ErrTimeout = errors.New("middleware: handler did not complete before its deadline")
+
+type timeoutOptions struct {
+	safetyMargin  time.Duration
+	routeTimeouts map[string]time.Duration
+}
+
+// TimeoutOption configures NewTimeout/NewTimeoutWithResponse.
+type TimeoutOption func(*timeoutOptions)
+
+// WithTimeoutSafetyMargin sets how long before the Lambda deadline the handler's deadline is, leaving enough time to
+// log the timeout and return a response before Lambda stops the invocation. Defaults to 500ms.
+func WithTimeoutSafetyMargin(d time.Duration) TimeoutOption {
+	return func(o *timeoutOptions) {
+		o.safetyMargin = d
+	}
+}
+
+// WithTimeoutRoute sets a timeout for a single API Gateway route, matched against the RouteKey of API Gateway v2
+// requests and "<HTTP method> <resource>" (e.g. "GET /customers/{id}") of API Gateway v1 requests. The Lambda deadline
+// minus the safety margin still applies if it is earlier. Call it once per route.
+func WithTimeoutRoute(route string, d time.Duration) TimeoutOption {
+	return func(o *timeoutOptions) {
+		timeouts := make(map[string]time.Duration, len(o.routeTimeouts)+1)
+		for k, v := range o.routeTimeouts {
+			timeouts[k] = v
+		}
+		timeouts[route] = d
+		o.routeTimeouts = timeouts
+	}
+}
+
+type timeoutNoResponse[E any] struct {
+	clock  clock.Clock
+	logger *slog.Logger
+	opts   timeoutOptions
+}
+
+// NewTimeout returns an implementation of NoResponse for the timeout middleware.
+//
+// The timeout middleware runs the handler with a context deadline of the Lambda deadline minus a safety margin (see
+// WithTimeoutSafetyMargin). If the handler has not returned by then, a timeout log record containing the elapsed time
+// is logged and ErrTimeout is returned, instead of Lambda stopping the invocation without a trace. The handler keeps
+// running in the background, so it should stop when its context is done.
+func NewTimeout[E any](logger *slog.Logger, options ...TimeoutOption) NoResponse[E] {
+	return &timeoutNoResponse[E]{
+		clock:  clock.NewSystem(),
+		logger: logger,
+		opts:   newTimeoutOptions(options),
+	}
+}
+
+func (t timeoutNoResponse[E]) Wrap(next func(context.Context, E) error) func(context.Context, E) error {
+	return func(ctx context.Context, event E) error {
+		deadline, ok := timeoutDeadline(ctx, t.clock, t.opts, event)
+		if !ok {
+			return next(ctx, event)
+		}
+
+		start := t.clock.Now()
+		ctx, cancel := context.WithDeadline(ctx, deadline)
+		defer cancel()
+
+		_, timedOut, err := runUntilDone(ctx, func(ctx context.Context) (struct{}, error) {
+			return struct{}{}, next(ctx, event)
+		})
+		if timedOut {
+			logTimeout(ctx, t.logger, t.clock, start, deadline)
+			return ErrTimeout
+		}
+		return err
+	}
+}
+
+type timeoutWithResponse[E, R any] struct {
+	clock  clock.Clock
+	logger *slog.Logger
+	opts   timeoutOptions
+}
+
+// NewTimeoutWithResponse returns an implementation of WithResponse for the timeout middleware.
+//
+// The timeout middleware runs the handler with a context deadline of the Lambda deadline minus a safety margin (see
+// WithTimeoutSafetyMargin). If the handler has not returned by then, a timeout log record containing the elapsed time
+// is logged and ErrTimeout is returned, instead of Lambda stopping the invocation without a trace. The handler keeps
+// running in the background, so it should stop when its context is done.
+//
+// For API Gateway v1 and v2 responses a response.ErrorCodeTimeout (504) response is returned instead of ErrTimeout.
+func NewTimeoutWithResponse[E, R any](logger *slog.Logger, options ...TimeoutOption) WithResponse[E, R] {
+	return &timeoutWithResponse[E, R]{
+		clock:  clock.NewSystem(),
+		logger: logger,
+		opts:   newTimeoutOptions(options),
+	}
+}
+
+func (t timeoutWithResponse[E, R]) Wrap(next func(context.Context, E) (R, error)) func(context.Context, E) (R, error) {
+	return func(ctx context.Context, event E) (R, error) {
+		deadline, ok := timeoutDeadline(ctx, t.clock, t.opts, event)
+		if !ok {
+			return next(ctx, event)
+		}
+
+		start := t.clock.Now()
+		ctx, cancel := context.WithDeadline(ctx, deadline)
+		defer cancel()
+
+		res, timedOut, err := runUntilDone(ctx, func(ctx context.Context) (R, error) {
+			return next(ctx, event)
+		})
+		if timedOut {
+			logTimeout(ctx, t.logger, t.clock, start, deadline)
+			if res, ok := errorCodeResponse[R](response.ErrorCodeTimeout); ok {
+				return res, nil
+			}
+			return res, ErrTimeout
+		}
+		return res, err
+	}
+}
+
+func newTimeoutOptions(options []TimeoutOption) timeoutOptions {
+	opts := timeoutOptions{safetyMargin: defaultTimeoutSafetyMargin}
+	for _, option := range options {
+		option(&opts)
+	}
+	return opts
+}
+
+// timeoutDeadline returns the deadline for the handler, or false if neither the context nor the route has one.
+func timeoutDeadline(ctx context.Context, clk clock.Clock, opts timeoutOptions, event any) (time.Time, bool) {
+	deadline, ok := ctx.Deadline()
+	if ok {
+		deadline = deadline.Add(-opts.safetyMargin)
+	}
+	if len(opts.routeTimeouts) > 0 {
+		if d, found := opts.routeTimeouts[routeKey(event)]; found {
+			if routeDeadline := clk.Now().Add(d); !ok || routeDeadline.Before(deadline) {
+				deadline, ok = routeDeadline, true
+			}
+		}
+	}
+	return deadline, ok
+}
+
+// runUntilDone runs fn in a goroutine and returns its result, or true if ctx is done first. A panic in fn is
+// re-raised on the calling goroutine, so it is still reported by the Lambda runtime.
+func runUntilDone[R any](ctx context.Context, fn func(context.Context) (R, error)) (R, bool, error) {
+	type result struct {
+		response R
+		err      error
+		panicked any
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- result{panicked: p}
+			}
+		}()
+		res, err := fn(ctx)
+		done <- result{response: res, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.panicked != nil {
+			panic(res.panicked)
+		}
+		return res.response, false, res.err
+	case <-ctx.Done():
+		var zero R
+		return zero, true, nil
+	}
+}
+
+func logTimeout(ctx context.Context, logger *slog.Logger, clk clock.Clock, start, deadline time.Time) {
+	logger.LogAttrs(ctx, slog.LevelError, defaultTimeoutMsg,
+		slog.Duration("elapsed", clk.Since(start)),
+		slog.Duration("timeout", deadline.Sub(start)),
+	)
+}
+
+// errorCodeResponse returns a response.NewErrorCode response for API Gateway response types.
+func errorCodeResponse[R any](code response.ErrorCode, opts ...response.ErrorCodeOption) (R, bool) {
+	var res R
+	var converted any
+	switch any(res).(type) {
+	case events.APIGatewayProxyResponse:
+		converted = response.NewErrorCode(code, opts...)
+	case events.APIGatewayV2HTTPResponse:
+		v1 := response.NewErrorCode(code, opts...)
+		converted = events.APIGatewayV2HTTPResponse{
+			StatusCode: v1.StatusCode,
+			Headers:    v1.Headers,
+			Body:       v1.Body,
+		}
+	}
+	res, ok := converted.(R)
+	return res, ok
+}
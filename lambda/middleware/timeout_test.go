@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/ellogroup/ello-golang-clock/clock"
+
+	"github.com/ellogroup/ello-golang-aws/v2/apigw/response"
+)
+
+func Test_timeoutNoResponse_Wrap(t *testing.T) {
+	errHandler := errors.New("error")
+
+	type testCase struct {
+		name        string
+		deadline    time.Duration
+		handler     func(ctx context.Context) error
+		wantTimeout bool
+		wantErr     error
+	}
+	tests := []testCase{
+		{
+			name:     "no deadline, handler returns nil, returns nil",
+			deadline: 0,
+			handler:  func(_ context.Context) error { return nil },
+		},
+		{
+			name:     "handler completes before deadline, returns handler error",
+			deadline: time.Second,
+			handler:  func(_ context.Context) error { return errHandler },
+			wantErr:  errHandler,
+		},
+		{
+			name:     "handler overruns deadline minus safety margin, logs timeout, returns ErrTimeout",
+			deadline: 20 * time.Millisecond,
+			handler: func(ctx context.Context) error {
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			},
+			wantTimeout: true,
+			wantErr:     ErrTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mHandler := new(mockSlogHandler)
+			mHandler.On("Enabled", mock.Anything, mock.Anything).Return(true).Maybe()
+			if tt.wantTimeout {
+				mHandler.On("Handle", mock.Anything, mock.MatchedBy(func(r slog.Record) bool {
+					return r.Message == defaultTimeoutMsg && r.Level == slog.LevelError
+				})).Return(nil).Once()
+			}
+
+			ctx := context.Background()
+			if tt.deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+				defer cancel()
+			}
+
+			sut := &timeoutNoResponse[string]{
+				clock:  clock.NewSystem(),
+				logger: slog.New(mHandler),
+				opts:   newTimeoutOptions([]TimeoutOption{WithTimeoutSafetyMargin(10 * time.Millisecond)}),
+			}
+			fn := sut.Wrap(func(ctx context.Context, _ string) error { return tt.handler(ctx) })
+
+			assert.ErrorIs(t, fn(ctx, "event"), tt.wantErr)
+			mHandler.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_timeoutWithResponse_Wrap(t *testing.T) {
+	blockUntilDone := func(ctx context.Context) {
+		<-ctx.Done()
+	}
+
+	t.Run("api gateway v1 handler overruns, returns 504 response", func(t *testing.T) {
+		mHandler := new(mockSlogHandler)
+		mHandler.On("Enabled", mock.Anything, mock.Anything).Return(true).Maybe()
+		mHandler.On("Handle", mock.Anything, mock.Anything).Return(nil).Once()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		sut := &timeoutWithResponse[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse]{
+			clock:  clock.NewSystem(),
+			logger: slog.New(mHandler),
+			opts:   newTimeoutOptions([]TimeoutOption{WithTimeoutSafetyMargin(10 * time.Millisecond)}),
+		}
+		fn := sut.Wrap(func(ctx context.Context, _ events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+			blockUntilDone(ctx)
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusOK}, nil
+		})
+		gotResp, gotErr := fn(ctx, events.APIGatewayProxyRequest{})
+
+		assert.NoError(t, gotErr)
+		assert.Equal(t, response.NewErrorCode(response.ErrorCodeTimeout), gotResp)
+		mHandler.AssertExpectations(t)
+	})
+
+	t.Run("api gateway v2 route timeout overruns, returns 504 response", func(t *testing.T) {
+		mHandler := new(mockSlogHandler)
+		mHandler.On("Enabled", mock.Anything, mock.Anything).Return(true).Maybe()
+		mHandler.On("Handle", mock.Anything, mock.Anything).Return(nil).Once()
+
+		sut := &timeoutWithResponse[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse]{
+			clock:  clock.NewSystem(),
+			logger: slog.New(mHandler),
+			opts:   newTimeoutOptions([]TimeoutOption{WithTimeoutRoute("POST /reports", 10*time.Millisecond)}),
+		}
+		fn := sut.Wrap(func(ctx context.Context, _ events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+			blockUntilDone(ctx)
+			return events.APIGatewayV2HTTPResponse{StatusCode: http.StatusOK}, nil
+		})
+		gotResp, gotErr := fn(context.Background(), events.APIGatewayV2HTTPRequest{RouteKey: "POST /reports"})
+
+		assert.NoError(t, gotErr)
+		assert.Equal(t, http.StatusGatewayTimeout, gotResp.StatusCode)
+		assert.JSONEq(t, `{"code":"timeout","message":"The request took too long to process. Please retry."}`, gotResp.Body)
+		mHandler.AssertExpectations(t)
+	})
+
+	t.Run("other response type overruns, returns ErrTimeout", func(t *testing.T) {
+		mHandler := new(mockSlogHandler)
+		mHandler.On("Enabled", mock.Anything, mock.Anything).Return(true).Maybe()
+		mHandler.On("Handle", mock.Anything, mock.Anything).Return(nil).Once()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		sut := &timeoutWithResponse[string, string]{
+			clock:  clock.NewSystem(),
+			logger: slog.New(mHandler),
+			opts:   newTimeoutOptions([]TimeoutOption{WithTimeoutSafetyMargin(10 * time.Millisecond)}),
+		}
+		fn := sut.Wrap(func(ctx context.Context, _ string) (string, error) {
+			blockUntilDone(ctx)
+			return "response", nil
+		})
+		gotResp, gotErr := fn(ctx, "event")
+
+		assert.ErrorIs(t, gotErr, ErrTimeout)
+		assert.Empty(t, gotResp)
+	})
+
+	t.Run("handler completes, returns handler response", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		sut := NewTimeoutWithResponse[string, string](slog.New(new(mockSlogHandler)))
+		fn := sut.Wrap(func(_ context.Context, _ string) (string, error) {
+			return "response", nil
+		})
+		gotResp, gotErr := fn(ctx, "event")
+
+		assert.NoError(t, gotErr)
+		assert.Equal(t, "response", gotResp)
+	})
+
+	t.Run("handler panics, panic is re-raised", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		sut := NewTimeoutWithResponse[string, string](slog.New(new(mockSlogHandler)))
+		fn := sut.Wrap(func(_ context.Context, _ string) (string, error) {
+			panic("boom")
+		})
+
+		assert.PanicsWithValue(t, "boom", func() { _, _ = fn(ctx, "event") })
+	})
+}
+
+func Test_timeoutDeadline(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC)
+	lambdaDeadline := now.Add(10 * time.Second)
+
+	withDeadline := func() context.Context {
+		ctx, cancel := context.WithDeadline(context.Background(), lambdaDeadline)
+		t.Cleanup(cancel)
+		return ctx
+	}
+
+	type testCase struct {
+		name         string
+		ctx          context.Context
+		options      []TimeoutOption
+		event        any
+		wantDeadline time.Time
+		wantOk       bool
+	}
+	tests := []testCase{
+		{
+			name:   "no lambda deadline, no route timeout, no deadline",
+			ctx:    context.Background(),
+			event:  "event",
+			wantOk: false,
+		},
+		{
+			name:         "lambda deadline, default safety margin subtracted",
+			ctx:          withDeadline(),
+			event:        "event",
+			wantDeadline: lambdaDeadline.Add(-defaultTimeoutSafetyMargin),
+			wantOk:       true,
+		},
+		{
+			name:         "route timeout earlier than lambda deadline, route deadline used",
+			ctx:          withDeadline(),
+			options:      []TimeoutOption{WithTimeoutRoute("GET /health", time.Second)},
+			event:        events.APIGatewayProxyRequest{Resource: "/health", RequestContext: events.APIGatewayProxyRequestContext{HTTPMethod: http.MethodGet}},
+			wantDeadline: now.Add(time.Second),
+			wantOk:       true,
+		},
+		{
+			name:         "route timeout later than lambda deadline, lambda deadline used",
+			ctx:          withDeadline(),
+			options:      []TimeoutOption{WithTimeoutRoute("GET /health", time.Minute)},
+			event:        events.APIGatewayProxyRequest{Resource: "/health", RequestContext: events.APIGatewayProxyRequestContext{HTTPMethod: http.MethodGet}},
+			wantDeadline: lambdaDeadline.Add(-defaultTimeoutSafetyMargin),
+			wantOk:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDeadline, gotOk := timeoutDeadline(tt.ctx, clock.NewFixed(now), newTimeoutOptions(tt.options), tt.event)
+			assert.Equal(t, tt.wantOk, gotOk)
+			assert.Equal(t, tt.wantDeadline, gotDeadline)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package s3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+
+	"github.com/ellogroup/ello-golang-aws/v2/lambda"
+)
+
+const (
+	eventBridgeObjectCreated = "Object Created"
+	eventBridgeObjectDeleted = "Object Deleted"
+)
+
+// eventBridgeDetail is the detail of an S3 event delivered through EventBridge.
+type eventBridgeDetail struct {
+	Bucket struct {
+		Name string `json:"name"`
+	} `json:"bucket"`
+	Object struct {
+		Key       string `json:"key"`
+		Size      int64  `json:"size"`
+		ETag      string `json:"etag"`
+		VersionID string `json:"version-id"`
+		Sequencer string `json:"sequencer"`
+	} `json:"object"`
+	Reason       string `json:"reason"`
+	DeletionType string `json:"deletion-type"`
+}
+
+// HandleEventBridge routes the object of an S3 event delivered through EventBridge, as HandleObject does. The
+// detail-type of the event is mapped to the equivalent S3 event name, e.g. "Object Created" with the reason
+// "PutObject" to "ObjectCreated:Put". Keys are not URL-encoded in EventBridge events.
+func (r *Router) HandleEventBridge(ctx context.Context, event events.EventBridgeEvent) error {
+	var detail eventBridgeDetail
+	if err := json.Unmarshal(event.Detail, &detail); err != nil {
+		return fmt.Errorf("decoding S3 event detail: %w", err)
+	}
+	return r.HandleObject(ctx, Object{
+		Bucket:    detail.Bucket.Name,
+		Key:       detail.Object.Key,
+		Size:      detail.Object.Size,
+		ETag:      detail.Object.ETag,
+		VersionID: detail.Object.VersionID,
+		Sequencer: detail.Object.Sequencer,
+		EventName: eventBridgeEventName(event.DetailType, detail),
+		EventTime: event.Time,
+		Region:    event.Region,
+	})
+}
+
+// EventBridge returns r as a lambda.Handler of S3 events delivered through EventBridge.
+func (r *Router) EventBridge() lambda.Handler[events.EventBridgeEvent] {
+	return eventBridgeHandler{router: r}
+}
+
+type eventBridgeHandler struct {
+	router *Router
+}
+
+func (h eventBridgeHandler) Handle(ctx context.Context, event events.EventBridgeEvent) error {
+	return h.router.HandleEventBridge(ctx, event)
+}
+
+func eventBridgeEventName(detailType string, detail eventBridgeDetail) string {
+	switch detailType {
+	case eventBridgeObjectCreated:
+		switch detail.Reason {
+		case "PutObject":
+			return string(ObjectCreated) + ":Put"
+		case "POST Object":
+			return string(ObjectCreated) + ":Post"
+		case "CopyObject":
+			return string(ObjectCreated) + ":Copy"
+		case "CompleteMultipartUpload":
+			return string(ObjectCreated) + ":CompleteMultipartUpload"
+		default:
+			return string(ObjectCreated) + ":" + detail.Reason
+		}
+	case eventBridgeObjectDeleted:
+		if detail.DeletionType == "Delete Marker Created" {
+			return string(ObjectRemoved) + ":DeleteMarkerCreated"
+		}
+		return string(ObjectRemoved) + ":Delete"
+	default:
+		return detailType
+	}
+}
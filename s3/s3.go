@@ -0,0 +1,186 @@
+// Package s3 handles S3 event notifications object by object, with URL-decoded keys, routing each object to a
+// handler by the type of event and a glob pattern matching its key. Notifications delivered directly by S3 and through
+// EventBridge are handled alike.
+//
+//	router := s3.NewRouter()
+//	router.Route(s3.ObjectCreated, "uploads/**.csv", importHandler)
+//	router.Route(s3.ObjectRemoved, "**", cleanupHandler)
+//
+//	lambda.Start[events.S3Event](router, middleware.CommonS3(logger))
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// EventType is the type of an S3 event, the prefix of its event name, e.g. ObjectCreated for "ObjectCreated:Put".
+type EventType string
+
+const (
+	// AnyEvent matches every event.
+	AnyEvent EventType = ""
+	// ObjectCreated matches events for objects created by a put, post, copy or multipart upload.
+	ObjectCreated EventType = "ObjectCreated"
+	// ObjectRemoved matches events for objects deleted, or with a delete marker created.
+	ObjectRemoved EventType = "ObjectRemoved"
+)
+
+// ErrNoRoute is returned by a Router for an object without a matching route when there is no default route.
+var ErrNoRoute = errors.New("s3: no route for object")
+
+// Object is an object an S3 event is for.
+type Object struct {
+	// Bucket is the name of the bucket.
+	Bucket string
+	// Key is the URL-decoded key of the object.
+	Key string
+	// Size is the size of the object in bytes. It is 0 for removed objects.
+	Size int64
+	// ETag is the ETag of the object. It is empty for removed objects.
+	ETag string
+	// VersionID is the version of the object, for versioned buckets.
+	VersionID string
+	// Sequencer orders events for the same key.
+	Sequencer string
+	// EventName is the name of the event, e.g. "ObjectCreated:Put" or "ObjectRemoved:DeleteMarkerCreated".
+	EventName string
+	// EventTime is the time of the event.
+	EventTime time.Time
+	// Region is the region of the bucket.
+	Region string
+}
+
+// Handler interface should be implemented for handlers of objects S3 events are for.
+type Handler interface {
+	// Handle handles an object.
+	Handle(ctx context.Context, object Object) error
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as a Handler.
+type HandlerFunc func(ctx context.Context, object Object) error
+
+// Handle calls f(ctx, object).
+func (f HandlerFunc) Handle(ctx context.Context, object Object) error {
+	return f(ctx, object)
+}
+
+type route struct {
+	eventType EventType
+	pattern   *regexp.Regexp
+	handler   Handler
+}
+
+// Router routes the objects of S3 events to handlers registered with Route. It implements
+// lambda.Handler[events.S3Event], and handles S3 events delivered through EventBridge with HandleEventBridge.
+//
+// Routes must be registered before the Router handles events.
+type Router struct {
+	routes       []route
+	defaultRoute Handler
+}
+
+// NewRouter returns a Router without any routes.
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Route registers handler for objects with keys matching pattern in events of eventType. Routes are matched in the
+// order they are registered.
+//
+// In pattern, "*" matches any sequence of characters other than "/", "**" matches any sequence of characters and "?"
+// matches any single character other than "/". e.g. "uploads/*.csv" matches "uploads/a.csv" but not
+// "uploads/2024/a.csv", which "uploads/**.csv" matches.
+func (r *Router) Route(eventType EventType, pattern string, handler Handler) {
+	r.routes = append(r.routes, route{
+		eventType: eventType,
+		pattern:   compileGlob(pattern),
+		handler:   handler,
+	})
+}
+
+// Default registers handler for objects without a matching route.
+func (r *Router) Default(handler Handler) {
+	r.defaultRoute = handler
+}
+
+// Handle routes the object of each record of event in order, stopping at the first error.
+func (r *Router) Handle(ctx context.Context, event events.S3Event) error {
+	for _, record := range event.Records {
+		object, err := objectFromRecord(record)
+		if err != nil {
+			return err
+		}
+		if err := r.HandleObject(ctx, object); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleObject routes object to the handler of the first matching route, or otherwise the default route.
+func (r *Router) HandleObject(ctx context.Context, object Object) error {
+	for _, route := range r.routes {
+		if matchesEventType(route.eventType, object.EventName) && route.pattern.MatchString(object.Key) {
+			return route.handler.Handle(ctx, object)
+		}
+	}
+	if r.defaultRoute != nil {
+		return r.defaultRoute.Handle(ctx, object)
+	}
+	return fmt.Errorf("%w: %s s3://%s/%s", ErrNoRoute, object.EventName, object.Bucket, object.Key)
+}
+
+// objectFromRecord returns the object an S3 event notification record is for, with its key URL-decoded.
+func objectFromRecord(record events.S3EventRecord) (Object, error) {
+	key, err := url.QueryUnescape(record.S3.Object.Key)
+	if err != nil {
+		return Object{}, fmt.Errorf("decoding key %q: %w", record.S3.Object.Key, err)
+	}
+	return Object{
+		Bucket:    record.S3.Bucket.Name,
+		Key:       key,
+		Size:      record.S3.Object.Size,
+		ETag:      record.S3.Object.ETag,
+		VersionID: record.S3.Object.VersionID,
+		Sequencer: record.S3.Object.Sequencer,
+		EventName: record.EventName,
+		EventTime: record.EventTime,
+		Region:    record.AWSRegion,
+	}, nil
+}
+
+func matchesEventType(eventType EventType, eventName string) bool {
+	if eventType == AnyEvent {
+		return true
+	}
+	prefix, _, _ := strings.Cut(eventName, ":")
+	return prefix == string(eventType)
+}
+
+func compileGlob(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			b.WriteString("[^/]*")
+		case pattern[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
@@ -0,0 +1,198 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/lambdatest"
+)
+
+func newTestRouter(got *[]string, withDefault bool) *Router {
+	r := NewRouter()
+	r.Route(ObjectCreated, "uploads/*.csv", HandlerFunc(func(_ context.Context, o Object) error {
+		*got = append(*got, "csv:"+o.Key)
+		return nil
+	}))
+	r.Route(ObjectCreated, "uploads/**", HandlerFunc(func(_ context.Context, o Object) error {
+		*got = append(*got, "upload:"+o.Key)
+		if o.Key == "uploads/fail" {
+			return errors.New("handler error")
+		}
+		return nil
+	}))
+	r.Route(ObjectRemoved, "**", HandlerFunc(func(_ context.Context, o Object) error {
+		*got = append(*got, "removed:"+o.Key)
+		return nil
+	}))
+	if withDefault {
+		r.Default(HandlerFunc(func(_ context.Context, o Object) error {
+			*got = append(*got, "default:"+o.EventName+":"+o.Key)
+			return nil
+		}))
+	}
+	return r
+}
+
+func TestRouter_Handle(t *testing.T) {
+	tests := []struct {
+		name        string
+		withDefault bool
+		records     []events.S3EventRecord
+		want        []string
+		wantErr     error
+	}{
+		{
+			name: "routes by event type and pattern, keys decoded",
+			records: []events.S3EventRecord{
+				lambdatest.NewS3Record("ObjectCreated:Put", "bucket", "uploads/my file+1.csv").Build(),
+				lambdatest.NewS3Record("ObjectCreated:CompleteMultipartUpload", "bucket", "uploads/2024/a.csv").Build(),
+				lambdatest.NewS3Record("ObjectRemoved:Delete", "bucket", "uploads/a.csv").Build(),
+			},
+			want: []string{"csv:uploads/my file+1.csv", "upload:uploads/2024/a.csv", "removed:uploads/a.csv"},
+		},
+		{
+			name: "handler error, stops and returns error",
+			records: []events.S3EventRecord{
+				lambdatest.NewS3Record("ObjectCreated:Put", "bucket", "uploads/fail").Build(),
+				lambdatest.NewS3Record("ObjectCreated:Put", "bucket", "uploads/a.csv").Build(),
+			},
+			want:    []string{"upload:uploads/fail"},
+			wantErr: errors.New("handler error"),
+		},
+		{
+			name:        "no matching route, default route",
+			withDefault: true,
+			records: []events.S3EventRecord{
+				lambdatest.NewS3Record("ObjectCreated:Put", "bucket", "other/a.csv").Build(),
+				lambdatest.NewS3Record("ObjectRestore:Completed", "bucket", "uploads/a.csv").Build(),
+			},
+			want: []string{"default:ObjectCreated:Put:other/a.csv", "default:ObjectRestore:Completed:uploads/a.csv"},
+		},
+		{
+			name: "no matching route, no default route, returns ErrNoRoute",
+			records: []events.S3EventRecord{
+				lambdatest.NewS3Record("ObjectCreated:Put", "bucket", "other/a.csv").Build(),
+			},
+			wantErr: ErrNoRoute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			r := newTestRouter(&got, tt.withDefault)
+
+			err := r.Handle(context.Background(), lambdatest.NewS3Event(tt.records...))
+
+			switch {
+			case tt.wantErr == nil:
+				assert.NoError(t, err)
+			case errors.Is(tt.wantErr, ErrNoRoute):
+				assert.ErrorIs(t, err, ErrNoRoute)
+			default:
+				assert.EqualError(t, err, tt.wantErr.Error())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRouter_Handle_Object(t *testing.T) {
+	var got Object
+	r := NewRouter()
+	r.Route(AnyEvent, "**", HandlerFunc(func(_ context.Context, o Object) error {
+		got = o
+		return nil
+	}))
+	record := lambdatest.NewS3Record("ObjectCreated:Put", "bucket", "a b.txt").WithSize(10).WithETag("etag").WithVersionID("v1").Build()
+
+	require.NoError(t, r.Handle(context.Background(), lambdatest.NewS3Event(record)))
+
+	assert.Equal(t, Object{
+		Bucket:    "bucket",
+		Key:       "a b.txt",
+		Size:      10,
+		ETag:      "etag",
+		VersionID: "v1",
+		EventName: "ObjectCreated:Put",
+		EventTime: record.EventTime,
+		Region:    lambdatest.Region,
+	}, got)
+}
+
+func TestRouter_HandleEventBridge(t *testing.T) {
+	tests := []struct {
+		name       string
+		detailType string
+		detail     map[string]any
+		want       []string
+	}{
+		{
+			name:       "object created",
+			detailType: "Object Created",
+			detail: map[string]any{
+				"bucket": map[string]any{"name": "bucket"},
+				"object": map[string]any{"key": "uploads/a b.csv", "size": 10, "etag": "etag"},
+				"reason": "PutObject",
+			},
+			want: []string{"csv:uploads/a b.csv"},
+		},
+		{
+			name:       "object deleted, delete marker",
+			detailType: "Object Deleted",
+			detail: map[string]any{
+				"bucket":        map[string]any{"name": "bucket"},
+				"object":        map[string]any{"key": "uploads/a.csv"},
+				"reason":        "DeleteObject",
+				"deletion-type": "Delete Marker Created",
+			},
+			want: []string{"removed:uploads/a.csv"},
+		},
+		{
+			name:       "other detail-type, default route",
+			detailType: "Object Restore Completed",
+			detail: map[string]any{
+				"bucket": map[string]any{"name": "bucket"},
+				"object": map[string]any{"key": "uploads/a.csv"},
+			},
+			want: []string{"default:Object Restore Completed:uploads/a.csv"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			r := newTestRouter(&got, true)
+			event := lambdatest.NewEventBridgeEvent("aws.s3", tt.detailType).WithDetail(tt.detail).Build()
+
+			assert.NoError(t, r.EventBridge().Handle(context.Background(), event))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"uploads/*.csv", "uploads/a.csv", true},
+		{"uploads/*.csv", "uploads/2024/a.csv", false},
+		{"uploads/**.csv", "uploads/2024/a.csv", true},
+		{"uploads/**", "uploads/", true},
+		{"*.json", "a.json", true},
+		{"*.json", "a.json.bak", false},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file/.txt", false},
+		{"a+b (1).txt", "a+b (1).txt", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.want, compileGlob(tt.pattern).MatchString(tt.key))
+		})
+	}
+}
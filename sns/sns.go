@@ -0,0 +1,173 @@
+// Package sns handles SNS messages with typed handlers, decoding the message body into the Go type registered for the
+// value of a message attribute, whether delivered to Lambda directly by SNS or through an SQS queue subscribed to a
+// topic.
+//
+//	router := sns.NewRouter(sns.WithRouteAttribute("event_type"))
+//	sns.Handle(router, "OrderPlaced", orderPlacedHandler)
+//	sns.HandleDefault(router, otherHandler)
+//
+//	lambda.Start[events.SNSEvent](router, middleware.CommonSNS(logger))
+package sns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// DefaultRouteAttribute is the message attribute a Router dispatches on by default.
+const DefaultRouteAttribute = "event_type"
+
+var (
+	// ErrNoRoute is returned by a Router for a message without a registered handler when there is no default route.
+	ErrNoRoute = errors.New("sns: no route for message")
+	// ErrInvalidMessage is returned by a Router when the body of a message cannot be decoded into the registered type.
+	ErrInvalidMessage = errors.New("sns: invalid message")
+)
+
+// Message [T any] is an SNS message with its body decoded into type T.
+type Message[T any] struct {
+	events.SNSEntity
+
+	// Body is the decoded body of the message. The raw body remains available as SNSEntity.Message.
+	Body T
+}
+
+// Handler [T any] interface should be implemented for handlers of SNS messages with a body of type T.
+type Handler[T any] interface {
+	// Handle handles a message.
+	Handle(ctx context.Context, message Message[T]) error
+}
+
+// HandlerFunc [T any] is an adapter to allow the use of ordinary functions as a Handler.
+type HandlerFunc[T any] func(ctx context.Context, message Message[T]) error
+
+// Handle calls f(ctx, message).
+func (f HandlerFunc[T]) Handle(ctx context.Context, message Message[T]) error {
+	return f(ctx, message)
+}
+
+type routerOptions struct {
+	attribute string
+}
+
+// RouterOption configures NewRouter.
+type RouterOption func(*routerOptions)
+
+// WithRouteAttribute sets the message attribute whose value routes each message. Defaults to DefaultRouteAttribute.
+func WithRouteAttribute(name string) RouterOption {
+	return func(o *routerOptions) {
+		o.attribute = name
+	}
+}
+
+// Router routes SNS messages to handlers registered with Handle by the value of a message attribute. It implements
+// lambda.Handler[events.SNSEvent], and handles messages delivered through SQS with HandleSQSMessage.
+//
+// The body of each message is decoded as JSON into the type of its handler, unless the type is string, []byte or
+// json.RawMessage. Handlers must be registered before the Router handles messages.
+type Router struct {
+	opts         routerOptions
+	routes       map[string]func(context.Context, events.SNSEntity) error
+	defaultRoute func(context.Context, events.SNSEntity) error
+}
+
+// NewRouter returns a Router without any registered handlers.
+func NewRouter(options ...RouterOption) *Router {
+	opts := routerOptions{
+		attribute: DefaultRouteAttribute,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+	return &Router{
+		opts:   opts,
+		routes: map[string]func(context.Context, events.SNSEntity) error{},
+	}
+}
+
+// Handle registers handler for messages with the route attribute set to value, decoding the body of each message into
+// type T. Panics if a handler is already registered for value.
+func Handle[T any](r *Router, value string, handler Handler[T]) {
+	if _, ok := r.routes[value]; ok {
+		panic(fmt.Sprintf("sns: handler already registered for %s %q", r.opts.attribute, value))
+	}
+	r.routes[value] = route(handler)
+}
+
+// HandleDefault registers handler for messages without a handler registered with Handle, decoding the body of each
+// message into type T.
+func HandleDefault[T any](r *Router, handler Handler[T]) {
+	r.defaultRoute = route(handler)
+}
+
+// Handle handles each record of event in order, stopping at the first error.
+func (r *Router) Handle(ctx context.Context, event events.SNSEvent) error {
+	for _, record := range event.Records {
+		if err := r.HandleEntity(ctx, record.SNS); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleEntity routes entity to the handler registered for the value of its route attribute, or otherwise the default
+// route.
+func (r *Router) HandleEntity(ctx context.Context, entity events.SNSEntity) error {
+	value, _ := Attribute(entity, r.opts.attribute)
+	if route, ok := r.routes[value]; ok {
+		return route(ctx, entity)
+	}
+	if r.defaultRoute != nil {
+		return r.defaultRoute(ctx, entity)
+	}
+	return fmt.Errorf("%w: %s %q", ErrNoRoute, r.opts.attribute, value)
+}
+
+// HandleSQSMessage unwraps an SNS message delivered through SQS with UnwrapSQSMessage, and routes it as
+// HandleEntity does.
+func (r *Router) HandleSQSMessage(ctx context.Context, message events.SQSMessage) error {
+	entity, _ := UnwrapSQSMessage(message)
+	return r.HandleEntity(ctx, entity)
+}
+
+// Decode decodes the body of entity into type T, as JSON unless T is string, []byte or json.RawMessage.
+func Decode[T any](entity events.SNSEntity) (Message[T], error) {
+	message := Message[T]{SNSEntity: entity}
+	switch p := any(&message.Body).(type) {
+	case *string:
+		*p = entity.Message
+	case *[]byte:
+		*p = []byte(entity.Message)
+	case *json.RawMessage:
+		*p = json.RawMessage(entity.Message)
+	default:
+		if err := json.Unmarshal([]byte(entity.Message), &message.Body); err != nil {
+			return message, fmt.Errorf("%w: message %s: %w", ErrInvalidMessage, entity.MessageID, err)
+		}
+	}
+	return message, nil
+}
+
+// Attribute returns the value of the message attribute name of entity.
+func Attribute(entity events.SNSEntity, name string) (string, bool) {
+	attribute, ok := entity.MessageAttributes[name].(map[string]any)
+	if !ok {
+		return "", false
+	}
+	value, ok := attribute["Value"].(string)
+	return value, ok
+}
+
+func route[T any](handler Handler[T]) func(context.Context, events.SNSEntity) error {
+	return func(ctx context.Context, entity events.SNSEntity) error {
+		message, err := Decode[T](entity)
+		if err != nil {
+			return err
+		}
+		return handler.Handle(ctx, message)
+	}
+}
@@ -0,0 +1,130 @@
+package sns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/lambdatest"
+)
+
+type orderPlaced struct {
+	OrderID string `json:"orderId"`
+}
+
+func TestRouter_Handle(t *testing.T) {
+	handlerErr := errors.New("handler error")
+
+	tests := []struct {
+		name        string
+		options     []RouterOption
+		withDefault bool
+		record      events.SNSEventRecord
+		wantRoute   string
+		wantErr     error
+	}{
+		{
+			name:      "registered route, body decoded",
+			record:    lambdatest.NewSNSRecord(`{"orderId":"123"}`).WithMessageAttribute("event_type", "OrderPlaced").Build(),
+			wantRoute: "OrderPlaced:123",
+		},
+		{
+			name:      "registered route, string body",
+			record:    lambdatest.NewSNSRecord(`plain text`).WithMessageAttribute("event_type", "Note").Build(),
+			wantRoute: "Note:plain text",
+		},
+		{
+			name:      "registered route, handler returns error, returns error",
+			record:    lambdatest.NewSNSRecord(`{}`).WithMessageAttribute("event_type", "OrderCancelled").Build(),
+			wantRoute: "OrderCancelled",
+			wantErr:   handlerErr,
+		},
+		{
+			name:    "registered route, invalid body, returns ErrInvalidMessage",
+			record:  lambdatest.NewSNSRecord(`not json`).WithMessageAttribute("event_type", "OrderPlaced").Build(),
+			wantErr: ErrInvalidMessage,
+		},
+		{
+			name:      "configured attribute",
+			options:   []RouterOption{WithRouteAttribute("type")},
+			record:    lambdatest.NewSNSRecord(`{"orderId":"1"}`).WithMessageAttribute("type", "OrderPlaced").Build(),
+			wantRoute: "OrderPlaced:1",
+		},
+		{
+			name:        "unknown value, default route",
+			withDefault: true,
+			record:      lambdatest.NewSNSRecord(`{"a":1}`).WithMessageAttribute("event_type", "Other").Build(),
+			wantRoute:   `default:{"a":1}`,
+		},
+		{
+			name:        "missing attribute, default route",
+			withDefault: true,
+			record:      lambdatest.NewSNSRecord(`{}`).Build(),
+			wantRoute:   "default:{}",
+		},
+		{
+			name:    "unknown value, no default route, returns ErrNoRoute",
+			record:  lambdatest.NewSNSRecord(`{}`).WithMessageAttribute("event_type", "Other").Build(),
+			wantErr: ErrNoRoute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var route string
+			r := NewRouter(tt.options...)
+			Handle(r, "OrderPlaced", HandlerFunc[orderPlaced](func(_ context.Context, m Message[orderPlaced]) error {
+				route = "OrderPlaced:" + m.Body.OrderID
+				return nil
+			}))
+			Handle(r, "Note", HandlerFunc[string](func(_ context.Context, m Message[string]) error {
+				route = "Note:" + m.Body
+				return nil
+			}))
+			Handle(r, "OrderCancelled", HandlerFunc[[]byte](func(context.Context, Message[[]byte]) error {
+				route = "OrderCancelled"
+				return handlerErr
+			}))
+			if tt.withDefault {
+				HandleDefault(r, HandlerFunc[json.RawMessage](func(_ context.Context, m Message[json.RawMessage]) error {
+					route = "default:" + string(m.Body)
+					return nil
+				}))
+			}
+
+			err := r.Handle(context.Background(), lambdatest.NewSNSEvent(tt.record))
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantRoute, route)
+		})
+	}
+}
+
+func TestHandle_Duplicate(t *testing.T) {
+	r := NewRouter()
+	handler := HandlerFunc[string](func(context.Context, Message[string]) error { return nil })
+	Handle(r, "OrderPlaced", handler)
+
+	require.Panics(t, func() {
+		Handle(r, "OrderPlaced", handler)
+	})
+}
+
+func TestAttribute(t *testing.T) {
+	entity := events.SNSEntity{MessageAttributes: map[string]any{
+		"event_type": map[string]any{"Type": "String", "Value": "OrderPlaced"},
+		"malformed":  "OrderPlaced",
+	}}
+
+	value, ok := Attribute(entity, "event_type")
+	assert.True(t, ok)
+	assert.Equal(t, "OrderPlaced", value)
+	_, ok = Attribute(entity, "malformed")
+	assert.False(t, ok)
+	_, ok = Attribute(entity, "missing")
+	assert.False(t, ok)
+}
@@ -0,0 +1,47 @@
+package sns
+
+import (
+	"encoding/json"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const notificationType = "Notification"
+
+// UnwrapSQSMessage returns the SNS message delivered through SQS as message, and whether it was delivered with raw
+// message delivery.
+//
+// Without raw message delivery, the body of the SQS message is the SNS envelope, which is unwrapped. With raw message
+// delivery, the body is the SNS message itself and the message attributes are SQS message attributes, from which an
+// events.SNSEntity is built with the message id of the SQS message and without the topic ARN.
+func UnwrapSQSMessage(message events.SQSMessage) (events.SNSEntity, bool) {
+	var envelope events.SNSEntity
+	if err := json.Unmarshal([]byte(message.Body), &envelope); err == nil &&
+		envelope.Type == notificationType && envelope.TopicArn != "" && envelope.MessageID != "" {
+		return envelope, false
+	}
+
+	entity := events.SNSEntity{
+		MessageID: message.MessageId,
+		Type:      notificationType,
+		Message:   message.Body,
+	}
+	if len(message.MessageAttributes) > 0 {
+		entity.MessageAttributes = make(map[string]any, len(message.MessageAttributes))
+		for name, attribute := range message.MessageAttributes {
+			value := map[string]any{"Type": attribute.DataType}
+			if attribute.StringValue != nil {
+				value["Value"] = *attribute.StringValue
+			}
+			entity.MessageAttributes[name] = value
+		}
+	}
+	return entity, true
+}
+
+// DecodeSQSMessage unwraps the SNS message delivered through SQS as message with UnwrapSQSMessage, and decodes its
+// body into type T with Decode.
+func DecodeSQSMessage[T any](message events.SQSMessage) (Message[T], error) {
+	entity, _ := UnwrapSQSMessage(message)
+	return Decode[T](entity)
+}
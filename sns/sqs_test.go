@@ -0,0 +1,98 @@
+package sns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ellogroup/ello-golang-aws/v2/lambda/lambdatest"
+)
+
+const envelope = `{
+  "Type": "Notification",
+  "MessageId": "sns-message-id",
+  "TopicArn": "arn:aws:sns:eu-west-2:123456789012:orders",
+  "Subject": "subject",
+  "Message": "{\"orderId\":\"123\"}",
+  "Timestamp": "2024-01-02T03:04:05.000Z",
+  "SignatureVersion": "1",
+  "SigningCertURL": "https://sns.eu-west-2.amazonaws.com/cert.pem",
+  "UnsubscribeURL": "https://sns.eu-west-2.amazonaws.com/?Action=Unsubscribe",
+  "MessageAttributes": {
+    "event_type": {"Type": "String", "Value": "OrderPlaced"}
+  }
+}`
+
+func TestUnwrapSQSMessage(t *testing.T) {
+	tests := []struct {
+		name          string
+		message       events.SQSMessage
+		wantRaw       bool
+		wantMessageID string
+		wantTopicArn  string
+		wantBody      string
+		wantEventType string
+	}{
+		{
+			name:          "envelope",
+			message:       lambdatest.NewSQSMessage(envelope).Build(),
+			wantMessageID: "sns-message-id",
+			wantTopicArn:  "arn:aws:sns:eu-west-2:123456789012:orders",
+			wantBody:      `{"orderId":"123"}`,
+			wantEventType: "OrderPlaced",
+		},
+		{
+			name:          "raw message delivery",
+			message:       lambdatest.NewSQSMessage(`{"orderId":"123"}`).WithMessageID("sqs-message-id").WithMessageAttribute("event_type", "OrderPlaced").Build(),
+			wantRaw:       true,
+			wantMessageID: "sqs-message-id",
+			wantBody:      `{"orderId":"123"}`,
+			wantEventType: "OrderPlaced",
+		},
+		{
+			name:          "raw message delivery, JSON body that is not an envelope",
+			message:       lambdatest.NewSQSMessage(`{"Type":"Notification"}`).WithMessageID("sqs-message-id").Build(),
+			wantRaw:       true,
+			wantMessageID: "sqs-message-id",
+			wantBody:      `{"Type":"Notification"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entity, raw := UnwrapSQSMessage(tt.message)
+
+			assert.Equal(t, tt.wantRaw, raw)
+			assert.Equal(t, tt.wantMessageID, entity.MessageID)
+			assert.Equal(t, tt.wantTopicArn, entity.TopicArn)
+			assert.Equal(t, tt.wantBody, entity.Message)
+			eventType, _ := Attribute(entity, "event_type")
+			assert.Equal(t, tt.wantEventType, eventType)
+		})
+	}
+}
+
+func TestDecodeSQSMessage(t *testing.T) {
+	message, err := DecodeSQSMessage[orderPlaced](lambdatest.NewSQSMessage(envelope).Build())
+
+	require.NoError(t, err)
+	assert.Equal(t, "123", message.Body.OrderID)
+	assert.Equal(t, "subject", message.Subject)
+}
+
+func TestRouter_HandleSQSMessage(t *testing.T) {
+	var got []string
+	r := NewRouter()
+	Handle(r, "OrderPlaced", HandlerFunc[orderPlaced](func(_ context.Context, m Message[orderPlaced]) error {
+		got = append(got, m.Body.OrderID)
+		return nil
+	}))
+
+	require.NoError(t, r.HandleSQSMessage(context.Background(), lambdatest.NewSQSMessage(envelope).Build()))
+	require.NoError(t, r.HandleSQSMessage(context.Background(),
+		lambdatest.NewSQSMessage(`{"orderId":"456"}`).WithMessageAttribute("event_type", "OrderPlaced").Build()))
+
+	assert.Equal(t, []string{"123", "456"}, got)
+}